@@ -3,17 +3,32 @@ package api
 import (
 	"music-files/internal/context"
 	"music-files/internal/database/repository/audio_file_repo"
+	"music-files/internal/database/repository/audio_file_tag_repo"
 	"music-files/internal/database/repository/cover_repo"
 	"music-files/internal/database/repository/dir_repo"
+	"music-files/internal/database/repository/ignore_pattern_repo"
+	"music-files/internal/database/repository/scan_error_repo"
+	"music-files/internal/database/repository/scan_job_dir_repo"
+	"music-files/internal/database/repository/scan_job_pending_deletion_repo"
+	"music-files/internal/database/repository/scan_job_pending_dir_repo"
+	"music-files/internal/database/repository/scan_job_repo"
+	"music-files/internal/database/repository/scan_report_entry_repo"
 	"music-files/internal/handler/audio_file_handler"
 	"music-files/internal/handler/cover_handler"
 	"music-files/internal/handler/dir_handler"
+	"music-files/internal/handler/scan_error_handler"
+	"music-files/internal/handler/scan_job_handler"
 	"music-files/internal/middleware"
 	"music-files/internal/service"
 	"music-files/internal/service/audio_file_service"
 	"music-files/internal/service/cover_service"
 	"music-files/internal/service/dir_service"
 	"music-files/internal/service/file_processor_service"
+	"music-files/internal/service/ignore_service"
+	"music-files/internal/service/scan_error_service"
+	"music-files/internal/service/scan_job_service"
+	"music-files/internal/service/thumbnail_service"
+	"music-files/internal/service/watcher_service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -31,17 +46,38 @@ func SetupRouter(ac *context.AppContext) (r *gin.Engine) {
 
 	coverRepo := cover_repo.NewRepository()
 	audioFileRepo := audio_file_repo.NewRepository()
+	audioFileTagRepo := audio_file_tag_repo.NewRepository()
 	dirRepo := dir_repo.NewRepository()
+	scanJobRepo := scan_job_repo.NewRepository()
+	scanJobDirRepo := scan_job_dir_repo.NewRepository()
+	scanErrorRepo := scan_error_repo.NewRepository()
+	ignorePatternRepo := ignore_pattern_repo.NewRepository()
+	scanReportEntryRepo := scan_report_entry_repo.NewRepository()
+	scanJobPendingDirRepo := scan_job_pending_dir_repo.NewRepository()
+	scanJobPendingDeletionRepo := scan_job_pending_deletion_repo.NewRepository()
 	txManager := service.NewTransactionManager(*ac.Db)
 
-	coverService := cover_service.NewService(coverRepo)
-	audioFileService := audio_file_service.NewService(audioFileRepo)
-	dirService := dir_service.NewService(dirRepo, *coverService, *audioFileService)
+	coverService := cover_service.NewService(coverRepo, *ac.Config.Covers)
+	audioFileService := audio_file_service.NewService(audioFileRepo, audioFileTagRepo)
+	scanErrorService := scan_error_service.NewService(scanErrorRepo, dirRepo)
+	ignoreService := ignore_service.NewService(ignorePatternRepo, dirRepo)
+	dirService := dir_service.NewService(dirRepo, *coverService, *audioFileService, *scanErrorService, *ignoreService, *ac.Config.Scanner)
 	fileProcessorService := file_processor_service.NewService(*dirService, *coverService, *audioFileService)
+	thumbnailService := thumbnail_service.NewService(*coverService, *fileProcessorService, *ac.Config.Thumbnails)
+	scanJobService := scan_job_service.NewService(scanJobRepo, scanJobDirRepo, scanJobPendingDirRepo, scanJobPendingDeletionRepo, scanReportEntryRepo, *dirService, *scanErrorService, *thumbnailService, txManager)
+	if err := scanJobService.Start(); err != nil {
+		log.Panic().Err(err).Msg("Failed to start scan job worker")
+	}
+	watcherService := watcher_service.NewService(*dirService, *ignoreService, *scanJobService, txManager, *ac.Config.Watcher)
+	if err := watcherService.Start(); err != nil {
+		log.Panic().Err(err).Msg("Failed to start filesystem watcher")
+	}
 
-	coverHandler := cover_handler.NewHandler(*coverService, *fileProcessorService, txManager)
-	audioFileHandler := audio_file_handler.NewHandler(*audioFileService, *fileProcessorService, txManager)
-	dirHandler := dir_handler.NewHandler(*dirService, txManager)
+	coverHandler := cover_handler.NewHandler(*coverService, *fileProcessorService, *thumbnailService, txManager)
+	audioFileHandler := audio_file_handler.NewHandler(*audioFileService, *fileProcessorService, *dirService, txManager)
+	dirHandler := dir_handler.NewHandler(*dirService, *ignoreService, *scanJobService, *thumbnailService, *watcherService, txManager)
+	scanJobHandler := scan_job_handler.NewHandler(*scanJobService, txManager)
+	scanErrorHandler := scan_error_handler.NewHandler(*scanErrorService, *dirService, txManager)
 
 	api := r.Group("/api")
 	{
@@ -52,13 +88,21 @@ func SetupRouter(ac *context.AppContext) (r *gin.Engine) {
 			roots.GET("", dirHandler.GetRoots)
 			roots.POST("", dirHandler.AddRootToWatchList)
 			roots.DELETE("/:dirId", dirHandler.RemoveRootFromWatchList)
+			roots.GET("/:dirId/ignore", dirHandler.GetIgnorePatterns)
+			roots.PUT("/:dirId/ignore", dirHandler.SetIgnorePatterns)
+			roots.PUT("/:dirId/symlink-policy", dirHandler.SetSymlinkPolicy)
 		}
 
 		dirs := api.Group("/dirs")
 		{
 			dirs.GET("/:dirId", dirHandler.GetDir)
 			dirs.GET("/:dirId/content", dirHandler.Content)
+			dirs.GET("/:dirId/cover", dirHandler.GetCover)
 			dirs.POST("/:dirId/scan", dirHandler.Scan)
+			dirs.POST("/:dirId/purge", dirHandler.PurgeOffline)
+			dirs.PUT("/:dirId/cover-override", dirHandler.SetCoverOverride)
+			dirs.DELETE("/:dirId/cover-override", dirHandler.ClearCoverOverride)
+			dirs.POST("/:dirId/covers", dirHandler.UploadCover)
 			dirs.POST("/scan", dirHandler.ScanAll)
 		}
 
@@ -68,14 +112,35 @@ func SetupRouter(ac *context.AppContext) (r *gin.Engine) {
 			audioFiles.GET("", audioFileHandler.GetAll)
 			audioFiles.GET("/:audioFileId/download", audioFileHandler.Download)
 			audioFiles.GET("/:audioFileId/cover", audioFileHandler.GetCover)
+			audioFiles.PUT("/:audioFileId/cover-override", audioFileHandler.SetCoverOverride)
+			audioFiles.DELETE("/:audioFileId/cover-override", audioFileHandler.ClearCoverOverride)
 			audioFiles.GET("/sha256/:sha256", audioFileHandler.SearchBySha256)
+			audioFiles.GET("/mbid/:mbid", audioFileHandler.SearchByMbid)
+			audioFiles.GET("/isrc/:isrc", audioFileHandler.SearchByIsrc)
 			audioFiles.PUT("/covers-top", audioFileHandler.CalcBestCovers)
 		}
 
 		covers := api.Group("/covers")
 		{
+			covers.GET("/duplicates", coverHandler.GetDuplicates)
 			covers.GET("/:coverId", coverHandler.GetCover)
-			covers.GET("/:coverId/image", coverHandler.Download)
+			covers.GET("/:coverId/download", coverHandler.Download)
+			covers.GET("/:coverId/image", coverHandler.Image)
+			covers.GET("/:coverId/similar", coverHandler.GetSimilar)
+		}
+
+		jobs := api.Group("/jobs")
+		{
+			jobs.GET("", scanJobHandler.GetJobs)
+			jobs.GET("/:jobId", scanJobHandler.GetJob)
+			jobs.DELETE("/:jobId", scanJobHandler.Cancel)
+			jobs.GET("/:jobId/report", scanJobHandler.GetReport)
+			jobs.POST("/:jobId/resume", scanJobHandler.Resume)
+		}
+
+		scanErrors := api.Group("/scan-errors")
+		{
+			scanErrors.GET("", scanErrorHandler.GetScanErrors)
 		}
 	}
 
@@ -24,7 +24,7 @@ const docTemplate = `{
     "paths": {
         "/audio-files": {
             "get": {
-                "description": "Retrieves a list of all audioFiles in the system",
+                "description": "Retrieves a list of all audioFiles in the system. Query parameters named tag.FIELD, e.g.\ntag.MUSICBRAINZ_ALBUMID or tag.GENRE, keep only audioFiles having the value among the values of the field.\nThe other parameters filter by technical properties. All given filters must match",
                 "consumes": [
                     "application/json"
                 ],
@@ -35,6 +35,44 @@ const docTemplate = `{
                     "AudioFiles"
                 ],
                 "summary": "Retrieve all audioFiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Value of the tag field FIELD",
+                        "name": "tag.FIELD",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Codec, e.g. flac, alac, aac or mp3",
+                        "name": "codec",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Container: mpeg, flac, ogg, mp4, wav or aiff",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Bits per sample of lossless audioFiles",
+                        "name": "bitsPerSample",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep only lossless or only lossy audioFiles",
+                        "name": "lossless",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CBR or VBR",
+                        "name": "bitrateMode",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -42,6 +80,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/audio_file_handler.getAudioFilesResponse"
                         }
                     },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -91,6 +135,82 @@ const docTemplate = `{
                 }
             }
         },
+        "/audio-files/isrc/{isrc}": {
+            "get": {
+                "description": "Retrieves a list of audioFiles that have the specified ISRC tag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AudioFiles"
+                ],
+                "summary": "Search audioFiles by ISRC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISRC, with or without dashes",
+                        "name": "isrc",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audio_file_handler.searchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/audio-files/mbid/{mbid}": {
+            "get": {
+                "description": "Retrieves a list of audioFiles that have the id in any of their MUSICBRAINZ_*ID tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AudioFiles"
+                ],
+                "summary": "Search audioFiles by MusicBrainz id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MusicBrainz id of a recording, release, release group, artist or work",
+                        "name": "mbid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audio_file_handler.searchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/audio-files/sha256/{sha256}": {
             "get": {
                 "description": "Retrieves a list of audioFiles that have the specified SHA256 hash.",
@@ -117,7 +237,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/audio_file_handler.searchBySha256Response"
+                            "$ref": "#/definitions/audio_file_handler.searchResponse"
                         }
                     },
                     "500": {
@@ -229,19 +349,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/audio-files/{audioFileId}/download": {
-            "get": {
-                "description": "Downloads a audio file identified by the audioFileId",
+        "/audio-files/{audioFileId}/cover-override": {
+            "put": {
+                "description": "The pinned cover goes before the cover pinned to the directory, track pictures, embedded pictures and covers found on disk",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
                     "AudioFiles"
                 ],
-                "summary": "Download a audio file by ID",
+                "summary": "Pin a cover to an audio file",
                 "parameters": [
                     {
                         "type": "integer",
@@ -249,77 +369,71 @@ const docTemplate = `{
                         "name": "audioFileId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Cover to pin",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/audio_file_handler.setCoverOverrideRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Audio File",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid audioFileId format, Failed to decode request",
                         "schema": {
-                            "type": "file"
-                        },
-                        "headers": {
-                            "Content-Disposition": {
-                                "type": "string",
-                                "description": "attachment; filename=[name of the file]"
-                            },
-                            "Content-Type": {
-                                "type": "string",
-                                "description": "application/octet-stream"
-                            }
+                            "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "400": {
-                        "description": "Invalid audioFileId format",
+                    "404": {
+                        "description": "Audio file or cover not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error, Failed to calculate absolute path",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     }
                 }
-            }
-        },
-        "/covers/{coverId}": {
-            "get": {
-                "description": "Retrieves detailed information about a cover by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "The audio file goes back to the cover of its directory or the ones found on disk",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Covers"
+                    "AudioFiles"
                 ],
-                "summary": "Retrieve a cover by ID",
+                "summary": "Unpin the cover of an audio file",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Cover ID",
-                        "name": "coverId",
+                        "description": "Audio File ID",
+                        "name": "audioFileId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/cover_handler.getCoverResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid coverId format",
+                        "description": "Invalid audioFileId format",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "Cover not found",
+                        "description": "Audio file not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -333,31 +447,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/covers/{coverId}/download": {
+        "/audio-files/{audioFileId}/download": {
             "get": {
-                "description": "Downloads a cover image file identified by the coverId",
+                "description": "Downloads a audio file identified by the audioFileId",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/octet-stream",
+                    "audio/mpeg",
+                    "audio/flac",
+                    "audio/ogg",
+                    "audio/mp4",
+                    "audio/wav",
+                    "audio/aiff"
                 ],
                 "tags": [
-                    "Covers"
+                    "AudioFiles"
                 ],
-                "summary": "Download a cover image by ID",
+                "summary": "Download a audio file by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Cover ID",
-                        "name": "coverId",
+                        "description": "Audio File ID",
+                        "name": "audioFileId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cover File",
+                        "description": "Audio File",
                         "schema": {
                             "type": "file"
                         },
@@ -368,12 +488,18 @@ const docTemplate = `{
                             },
                             "Content-Type": {
                                 "type": "string",
-                                "description": "application/octet-stream"
+                                "description": "MIME type of the audio format, application/octet-stream if it is unknown"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid coverId format",
+                        "description": "Invalid audioFileId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Audio file not found, missing on disk or not allowed by the symlink policy",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -387,22 +513,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/dirs/scan": {
-            "post": {
-                "description": "Initiates a scan in all directories to identify new or updated files.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/covers/duplicates": {
+            "get": {
+                "description": "Covers are grouped when their perceptual hashes are linked by a chain of covers each within maxDistance bits of the next.\nThe variant with the highest resolution is the canonical one. Covers that have no duplicates are left out",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Directories"
+                    "Covers"
+                ],
+                "summary": "Find groups of near-duplicate covers in the library",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Largest number of differing bits from 0 to 64, COVERS_SIMILARITY_MAX_DISTANCE by default",
+                        "name": "maxDistance",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Scan all directories",
                 "responses": {
                     "200": {
-                        "description": "All directories scanned successfully"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cover_handler.coverGroupResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid maxDistance",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -413,9 +556,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/dirs/{dirId}": {
+        "/covers/{coverId}": {
             "get": {
-                "description": "Retrieves detailed information about a directory by its ID",
+                "description": "Retrieves detailed information about a cover by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -423,14 +566,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Directories"
+                    "Covers"
                 ],
-                "summary": "Retrieve a directory by ID",
+                "summary": "Retrieve a cover by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Dir ID",
-                        "name": "dirId",
+                        "description": "Cover ID",
+                        "name": "coverId",
                         "in": "path",
                         "required": true
                     }
@@ -439,17 +582,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dir_handler.getDirResponse"
+                            "$ref": "#/definitions/cover_handler.getCoverResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid dirId format",
+                        "description": "Invalid coverId format",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "Directory not found",
+                        "description": "Cover not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -463,49 +606,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/dirs/{dirId}/content": {
+        "/covers/{coverId}/download": {
             "get": {
-                "description": "Retrieves a list of subdirectories for a given directory ID",
+                "description": "Downloads a cover image file identified by the coverId",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Directories"
+                    "Covers"
                 ],
-                "summary": "Retrieve content of a directory by ID",
+                "summary": "Download a cover image by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Directory ID",
-                        "name": "dirId",
+                        "description": "Cover ID",
+                        "name": "coverId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Cover File",
                         "schema": {
-                            "$ref": "#/definitions/dir_handler.contentResponse"
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=[name of the file]"
+                            },
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "application/octet-stream"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid dirId format",
+                        "description": "Invalid coverId format",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "Directory not found",
+                        "description": "Cover not found, missing on disk or not allowed by the symlink policy",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal Server Error, Failed to calculate absolute path",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -513,40 +666,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/dirs/{dirId}/scan": {
-            "post": {
-                "description": "Initiates a scan in the specified directory to identify new or updated files.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/covers/{coverId}/image": {
+            "get": {
+                "description": "Without query parameters the original file is sent, AVIF pictures are only sent this way. Otherwise the image is turned upright according to its EXIF orientation,\nscaled down to the box (never up) and encoded in the requested format. Variants are cached on disk",
                 "produces": [
-                    "application/json"
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp",
+                    "image/bmp",
+                    "image/tiff",
+                    "image/avif"
                 ],
                 "tags": [
-                    "Directories"
+                    "Covers"
                 ],
-                "summary": "Scan a directory by ID",
+                "summary": "Get a cover image, optionally resized or converted",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Directory ID",
-                        "name": "dirId",
+                        "description": "Cover ID",
+                        "name": "coverId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width and height of the box, can't be combined with w and h",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width of the box",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height of the box",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "fit",
+                            "fill"
+                        ],
+                        "type": "string",
+                        "description": "fit keeps the whole image inside the box, fill crops it to cover the box",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "jpeg",
+                            "png",
+                            "webp"
+                        ],
+                        "type": "string",
+                        "description": "Format of the variant, jpeg by default",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Directory scanned successfully"
+                        "description": "Cover image",
+                        "schema": {
+                            "type": "file"
+                        }
                     },
                     "400": {
-                        "description": "Invalid dirId format",
+                        "description": "Invalid coverId or variant parameters",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "Directory not found",
+                        "description": "Cover not found, missing on disk or not allowed by the symlink policy",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -560,24 +758,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/roots": {
+        "/covers/{coverId}/similar": {
             "get": {
-                "description": "Retrieves a list of all root directories that are tracked",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Compares perceptual hashes, so the same artwork at another resolution or compression is found. Nearest covers go first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Directories"
+                    "Covers"
+                ],
+                "summary": "Find covers that look like a cover",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cover ID",
+                        "name": "coverId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Largest number of differing bits from 0 to 64, COVERS_SIMILARITY_MAX_DISTANCE by default",
+                        "name": "maxDistance",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Retrieve root directories",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dir_handler.getRootsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cover_handler.similarCoverResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid coverId or maxDistance",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Cover not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "The cover has no perceptual hash yet",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
@@ -587,9 +818,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/dirs/scan": {
             "post": {
-                "description": "Adds a new directory to the database for tracking",
+                "description": "Submits a scan job for all root directories to identify new or updated files. Progress is available via /jobs/{jobId}",
                 "consumes": [
                     "application/json"
                 ],
@@ -599,39 +832,36 @@ const docTemplate = `{
                 "tags": [
                     "Directories"
                 ],
-                "summary": "Add a new tracked directory",
+                "summary": "Scan all directories",
                 "parameters": [
                     {
-                        "description": "Directory Data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dir_handler.addRootToWatchListRequest"
-                        }
+                        "type": "boolean",
+                        "description": "Hash every file even if its size and modification time did not change",
+                        "name": "deepVerify",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only compute the report of changes without writing them",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/dir_handler.addRootToWatchListResponse"
+                            "$ref": "#/definitions/dir_handler.scanAllResponse"
                         }
                     },
                     "400": {
-                        "description": "Failed to decode request",
-                        "schema": {
-                            "$ref": "#/definitions/response.Error"
-                        }
-                    },
-                    "404": {
-                        "description": "Directory not found",
+                        "description": "Invalid deepVerify or dryRun format",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "409": {
-                        "description": "Directory already tracked",
+                        "description": "Scan queue is full",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -645,9 +875,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/roots/{dirId}": {
-            "delete": {
-                "description": "Stops tracking a directory with a specified root directory ID",
+        "/dirs/{dirId}": {
+            "get": {
+                "description": "Retrieves detailed information about a directory by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -657,22 +887,25 @@ const docTemplate = `{
                 "tags": [
                     "Directories"
                 ],
-                "summary": "Remove a tracked root directory",
+                "summary": "Retrieve a directory by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Root Directory ID",
+                        "description": "Dir ID",
                         "name": "dirId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.getDirResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid dirId format, The directory is not root",
+                        "description": "Invalid dirId format",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -691,97 +924,1686 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "audio_file_handler.calcBestCoversRequest": {
-            "type": "object",
-            "properties": {
-                "audioFiles": {
-                    "description": "List of audio files to find their most suitable covers",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        },
+        "/dirs/{dirId}/content": {
+            "get": {
+                "description": "Retrieves a list of subdirectories for a given directory ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Retrieve content of a directory by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.contentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dirId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     }
                 }
             }
         },
-        "audio_file_handler.calcBestCoversResponse": {
-            "type": "object",
-            "properties": {
-                "coversTop": {
-                    "description": "Top suitable covers by frequency of occurrence",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        "/dirs/{dirId}/cover": {
+            "get": {
+                "description": "A directory with its own cover (pinned or the best front cover of it and its artwork subfolders) is shown with it.\nA directory without one, like an artist or a compilation, gets a 3x3 or 2x2 mosaic of the most used covers of audio files below it,\nor the most used cover alone when there are fewer than 4 different ones. Parameters resize and convert the image as for /covers/{coverId}/image,\na mosaic is always square cells filling the box, THUMBNAILS_MOSAIC_SIZE by default. Mosaics are cached on disk and rendered again once covers below change",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp",
+                    "image/bmp",
+                    "image/tiff",
+                    "image/avif"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Get the cover image of a directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dir ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width and height of the box, can't be combined with w and h",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width of the box",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height of the box",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "fit",
+                            "fill"
+                        ],
+                        "type": "string",
+                        "description": "fit keeps the whole image inside the box, fill crops it to cover the box. Ignored for mosaics",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "jpeg",
+                            "png",
+                            "webp"
+                        ],
+                        "type": "string",
+                        "description": "Format of the image, jpeg by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cover image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dirId or image parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found or there are no covers in it and below it",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     }
                 }
             }
         },
-        "audio_file_handler.getAudioFileResponse": {
-            "type": "object",
-            "properties": {
-                "audioFileId": {
-                    "description": "Unique identifier for the audioFile",
-                    "type": "integer"
-                },
-                "bitrateKbps": {
-                    "description": "Bitrate in kilobits per second",
-                    "type": "integer"
-                },
-                "channelsN": {
-                    "description": "Number of audio channels",
-                    "type": "integer"
-                },
-                "dirId": {
-                    "description": "Directory identifier where the audioFile resides",
-                    "type": "integer"
-                },
-                "durationMs": {
-                    "description": "Duration of the audioFile in milliseconds",
-                    "type": "integer"
-                },
-                "extension": {
-                    "description": "File extension of the audioFile",
-                    "type": "string"
-                },
-                "filename": {
-                    "description": "Filename of the audioFile",
+        "/dirs/{dirId}/cover-override": {
+            "put": {
+                "description": "The pinned cover replaces the best front cover of the directory for its audio files and for the directories below that have no covers of their own. Covers pinned to audio files still go first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Pin a cover to a directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dir ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cover to pin",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.setCoverOverrideRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, Failed to decode request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory or cover not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Audio files of the directory and below go back to covers found on disk",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Unpin the cover of a directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dir ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/dirs/{dirId}/covers": {
+            "post": {
+                "description": "Writes the image into the directory as cover.jpg (the name is set by COVERS_UPLOAD_NAME, the extension follows the format) and registers it.\nAn existing file with that name is kept and 409 is returned unless overwrite=true. The file is moved in place once the cover is stored.\nWith pin=true the cover is also pinned to the directory. The image can't be larger than COVERS_UPLOAD_MAX_BYTES",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Upload a cover into a directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dir ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "JPEG, PNG, GIF, WebP, BMP, TIFF or AVIF image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace an existing file with the name of the upload",
+                        "name": "overwrite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Pin the uploaded cover to the directory",
+                        "name": "pin",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.uploadCoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, Missing file, Not a supported image, Not a cover by the cover rules",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "A file with the name of the upload exists",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Uploaded file is too large",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/dirs/{dirId}/purge": {
+            "post": {
+                "description": "Deletes subdirectories, audio files and covers stored below an offline directory without waiting for the grace period. The directory itself is kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Purge content of an offline directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Directory is not offline",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/dirs/{dirId}/scan": {
+            "post": {
+                "description": "Submits a scan job for the specified directory to identify new or updated files. Progress is available via /jobs/{jobId}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Scan a directory by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Hash every file even if its size and modification time did not change",
+                        "name": "deepVerify",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only compute the report of changes without writing them",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.scanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dirId, deepVerify or dryRun format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Scan queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "Retrieves all submitted scan jobs with their outcome, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Retrieve scan jobs history",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scan_job_handler.getJobsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{jobId}": {
+            "get": {
+                "description": "Retrieves the state and the live progress of a scan job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Retrieve a scan job by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scan Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scan_job_handler.getJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid jobId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Scan job not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels a queued or running scan job. A running job stops at the next file or directory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Cancel a scan job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scan Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Invalid jobId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Scan job not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Scan job already finished",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{jobId}/report": {
+            "get": {
+                "description": "Retrieves directories, audio files and covers created, updated, moved and deleted by the scan job, with counters and timings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Retrieve the report of a scan job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scan Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scan_job_handler.getReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid jobId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Scan job not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{jobId}/resume": {
+            "post": {
+                "description": "Puts a failed or cancelled scan job back in the queue. The job continues from the directories it has not scanned yet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Resume a scan job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scan Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/scan_job_handler.resumeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid jobId format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Scan job not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Scan job can't be resumed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/roots": {
+            "get": {
+                "description": "Retrieves a list of all root directories that are tracked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Retrieve root directories",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.getRootsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new directory to the database for tracking",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Add a new tracked directory",
+                "parameters": [
+                    {
+                        "description": "Directory Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.addRootToWatchListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.addRootToWatchListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to decode request, Unknown symlink policy",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Directory already tracked",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/roots/{dirId}": {
+            "delete": {
+                "description": "Stops tracking a directory with a specified root directory ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Remove a tracked root directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Root Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, The directory is not root",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/roots/{dirId}/ignore": {
+            "get": {
+                "description": "Retrieves gitignore-style patterns excluding paths of the root from scans. Patterns of .wakarimiignore files are not included",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Retrieve ignore patterns of a root",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Root Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.getIgnorePatternsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, The directory is not root",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces gitignore-style patterns excluding paths of the root from scans. They take effect on the next scan, stored entities that become ignored are deleted by it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Replace ignore patterns of a root",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Root Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ignore patterns",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.setIgnorePatternsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, Failed to decode request, Invalid pattern, The directory is not root",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/roots/{dirId}/symlink-policy": {
+            "put": {
+                "description": "Changes how scans of the root treat symlinks to directories, symlinks to files are scanned under every policy. It takes effect on the next scan, stored entities reached through links that are no longer followed are deleted by it. Downloads resolve files by the same policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Directories"
+                ],
+                "summary": "Change symlink policy of a root",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Root Directory ID",
+                        "name": "dirId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Symlink policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dir_handler.setSymlinkPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid dirId format, Failed to decode request, Unknown symlink policy, The directory is not root",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/scan-errors": {
+            "get": {
+                "description": "Retrieves files and directories skipped by scans. An error disappears once a later scan of the file succeeds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ScanErrors"
+                ],
+                "summary": "Retrieve scan errors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only errors in this directory and below it",
+                        "name": "rootId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only errors directly in this directory",
+                        "name": "dirId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/scan_error_handler.getScanErrorsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rootId or dirId",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Directory not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audio_file_handler.calcBestCoversRequest": {
+            "type": "object",
+            "properties": {
+                "audioFiles": {
+                    "description": "List of audio files to find their most suitable covers",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "audio_file_handler.calcBestCoversResponse": {
+            "type": "object",
+            "properties": {
+                "coversTop": {
+                    "description": "Top suitable covers by frequency of occurrence",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "audio_file_handler.getAudioFileResponse": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "description": "Album from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "artist": {
+                    "description": "Artist from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "audioFileId": {
+                    "description": "Unique identifier for the audioFile",
+                    "type": "integer"
+                },
+                "bitrateKbps": {
+                    "description": "Bitrate in kilobits per second",
+                    "type": "integer"
+                },
+                "bitrateMode": {
+                    "description": "CBR or VBR, null if it isn't known",
+                    "type": "string"
+                },
+                "bitsPerSample": {
+                    "description": "Bits per sample of lossless streams, null for lossy ones",
+                    "type": "integer"
+                },
+                "channelsN": {
+                    "description": "Number of audio channels",
+                    "type": "integer"
+                },
+                "codec": {
+                    "description": "Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Comment from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "container": {
+                    "description": "Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known",
+                    "type": "string"
+                },
+                "coverId": {
+                    "description": "Identifier of the cover resolved for the audioFile, null if it has none",
+                    "type": "integer"
+                },
+                "coverOverrideId": {
+                    "description": "Identifier of the cover pinned to the audioFile, null if none is pinned",
+                    "type": "integer"
+                },
+                "dirId": {
+                    "description": "Directory identifier where the audioFile resides",
+                    "type": "integer"
+                },
+                "discNumber": {
+                    "description": "Disc number, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "durationMs": {
+                    "description": "Duration of the audioFile in milliseconds",
+                    "type": "integer"
+                },
+                "encoder": {
+                    "description": "Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known",
+                    "type": "string"
+                },
+                "extension": {
+                    "description": "File extension of the audioFile",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "Filename of the audioFile",
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "Genre from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "lastContentUpdate": {
+                    "description": "Time of the last update to the audioFile's content",
+                    "type": "string"
+                },
+                "lossless": {
+                    "description": "Whether the stream is lossless, null if it isn't known",
+                    "type": "boolean"
+                },
+                "sampleCount": {
+                    "description": "Number of samples per channel, null if it isn't known",
+                    "type": "integer"
+                },
+                "sampleRateHz": {
+                    "description": "Sample rate in hertz",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "description": "SHA-256 hash of the file",
+                    "type": "string"
+                },
+                "sizeByte": {
+                    "description": "File size in bytes",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "All tag fields of the audioFile by their upper case names, such as ALBUMARTIST or MUSICBRAINZ_ALBUMID",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "title": {
+                    "description": "Title from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "trackNumber": {
+                    "description": "Track number on the disc, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "year": {
+                    "description": "Release year, null if the tags don't have it",
+                    "type": "integer"
+                }
+            }
+        },
+        "audio_file_handler.getAudioFilesResponse": {
+            "type": "object",
+            "properties": {
+                "audioFiles": {
+                    "description": "Array containing audioFile items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audio_file_handler.getAudioFilesResponseItem"
+                    }
+                }
+            }
+        },
+        "audio_file_handler.getAudioFilesResponseItem": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "description": "Album from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "artist": {
+                    "description": "Artist from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "audioFileId": {
+                    "description": "Unique identifier for the audioFile",
+                    "type": "integer"
+                },
+                "bitrateKbps": {
+                    "description": "Bitrate in kilobits per second",
+                    "type": "integer"
+                },
+                "bitrateMode": {
+                    "description": "CBR or VBR, null if it isn't known",
+                    "type": "string"
+                },
+                "bitsPerSample": {
+                    "description": "Bits per sample of lossless streams, null for lossy ones",
+                    "type": "integer"
+                },
+                "channelsN": {
+                    "description": "Number of audio channels",
+                    "type": "integer"
+                },
+                "codec": {
+                    "description": "Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Comment from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "container": {
+                    "description": "Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known",
+                    "type": "string"
+                },
+                "coverId": {
+                    "description": "Identifier of the cover resolved for the audioFile, null if it has none",
+                    "type": "integer"
+                },
+                "dirId": {
+                    "description": "Directory identifier where the audioFile resides",
+                    "type": "integer"
+                },
+                "discNumber": {
+                    "description": "Disc number, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "durationMs": {
+                    "description": "Duration of the audioFile in milliseconds",
+                    "type": "integer"
+                },
+                "encoder": {
+                    "description": "Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known",
+                    "type": "string"
+                },
+                "extension": {
+                    "description": "File extension of the audioFile",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "Filename of the audioFile",
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "Genre from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "lastContentUpdate": {
+                    "description": "Time of the last update to the audioFile's content",
+                    "type": "string"
+                },
+                "lossless": {
+                    "description": "Whether the stream is lossless, null if it isn't known",
+                    "type": "boolean"
+                },
+                "sampleCount": {
+                    "description": "Number of samples per channel, null if it isn't known",
+                    "type": "integer"
+                },
+                "sampleRateHz": {
+                    "description": "Sample rate in hertz",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "description": "SHA-256 hash of the file",
+                    "type": "string"
+                },
+                "sizeByte": {
+                    "description": "File size in bytes",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Title from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "trackNumber": {
+                    "description": "Track number on the disc, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "year": {
+                    "description": "Release year, null if the tags don't have it",
+                    "type": "integer"
+                }
+            }
+        },
+        "audio_file_handler.getCoverResponse": {
+            "type": "object",
+            "properties": {
+                "averageLuminance": {
+                    "description": "Mean relative luminance from 0 (black) to 1 (white), null until the picture is analyzed by a scan.",
+                    "type": "number"
+                },
+                "coverId": {
+                    "description": "Unique identifier for the cover.",
+                    "type": "integer"
+                },
+                "dominantColor": {
+                    "description": "The most common color as \"#rrggbb\", null until the picture is analyzed by a scan.",
+                    "type": "string"
+                },
+                "extension": {
+                    "description": "File extension of the cover.",
+                    "type": "string"
+                },
+                "heightPx": {
+                    "description": "Height of the cover in pixels.",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "What the cover shows: front, back, disc or booklet.",
+                    "type": "string"
+                },
+                "lastContentUpdate": {
+                    "description": "Timestamp of the last content update.",
+                    "type": "string"
+                },
+                "mutedColor": {
+                    "description": "The most common unsaturated color of medium lightness, null if the picture has none.",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "SHA-256 hash of the cover.",
+                    "type": "string"
+                },
+                "sizeByte": {
+                    "description": "File size of the cover in bytes.",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Where the cover comes from: file in a directory or embedded into audio files.",
+                    "type": "string"
+                },
+                "vibrantColor": {
+                    "description": "The most common saturated color of medium lightness, null if the picture has none.",
+                    "type": "string"
+                },
+                "widthPx": {
+                    "description": "Width of the cover in pixels.",
+                    "type": "integer"
+                }
+            }
+        },
+        "audio_file_handler.searchResponse": {
+            "type": "object",
+            "properties": {
+                "audioFiles": {
+                    "description": "Array of audioFiles that match the search query.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audio_file_handler.searchResponseItem"
+                    }
+                }
+            }
+        },
+        "audio_file_handler.searchResponseItem": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "description": "Album from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "artist": {
+                    "description": "Artist from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "audioFileId": {
+                    "description": "Unique identifier for the audioFile.",
+                    "type": "integer"
+                },
+                "bitrateKbps": {
+                    "description": "Bitrate of the audioFile in Kbps.",
+                    "type": "integer"
+                },
+                "bitrateMode": {
+                    "description": "CBR or VBR, null if it isn't known",
+                    "type": "string"
+                },
+                "bitsPerSample": {
+                    "description": "Bits per sample of lossless streams, null for lossy ones",
+                    "type": "integer"
+                },
+                "channelsN": {
+                    "description": "Number of channels in the audioFile.",
+                    "type": "integer"
+                },
+                "codec": {
+                    "description": "Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Comment from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "container": {
+                    "description": "Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known",
+                    "type": "string"
+                },
+                "coverId": {
+                    "description": "Identifier of the cover resolved for the audioFile, null if it has none",
+                    "type": "integer"
+                },
+                "dirId": {
+                    "description": "Directory ID where the audioFile is located.",
+                    "type": "integer"
+                },
+                "discNumber": {
+                    "description": "Disc number, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "durationMs": {
+                    "description": "Duration of the audioFile in milliseconds.",
+                    "type": "integer"
+                },
+                "encoder": {
+                    "description": "Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known",
+                    "type": "string"
+                },
+                "extension": {
+                    "description": "File extension of the audioFile.",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "Filename of the audioFile.",
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "Genre from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "lastContentUpdate": {
+                    "description": "Timestamp of the last content update.",
+                    "type": "string"
+                },
+                "lossless": {
+                    "description": "Whether the stream is lossless, null if it isn't known",
+                    "type": "boolean"
+                },
+                "sampleCount": {
+                    "description": "Number of samples per channel, null if it isn't known",
+                    "type": "integer"
+                },
+                "sampleRateHz": {
+                    "description": "Sample rate of the audioFile in Hz.",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "description": "SHA-256 hash of the audioFile.",
+                    "type": "string"
+                },
+                "sizeByte": {
+                    "description": "File size of the audioFile in bytes.",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Title from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "trackNumber": {
+                    "description": "Track number on the disc, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "year": {
+                    "description": "Release year, null if the tags don't have it",
+                    "type": "integer"
+                }
+            }
+        },
+        "audio_file_handler.setCoverOverrideRequest": {
+            "type": "object",
+            "required": [
+                "coverId"
+            ],
+            "properties": {
+                "coverId": {
+                    "description": "Identifier of any known cover, it doesn't have to be in the directory of the audio file",
+                    "type": "integer"
+                }
+            }
+        },
+        "cover_handler.coverGroupResponse": {
+            "type": "object",
+            "properties": {
+                "canonical": {
+                    "description": "The variant with the highest resolution, its distance is 0",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/cover_handler.similarCoverResponse"
+                        }
+                    ]
+                },
+                "variants": {
+                    "description": "The other variants with their distance to the canonical one",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cover_handler.similarCoverResponse"
+                    }
+                }
+            }
+        },
+        "cover_handler.getCoverResponse": {
+            "type": "object",
+            "properties": {
+                "averageLuminance": {
+                    "description": "Mean relative luminance from 0 (black) to 1 (white), null until the picture is analyzed by a scan",
+                    "type": "number"
+                },
+                "coverId": {
+                    "description": "Unique identifier for the cover",
+                    "type": "integer"
+                },
+                "dominantColor": {
+                    "description": "The most common color as \"#rrggbb\", null until the picture is analyzed by a scan",
+                    "type": "string"
+                },
+                "extension": {
+                    "description": "File extension of the cover",
+                    "type": "string"
+                },
+                "heightPx": {
+                    "description": "Height of the cover in pixels",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "What the cover shows: front, back, disc or booklet",
                     "type": "string"
                 },
                 "lastContentUpdate": {
-                    "description": "Time of the last update to the audioFile's content",
+                    "description": "Timestamp of the last content update",
                     "type": "string"
                 },
-                "sampleRateHz": {
-                    "description": "Sample rate in hertz",
+                "mutedColor": {
+                    "description": "The most common unsaturated color of medium lightness, null if the picture has none",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "SHA-256 hash of the cover",
+                    "type": "string"
+                },
+                "sizeByte": {
+                    "description": "File size of the cover in bytes",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Where the cover comes from: file in a directory or embedded into audio files",
+                    "type": "string"
+                },
+                "vibrantColor": {
+                    "description": "The most common saturated color of medium lightness, null if the picture has none",
+                    "type": "string"
+                },
+                "widthPx": {
+                    "description": "Width of the cover in pixels",
+                    "type": "integer"
+                }
+            }
+        },
+        "cover_handler.similarCoverResponse": {
+            "type": "object",
+            "properties": {
+                "coverId": {
+                    "description": "Unique identifier for the cover",
+                    "type": "integer"
+                },
+                "dirId": {
+                    "description": "Directory of the cover file, null for covers embedded into audio files",
                     "type": "integer"
                 },
+                "distance": {
+                    "description": "Number of bits the perceptual hashes differ in, 0 for the same picture",
+                    "type": "integer"
+                },
+                "filename": {
+                    "description": "Name of the cover file",
+                    "type": "string"
+                },
+                "heightPx": {
+                    "description": "Height of the cover in pixels",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "What the cover shows: front, back, disc or booklet",
+                    "type": "string"
+                },
                 "sha256": {
-                    "description": "SHA-256 hash of the file",
+                    "description": "SHA-256 hash of the cover",
                     "type": "string"
                 },
                 "sizeByte": {
-                    "description": "File size in bytes",
+                    "description": "File size of the cover in bytes",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Where the cover comes from: file in a directory or embedded into audio files",
+                    "type": "string"
+                },
+                "widthPx": {
+                    "description": "Width of the cover in pixels",
                     "type": "integer"
                 }
             }
         },
-        "audio_file_handler.getAudioFilesResponse": {
+        "dir_handler.addRootToWatchListRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "description": "Path to the directory on disk",
+                    "type": "string"
+                },
+                "symlinkPolicy": {
+                    "description": "How scans treat symlinks: ignore, within_root or anywhere. Default is ignore",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.addRootToWatchListResponse": {
+            "type": "object",
+            "properties": {
+                "dirId": {
+                    "description": "Unique identifier of the directory in the database",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name of the directory",
+                    "type": "string"
+                },
+                "symlinkPolicy": {
+                    "description": "How scans treat symlinks below the root",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.contentResponse": {
             "type": "object",
             "properties": {
                 "audioFiles": {
                     "description": "Array containing audioFile items",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/audio_file_handler.getAudioFilesResponseItem"
+                        "$ref": "#/definitions/dir_handler.contentResponseAudioFileItem"
+                    }
+                },
+                "dirs": {
+                    "description": "Array containing directory items",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dir_handler.contentResponseDirItem"
                     }
                 }
             }
         },
-        "audio_file_handler.getAudioFilesResponseItem": {
+        "dir_handler.contentResponseAudioFileItem": {
             "type": "object",
             "properties": {
+                "album": {
+                    "description": "Album from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "artist": {
+                    "description": "Artist from the tags, null if the file has none",
+                    "type": "string"
+                },
                 "audioFileId": {
                     "description": "Unique identifier for the audioFile",
                     "type": "integer"
@@ -790,18 +2612,50 @@ const docTemplate = `{
                     "description": "Bitrate in kilobits per second",
                     "type": "integer"
                 },
+                "bitrateMode": {
+                    "description": "CBR or VBR, null if it isn't known",
+                    "type": "string"
+                },
+                "bitsPerSample": {
+                    "description": "Bits per sample of lossless streams, null for lossy ones",
+                    "type": "integer"
+                },
                 "channelsN": {
                     "description": "Number of audio channels",
                     "type": "integer"
                 },
+                "codec": {
+                    "description": "Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Comment from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "container": {
+                    "description": "Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known",
+                    "type": "string"
+                },
+                "coverId": {
+                    "description": "Identifier of the cover resolved for the audioFile, null if it has none",
+                    "type": "integer"
+                },
                 "dirId": {
                     "description": "Directory identifier where the audioFile resides",
                     "type": "integer"
                 },
+                "discNumber": {
+                    "description": "Disc number, null if the tags don't have it",
+                    "type": "integer"
+                },
                 "durationMs": {
                     "description": "Duration of the audioFile in milliseconds",
                     "type": "integer"
                 },
+                "encoder": {
+                    "description": "Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known",
+                    "type": "string"
+                },
                 "extension": {
                     "description": "File extension of the audioFile",
                     "type": "string"
@@ -810,10 +2664,22 @@ const docTemplate = `{
                     "description": "Filename of the audioFile",
                     "type": "string"
                 },
+                "genre": {
+                    "description": "Genre from the tags, null if the file has none",
+                    "type": "string"
+                },
                 "lastContentUpdate": {
                     "description": "Time of the last update to the audioFile's content",
                     "type": "string"
                 },
+                "lossless": {
+                    "description": "Whether the stream is lossless, null if it isn't known",
+                    "type": "boolean"
+                },
+                "sampleCount": {
+                    "description": "Number of samples per channel, null if it isn't known",
+                    "type": "integer"
+                },
                 "sampleRateHz": {
                     "description": "Sample rate in hertz",
                     "type": "integer"
@@ -825,122 +2691,213 @@ const docTemplate = `{
                 "sizeByte": {
                     "description": "File size in bytes",
                     "type": "integer"
+                },
+                "title": {
+                    "description": "Title from the tags, null if the file has none",
+                    "type": "string"
+                },
+                "trackNumber": {
+                    "description": "Track number on the disc, null if the tags don't have it",
+                    "type": "integer"
+                },
+                "year": {
+                    "description": "Release year, null if the tags don't have it",
+                    "type": "integer"
                 }
             }
         },
-        "audio_file_handler.getCoverResponse": {
+        "dir_handler.contentResponseDirItem": {
             "type": "object",
             "properties": {
-                "coverId": {
-                    "description": "Unique identifier for the cover.",
+                "dirId": {
+                    "description": "Unique identifier for the directory",
                     "type": "integer"
                 },
-                "extension": {
-                    "description": "File extension of the cover.",
+                "name": {
+                    "description": "Name of the directory",
                     "type": "string"
                 },
-                "heightPx": {
-                    "description": "Height of the cover in pixels.",
+                "offlineSince": {
+                    "description": "Time since which the directory is unavailable on disk. Absent while it is online",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.getDirResponse": {
+            "type": "object",
+            "properties": {
+                "absolutePath": {
+                    "description": "Absolute path to directory",
+                    "type": "string"
+                },
+                "coverOverrideId": {
+                    "description": "Identifier of the cover pinned to the directory, null if none is pinned",
                     "type": "integer"
                 },
-                "lastContentUpdate": {
-                    "description": "Timestamp of the last content update.",
+                "dirId": {
+                    "description": "Unique identifier for the directory",
+                    "type": "integer"
+                },
+                "lastScanned": {
+                    "description": "Last time the directory was scanned",
                     "type": "string"
                 },
-                "sha256": {
-                    "description": "SHA-256 hash of the cover.",
+                "name": {
+                    "description": "Name of the directory",
                     "type": "string"
                 },
-                "sizeByte": {
-                    "description": "File size of the cover in bytes.",
+                "offlineSince": {
+                    "description": "Time since which the directory is unavailable on disk. Absent while it is online",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.getIgnorePatternsResponse": {
+            "type": "object",
+            "properties": {
+                "patterns": {
+                    "description": "Gitignore-style patterns in the order they apply",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dir_handler.getRootsResponse": {
+            "type": "object",
+            "properties": {
+                "dirs": {
+                    "description": "Array containing root directories",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dir_handler.getRootsResponseItem"
+                    }
+                }
+            }
+        },
+        "dir_handler.getRootsResponseItem": {
+            "type": "object",
+            "properties": {
+                "dirId": {
+                    "description": "Unique identifier for the directory",
                     "type": "integer"
                 },
-                "widthPx": {
-                    "description": "Width of the cover in pixels.",
+                "lastScanned": {
+                    "description": "Last time the directory was scanned",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the directory",
+                    "type": "string"
+                },
+                "offlineSince": {
+                    "description": "Time since which the directory is unavailable on disk. Absent while it is online",
+                    "type": "string"
+                },
+                "symlinkPolicy": {
+                    "description": "How scans treat symlinks below the root: ignore, within_root or anywhere",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.scanAllResponse": {
+            "type": "object",
+            "properties": {
+                "scanJobId": {
+                    "description": "Identifier of the submitted scan job",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "State of the submitted scan job",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.scanResponse": {
+            "type": "object",
+            "properties": {
+                "scanJobId": {
+                    "description": "Identifier of the submitted scan job",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "State of the submitted scan job",
+                    "type": "string"
+                }
+            }
+        },
+        "dir_handler.setCoverOverrideRequest": {
+            "type": "object",
+            "required": [
+                "coverId"
+            ],
+            "properties": {
+                "coverId": {
+                    "description": "Identifier of any known cover, it doesn't have to be in this directory",
                     "type": "integer"
                 }
             }
         },
-        "audio_file_handler.searchBySha256Response": {
+        "dir_handler.setIgnorePatternsRequest": {
             "type": "object",
             "properties": {
-                "audioFiles": {
-                    "description": "Array of audioFiles that match the search query.",
+                "patterns": {
+                    "description": "Gitignore-style patterns relative to the root, e.g. \"@eaDir/\", \".*\" or \"Samples/\". Later patterns win, \"!\" negates",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/audio_file_handler.searchBySha256ResponseItem"
+                        "type": "string"
                     }
                 }
             }
         },
-        "audio_file_handler.searchBySha256ResponseItem": {
+        "dir_handler.setSymlinkPolicyRequest": {
             "type": "object",
+            "required": [
+                "symlinkPolicy"
+            ],
             "properties": {
-                "audioFileId": {
-                    "description": "Unique identifier for the audioFile.",
-                    "type": "integer"
-                },
-                "bitrateKbps": {
-                    "description": "Bitrate of the audioFile in Kbps.",
-                    "type": "integer"
-                },
-                "channelsN": {
-                    "description": "Number of channels in the audioFile.",
-                    "type": "integer"
-                },
-                "dirId": {
-                    "description": "Directory ID where the audioFile is located.",
-                    "type": "integer"
-                },
-                "durationMs": {
-                    "description": "Duration of the audioFile in milliseconds.",
-                    "type": "integer"
-                },
-                "extension": {
-                    "description": "File extension of the audioFile.",
-                    "type": "string"
-                },
-                "filename": {
-                    "description": "Filename of the audioFile.",
-                    "type": "string"
-                },
-                "lastContentUpdate": {
-                    "description": "Timestamp of the last content update.",
-                    "type": "string"
-                },
-                "sampleRateHz": {
-                    "description": "Sample rate of the audioFile in Hz.",
-                    "type": "integer"
-                },
-                "sha256": {
-                    "description": "SHA-256 hash of the audioFile.",
+                "symlinkPolicy": {
+                    "description": "ignore skips symlinks, within_root follows those leading inside the root, anywhere follows all of them",
                     "type": "string"
-                },
-                "sizeByte": {
-                    "description": "File size of the audioFile in bytes.",
-                    "type": "integer"
                 }
             }
         },
-        "cover_handler.getCoverResponse": {
+        "dir_handler.uploadCoverResponse": {
             "type": "object",
             "properties": {
+                "averageLuminance": {
+                    "description": "Mean relative luminance from 0 (black) to 1 (white), null if the picture can't be decoded",
+                    "type": "number"
+                },
                 "coverId": {
                     "description": "Unique identifier for the cover",
                     "type": "integer"
                 },
-                "extension": {
-                    "description": "File extension of the cover",
+                "dominantColor": {
+                    "description": "The most common color as \"#rrggbb\", null if the picture can't be decoded",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "Name of the file written into the directory",
                     "type": "string"
                 },
                 "heightPx": {
                     "description": "Height of the cover in pixels",
                     "type": "integer"
                 },
+                "kind": {
+                    "description": "What the cover shows, assigned by the cover rules",
+                    "type": "string"
+                },
                 "lastContentUpdate": {
                     "description": "Timestamp of the last content update",
                     "type": "string"
                 },
+                "mutedColor": {
+                    "description": "The most common unsaturated color of medium lightness, null if the picture has none",
+                    "type": "string"
+                },
                 "sha256": {
                     "description": "SHA-256 hash of the cover",
                     "type": "string"
@@ -949,174 +2906,369 @@ const docTemplate = `{
                     "description": "File size of the cover in bytes",
                     "type": "integer"
                 },
+                "vibrantColor": {
+                    "description": "The most common saturated color of medium lightness, null if the picture has none",
+                    "type": "string"
+                },
                 "widthPx": {
                     "description": "Width of the cover in pixels",
                     "type": "integer"
                 }
             }
         },
-        "dir_handler.addRootToWatchListRequest": {
+        "response.Error": {
             "type": "object",
             "properties": {
-                "path": {
-                    "description": "Path to the directory on disk",
+                "message": {
+                    "description": "Human-readable error message",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Internal error description",
                     "type": "string"
                 }
             }
         },
-        "dir_handler.addRootToWatchListResponse": {
+        "scan_error_handler.getScanErrorsResponse": {
+            "type": "object",
+            "properties": {
+                "scanErrors": {
+                    "description": "Array containing scan errors",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scan_error_handler.getScanErrorsResponseItem"
+                    }
+                }
+            }
+        },
+        "scan_error_handler.getScanErrorsResponseItem": {
             "type": "object",
             "properties": {
                 "dirId": {
-                    "description": "Unique identifier of the directory in the database",
+                    "description": "Directory containing the problem file",
                     "type": "integer"
                 },
-                "name": {
-                    "description": "Name of the directory",
+                "filename": {
+                    "description": "Name of the problem file, empty when the directory itself could not be scanned",
+                    "type": "string"
+                },
+                "occurredAt": {
+                    "description": "Time of the last failed attempt",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Absolute path to the problem file or directory",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Description of the failure",
+                    "type": "string"
+                },
+                "scanErrorId": {
+                    "description": "Unique identifier of the scan error",
+                    "type": "integer"
+                },
+                "stage": {
+                    "description": "Step that failed: directory, detect, stat, hash, tags or cover",
                     "type": "string"
                 }
             }
         },
-        "dir_handler.contentResponse": {
+        "scan_job_handler.getJobResponse": {
             "type": "object",
             "properties": {
-                "audioFiles": {
-                    "description": "Array containing audioFile items",
+                "bytesProcessed": {
+                    "description": "Number of bytes read while hashing",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "Time the job was submitted",
+                    "type": "string"
+                },
+                "currentPath": {
+                    "description": "Path that is being processed right now or was processed last",
+                    "type": "string"
+                },
+                "deepVerify": {
+                    "description": "Whether every file is hashed regardless of its size and modification time",
+                    "type": "boolean"
+                },
+                "dirIds": {
+                    "description": "Scanned directories, empty when all roots are scanned",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dir_handler.contentResponseAudioFileItem"
+                        "type": "integer"
                     }
                 },
-                "dirs": {
-                    "description": "Array containing directory items",
+                "dirsFailed": {
+                    "description": "Number of directories skipped because their scan failed",
+                    "type": "integer"
+                },
+                "dirsVisited": {
+                    "description": "Number of directories visited so far",
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "description": "Whether the job only computes the report without writing changes",
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Reason of the failure",
+                    "type": "string"
+                },
+                "filesHashed": {
+                    "description": "Number of files hashed so far",
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "description": "Time the job was finished",
+                    "type": "string"
+                },
+                "recursive": {
+                    "description": "Whether existing subdirectories are scanned too",
+                    "type": "boolean"
+                },
+                "scanJobId": {
+                    "description": "Unique identifier of the scan job",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "description": "Time the job was started",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "State of the job: queued, running, completed, failed or cancelled",
+                    "type": "string"
+                },
+                "triggeredBy": {
+                    "description": "Source of the job: api or watcher",
+                    "type": "string"
+                }
+            }
+        },
+        "scan_job_handler.getJobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "description": "Array containing scan jobs, newest first",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dir_handler.contentResponseDirItem"
+                        "$ref": "#/definitions/scan_job_handler.getJobsResponseItem"
                     }
                 }
             }
         },
-        "dir_handler.contentResponseAudioFileItem": {
+        "scan_job_handler.getJobsResponseItem": {
             "type": "object",
             "properties": {
-                "audioFileId": {
-                    "description": "Unique identifier for the audioFile",
+                "bytesProcessed": {
+                    "description": "Number of bytes read while hashing",
                     "type": "integer"
                 },
-                "bitrateKbps": {
-                    "description": "Bitrate in kilobits per second",
-                    "type": "integer"
+                "createdAt": {
+                    "description": "Time the job was submitted",
+                    "type": "string"
                 },
-                "channelsN": {
-                    "description": "Number of audio channels",
-                    "type": "integer"
+                "deepVerify": {
+                    "description": "Whether every file is hashed regardless of its size and modification time",
+                    "type": "boolean"
                 },
-                "dirId": {
-                    "description": "Directory identifier where the audioFile resides",
+                "dirIds": {
+                    "description": "Scanned directories, empty when all roots are scanned",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dirsFailed": {
+                    "description": "Number of directories skipped because their scan failed",
                     "type": "integer"
                 },
-                "durationMs": {
-                    "description": "Duration of the audioFile in milliseconds",
+                "dirsVisited": {
+                    "description": "Number of directories visited",
                     "type": "integer"
                 },
-                "extension": {
-                    "description": "File extension of the audioFile",
-                    "type": "string"
+                "dryRun": {
+                    "description": "Whether the job only computes the report without writing changes",
+                    "type": "boolean"
                 },
-                "filename": {
-                    "description": "Filename of the audioFile",
+                "error": {
+                    "description": "Reason of the failure",
                     "type": "string"
                 },
-                "lastContentUpdate": {
-                    "description": "Time of the last update to the audioFile's content",
+                "filesHashed": {
+                    "description": "Number of files hashed",
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "description": "Time the job was finished",
                     "type": "string"
                 },
-                "sampleRateHz": {
-                    "description": "Sample rate in hertz",
+                "recursive": {
+                    "description": "Whether existing subdirectories are scanned too",
+                    "type": "boolean"
+                },
+                "scanJobId": {
+                    "description": "Unique identifier of the scan job",
                     "type": "integer"
                 },
-                "sha256": {
-                    "description": "SHA-256 hash of the file",
+                "startedAt": {
+                    "description": "Time the job was started",
                     "type": "string"
                 },
-                "sizeByte": {
-                    "description": "File size in bytes",
-                    "type": "integer"
+                "status": {
+                    "description": "State of the job: queued, running, completed, failed or cancelled",
+                    "type": "string"
+                },
+                "triggeredBy": {
+                    "description": "Source of the job: api or watcher",
+                    "type": "string"
                 }
             }
         },
-        "dir_handler.contentResponseDirItem": {
+        "scan_job_handler.getReportResponse": {
             "type": "object",
             "properties": {
-                "dirId": {
-                    "description": "Unique identifier for the directory",
+                "bytesProcessed": {
+                    "description": "Number of bytes read while hashing",
                     "type": "integer"
                 },
-                "name": {
-                    "description": "Name of the directory",
+                "createdAt": {
+                    "description": "Time the job was submitted",
                     "type": "string"
-                }
-            }
-        },
-        "dir_handler.getDirResponse": {
-            "type": "object",
-            "properties": {
-                "absolutePath": {
-                    "description": "Absolute path to directory",
+                },
+                "dirsFailed": {
+                    "description": "Number of directories skipped because their scan failed",
+                    "type": "integer"
+                },
+                "dirsVisited": {
+                    "description": "Number of directories visited",
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "description": "Whether the changes were only computed and not written",
+                    "type": "boolean"
+                },
+                "durationMs": {
+                    "description": "Time between start and finish in milliseconds",
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "All changes in the order they were made",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scan_job_handler.getReportResponseEntry"
+                    }
+                },
+                "filesHashed": {
+                    "description": "Number of files hashed",
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "description": "Time the job was finished",
                     "type": "string"
                 },
-                "dirId": {
-                    "description": "Unique identifier for the directory",
+                "scanJobId": {
+                    "description": "Unique identifier of the scan job",
                     "type": "integer"
                 },
-                "lastScanned": {
-                    "description": "Last time the directory was scanned",
+                "startedAt": {
+                    "description": "Time the job was started",
                     "type": "string"
                 },
-                "name": {
-                    "description": "Name of the directory",
+                "status": {
+                    "description": "State of the job: queued, running, completed, failed or cancelled",
                     "type": "string"
+                },
+                "summary": {
+                    "description": "Counters of changes",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/scan_job_handler.getReportResponseSummary"
+                        }
+                    ]
                 }
             }
         },
-        "dir_handler.getRootsResponse": {
+        "scan_job_handler.getReportResponseEntry": {
             "type": "object",
             "properties": {
-                "dirs": {
-                    "description": "Array containing root directories",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dir_handler.getRootsResponseItem"
-                    }
+                "action": {
+                    "description": "What happened: created, updated, moved, deleted or ignored",
+                    "type": "string"
+                },
+                "entity": {
+                    "description": "What it happened to: directory, audio_file, cover or file for ignored paths",
+                    "type": "string"
+                },
+                "entityId": {
+                    "description": "Identifier of the directory, audio file or cover. Absent for entities created by a dry run and for ignored paths",
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "Absolute path on disk. For moved entities it is the new location",
+                    "type": "string"
                 }
             }
         },
-        "dir_handler.getRootsResponseItem": {
+        "scan_job_handler.getReportResponseSummary": {
             "type": "object",
             "properties": {
-                "dirId": {
-                    "description": "Unique identifier for the directory",
+                "audioFilesCreated": {
+                    "description": "Number of new audio files",
                     "type": "integer"
                 },
-                "lastScanned": {
-                    "description": "Last time the directory was scanned",
-                    "type": "string"
+                "audioFilesDeleted": {
+                    "description": "Number of audio files no longer on disk",
+                    "type": "integer"
                 },
-                "name": {
-                    "description": "Name of the directory",
-                    "type": "string"
+                "audioFilesMoved": {
+                    "description": "Number of audio files found under another directory or name, they keep their ids",
+                    "type": "integer"
+                },
+                "audioFilesUpdated": {
+                    "description": "Number of audio files with changed content",
+                    "type": "integer"
+                },
+                "coversCreated": {
+                    "description": "Number of new covers",
+                    "type": "integer"
+                },
+                "coversDeleted": {
+                    "description": "Number of covers no longer on disk",
+                    "type": "integer"
+                },
+                "coversMoved": {
+                    "description": "Number of covers found under another directory or name, they keep their ids",
+                    "type": "integer"
+                },
+                "coversUpdated": {
+                    "description": "Number of covers with changed content",
+                    "type": "integer"
+                },
+                "dirsCreated": {
+                    "description": "Number of directories found on disk",
+                    "type": "integer"
+                },
+                "dirsDeleted": {
+                    "description": "Number of directories no longer on disk",
+                    "type": "integer"
+                },
+                "pathsIgnored": {
+                    "description": "Number of directories and files skipped by ignore patterns",
+                    "type": "integer"
                 }
             }
         },
-        "response.Error": {
+        "scan_job_handler.resumeResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "description": "Human-readable error message",
-                    "type": "string"
+                "scanJobId": {
+                    "description": "Identifier of the resumed scan job",
+                    "type": "integer"
                 },
-                "reason": {
-                    "description": "Internal error description",
+                "status": {
+                    "description": "State of the resumed scan job",
                     "type": "string"
                 }
             }
@@ -1126,7 +3278,7 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "0.4",
+	Version:          "0.4.2",
 	Host:             "localhost:8022",
 	BasePath:         "/api",
 	Schemes:          []string{},
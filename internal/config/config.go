@@ -3,13 +3,19 @@ package config
 import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
+	"music-files/internal/utils"
 	"strings"
+	"time"
 )
 
 type Configuration struct {
 	*Database
 	*HttpServer
 	*Logger
+	*Watcher
+	*Scanner
+	*Covers
+	*Thumbnails
 }
 
 type Database struct {
@@ -24,10 +30,95 @@ type Logger struct {
 	Level zerolog.Level
 }
 
+type Watcher struct {
+	// Watch tracked roots for changes and rescan affected directories
+	Enabled bool
+	// Quiet period after the last event before changes are applied
+	Debounce time.Duration
+	// Period of stat-based polling for roots where filesystem events are unavailable
+	PollInterval time.Duration
+	// Use polling for all roots instead of filesystem events
+	ForcePolling bool
+}
+
+type Scanner struct {
+	// Number of files hashed and parsed at the same time
+	Workers int
+	// Upper bound of the total read speed while hashing. Zero means no limit
+	ReadBytesPerSecond int64
+	// How long content of an offline directory is kept before a scan purges it. Zero means until purged explicitly
+	OfflineGracePeriod time.Duration
+	// Deepest directory level below a root that is scanned. Zero means no limit
+	MaxDepth int
+	// Names of readers of audio metadata in the order they are tried, the next one is used when a reader fails
+	MetadataReaders []string
+}
+
+type Covers struct {
+	// Which images are covers, what they show and which front cover is the best
+	Rules utils.CoverRules
+	// Directory of the content-addressed store of pictures extracted from audio files
+	EmbeddedDir string
+	// Use the picture embedded in an audio file before looking for cover files, otherwise only when there are none
+	PreferEmbedded bool
+	// Name of uploaded cover files without extension, the extension follows the image format
+	UploadName string
+	// Largest uploaded image that is accepted
+	UploadMaxBytes int64
+	// Largest Hamming distance between perceptual hashes of covers that are taken for the same artwork
+	SimilarityMaxDistance int
+}
+
+type Thumbnails struct {
+	// Directory of rendered variants of covers
+	CacheDir string
+	// Upper bound of the cache size, the least recently used variants are evicted. Zero means no limit
+	CacheMaxBytes int64
+	// Largest width or height that can be requested
+	MaxSize int
+	// Variants rendered for covers found by scans, so the first request doesn't wait
+	Pregenerate []utils.ThumbnailSpec
+	// Width and height of mosaics of directories without own covers when the request doesn't set them
+	MosaicSize int
+}
+
 func LoadConfiguration() (config *Configuration, err error) {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	viper.SetDefault("WATCHER_ENABLED", true)
+	viper.SetDefault("WATCHER_DEBOUNCE", "3s")
+	viper.SetDefault("WATCHER_POLL_INTERVAL", "5m")
+	viper.SetDefault("WATCHER_FORCE_POLLING", false)
+	viper.SetDefault("SCANNER_WORKERS", 4)
+	viper.SetDefault("SCANNER_READ_BYTES_PER_SECOND", 0)
+	viper.SetDefault("SCANNER_OFFLINE_GRACE_PERIOD", "720h")
+	viper.SetDefault("SCANNER_MAX_DEPTH", 64)
+	viper.SetDefault("SCANNER_METADATA_READERS", "native,taglib")
+	viper.SetDefault("COVERS_RULES", "back:*back*,disc:cd*,disc:disc*,disc:disk*,booklet:booklet*,booklet:inlay*,booklet:inside*,"+
+		"front:cover,front:folder,front:front,front:cover*,front:folder*,front:front*,front:albumart*,front:album*,front:*cover*")
+	viper.SetDefault("COVERS_SUBFOLDERS", "artwork:front,covers:front,scans:booklet")
+	viper.SetDefault("COVERS_ANY_SQUARE_IMAGE", false)
+	viper.SetDefault("COVERS_EMBEDDED_DIR", "embedded-covers")
+	viper.SetDefault("COVERS_PREFER_EMBEDDED", false)
+	viper.SetDefault("COVERS_UPLOAD_NAME", "cover")
+	viper.SetDefault("COVERS_UPLOAD_MAX_BYTES", 32*1024*1024)
+	viper.SetDefault("COVERS_SIMILARITY_MAX_DISTANCE", 10)
+	viper.SetDefault("THUMBNAILS_CACHE_DIR", "thumbnail-cache")
+	viper.SetDefault("THUMBNAILS_CACHE_MAX_BYTES", 512*1024*1024)
+	viper.SetDefault("THUMBNAILS_MAX_SIZE", 2048)
+	viper.SetDefault("THUMBNAILS_PREGENERATE", "")
+	viper.SetDefault("THUMBNAILS_MOSAIC_SIZE", 600)
+
+	coverRules, err := utils.ParseCoverRules(viper.GetString("COVERS_RULES"), viper.GetString("COVERS_SUBFOLDERS"), viper.GetBool("COVERS_ANY_SQUARE_IMAGE"))
+	if err != nil {
+		return nil, err
+	}
+	pregeneratedThumbnails, err := utils.ParseThumbnailSpecs(viper.GetString("THUMBNAILS_PREGENERATE"), viper.GetInt("THUMBNAILS_MAX_SIZE"))
+	if err != nil {
+		return nil, err
+	}
+
 	config = &Configuration{
 		&Database{
 			ConnectionString: viper.GetString("WAKARIMI_MUSIC_FILES_DB_STRING"),
@@ -38,6 +129,34 @@ func LoadConfiguration() (config *Configuration, err error) {
 		&Logger{
 			Level: loadLoggingLevel(),
 		},
+		&Watcher{
+			Enabled:      viper.GetBool("WATCHER_ENABLED"),
+			Debounce:     viper.GetDuration("WATCHER_DEBOUNCE"),
+			PollInterval: viper.GetDuration("WATCHER_POLL_INTERVAL"),
+			ForcePolling: viper.GetBool("WATCHER_FORCE_POLLING"),
+		},
+		&Scanner{
+			Workers:            viper.GetInt("SCANNER_WORKERS"),
+			ReadBytesPerSecond: viper.GetInt64("SCANNER_READ_BYTES_PER_SECOND"),
+			OfflineGracePeriod: viper.GetDuration("SCANNER_OFFLINE_GRACE_PERIOD"),
+			MaxDepth:           viper.GetInt("SCANNER_MAX_DEPTH"),
+			MetadataReaders:    strings.Split(viper.GetString("SCANNER_METADATA_READERS"), ","),
+		},
+		&Covers{
+			Rules:                 coverRules,
+			EmbeddedDir:           viper.GetString("COVERS_EMBEDDED_DIR"),
+			PreferEmbedded:        viper.GetBool("COVERS_PREFER_EMBEDDED"),
+			UploadName:            viper.GetString("COVERS_UPLOAD_NAME"),
+			UploadMaxBytes:        viper.GetInt64("COVERS_UPLOAD_MAX_BYTES"),
+			SimilarityMaxDistance: viper.GetInt("COVERS_SIMILARITY_MAX_DISTANCE"),
+		},
+		&Thumbnails{
+			CacheDir:      viper.GetString("THUMBNAILS_CACHE_DIR"),
+			CacheMaxBytes: viper.GetInt64("THUMBNAILS_CACHE_MAX_BYTES"),
+			MaxSize:       viper.GetInt("THUMBNAILS_MAX_SIZE"),
+			Pregenerate:   pregeneratedThumbnails,
+			MosaicSize:    viper.GetInt("THUMBNAILS_MOSAIC_SIZE"),
+		},
 	}
 
 	return config, nil
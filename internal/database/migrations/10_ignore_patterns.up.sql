@@ -0,0 +1,9 @@
+CREATE TABLE ignore_patterns
+(
+    ignore_pattern_id SERIAL PRIMARY KEY,
+    dir_id            INTEGER NOT NULL,
+    position          INTEGER NOT NULL,
+    pattern           TEXT    NOT NULL,
+    UNIQUE (dir_id, position),
+    FOREIGN KEY (dir_id) REFERENCES directories (dir_id) ON DELETE CASCADE
+);
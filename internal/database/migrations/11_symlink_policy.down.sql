@@ -0,0 +1 @@
+ALTER TABLE directories DROP COLUMN symlink_policy;
@@ -0,0 +1 @@
+ALTER TABLE directories ADD COLUMN symlink_policy VARCHAR(16) NULL;
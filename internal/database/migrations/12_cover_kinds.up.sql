@@ -0,0 +1 @@
+ALTER TABLE covers ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'front';
@@ -0,0 +1,7 @@
+DROP INDEX idx_audio_files_embedded_cover_id;
+ALTER TABLE audio_files DROP COLUMN embedded_cover_id;
+
+DELETE FROM covers WHERE source = 'embedded';
+DROP INDEX idx_covers_embedded_sha_256;
+ALTER TABLE covers ALTER COLUMN dir_id SET NOT NULL;
+ALTER TABLE covers DROP COLUMN source;
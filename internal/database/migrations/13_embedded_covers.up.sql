@@ -0,0 +1,7 @@
+ALTER TABLE covers ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'file';
+ALTER TABLE covers ALTER COLUMN dir_id DROP NOT NULL;
+CREATE UNIQUE INDEX idx_covers_embedded_sha_256 ON covers (sha_256) WHERE source = 'embedded';
+
+ALTER TABLE audio_files ADD COLUMN embedded_cover_id INTEGER NULL;
+ALTER TABLE audio_files ADD FOREIGN KEY (embedded_cover_id) REFERENCES covers (cover_id) ON DELETE SET NULL;
+CREATE INDEX idx_audio_files_embedded_cover_id ON audio_files (embedded_cover_id);
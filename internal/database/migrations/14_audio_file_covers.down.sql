@@ -0,0 +1,2 @@
+DROP INDEX idx_audio_files_cover_id;
+ALTER TABLE audio_files DROP COLUMN cover_id;
@@ -0,0 +1,3 @@
+ALTER TABLE audio_files ADD COLUMN cover_id INTEGER NULL;
+ALTER TABLE audio_files ADD FOREIGN KEY (cover_id) REFERENCES covers (cover_id) ON DELETE SET NULL;
+CREATE INDEX idx_audio_files_cover_id ON audio_files (cover_id);
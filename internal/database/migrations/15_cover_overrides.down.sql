@@ -0,0 +1,5 @@
+DROP INDEX idx_audio_files_cover_override_id;
+ALTER TABLE audio_files DROP COLUMN cover_override_id;
+
+DROP INDEX idx_directories_cover_override_id;
+ALTER TABLE directories DROP COLUMN cover_override_id;
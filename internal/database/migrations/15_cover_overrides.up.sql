@@ -0,0 +1,7 @@
+ALTER TABLE directories ADD COLUMN cover_override_id INTEGER NULL;
+ALTER TABLE directories ADD FOREIGN KEY (cover_override_id) REFERENCES covers (cover_id) ON DELETE SET NULL;
+CREATE INDEX idx_directories_cover_override_id ON directories (cover_override_id);
+
+ALTER TABLE audio_files ADD COLUMN cover_override_id INTEGER NULL;
+ALTER TABLE audio_files ADD FOREIGN KEY (cover_override_id) REFERENCES covers (cover_id) ON DELETE SET NULL;
+CREATE INDEX idx_audio_files_cover_override_id ON audio_files (cover_override_id);
@@ -0,0 +1 @@
+ALTER TABLE covers DROP COLUMN perceptual_hash;
@@ -0,0 +1 @@
+ALTER TABLE covers ADD COLUMN perceptual_hash BIGINT NULL;
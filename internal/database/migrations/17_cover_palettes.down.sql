@@ -0,0 +1,4 @@
+ALTER TABLE covers DROP COLUMN average_luminance;
+ALTER TABLE covers DROP COLUMN muted_color;
+ALTER TABLE covers DROP COLUMN vibrant_color;
+ALTER TABLE covers DROP COLUMN dominant_color;
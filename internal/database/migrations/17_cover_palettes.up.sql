@@ -0,0 +1,4 @@
+ALTER TABLE covers ADD COLUMN dominant_color VARCHAR(7) NULL;
+ALTER TABLE covers ADD COLUMN vibrant_color VARCHAR(7) NULL;
+ALTER TABLE covers ADD COLUMN muted_color VARCHAR(7) NULL;
+ALTER TABLE covers ADD COLUMN average_luminance DOUBLE PRECISION NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE audio_files DROP COLUMN comment;
+ALTER TABLE audio_files DROP COLUMN genre;
+ALTER TABLE audio_files DROP COLUMN year;
+ALTER TABLE audio_files DROP COLUMN disc_number;
+ALTER TABLE audio_files DROP COLUMN track_number;
+ALTER TABLE audio_files DROP COLUMN album;
+ALTER TABLE audio_files DROP COLUMN artist;
+ALTER TABLE audio_files DROP COLUMN title;
@@ -0,0 +1,8 @@
+ALTER TABLE audio_files ADD COLUMN title TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN artist TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN album TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN track_number INTEGER NULL;
+ALTER TABLE audio_files ADD COLUMN disc_number INTEGER NULL;
+ALTER TABLE audio_files ADD COLUMN year INTEGER NULL;
+ALTER TABLE audio_files ADD COLUMN genre TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN comment TEXT NULL;
@@ -0,0 +1 @@
+DROP TABLE audio_file_tags;
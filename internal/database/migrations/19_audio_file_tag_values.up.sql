@@ -0,0 +1,11 @@
+CREATE TABLE audio_file_tags
+(
+    audio_file_id INTEGER NOT NULL,
+    key           TEXT    NOT NULL,
+    position      INTEGER NOT NULL,
+    value         TEXT    NOT NULL,
+    PRIMARY KEY (audio_file_id, key, position),
+    FOREIGN KEY (audio_file_id) REFERENCES audio_files (audio_file_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_audio_file_tags_key_value ON audio_file_tags (key, value);
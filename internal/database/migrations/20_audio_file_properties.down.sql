@@ -0,0 +1,8 @@
+DROP INDEX idx_audio_files_codec;
+ALTER TABLE audio_files DROP COLUMN sample_count;
+ALTER TABLE audio_files DROP COLUMN encoder;
+ALTER TABLE audio_files DROP COLUMN bitrate_mode;
+ALTER TABLE audio_files DROP COLUMN lossless;
+ALTER TABLE audio_files DROP COLUMN bits_per_sample;
+ALTER TABLE audio_files DROP COLUMN container;
+ALTER TABLE audio_files DROP COLUMN codec;
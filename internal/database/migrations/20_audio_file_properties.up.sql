@@ -0,0 +1,8 @@
+ALTER TABLE audio_files ADD COLUMN codec TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN container TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN bits_per_sample INTEGER NULL;
+ALTER TABLE audio_files ADD COLUMN lossless BOOLEAN NULL;
+ALTER TABLE audio_files ADD COLUMN bitrate_mode TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN encoder TEXT NULL;
+ALTER TABLE audio_files ADD COLUMN sample_count BIGINT NULL;
+CREATE INDEX idx_audio_files_codec ON audio_files (codec);
@@ -0,0 +1 @@
+ALTER TABLE directories ADD COLUMN mount_point BOOLEAN NOT NULL DEFAULT FALSE;
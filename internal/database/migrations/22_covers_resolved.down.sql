@@ -0,0 +1 @@
+ALTER TABLE directories DROP COLUMN covers_resolved;
@@ -0,0 +1 @@
+ALTER TABLE directories ADD COLUMN covers_resolved BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+CREATE TABLE scan_jobs
+(
+    scan_job_id     SERIAL PRIMARY KEY,
+    dir_id          INTEGER     NULL,
+    status          VARCHAR(16) NOT NULL,
+    dirs_visited    BIGINT      NOT NULL DEFAULT 0,
+    files_hashed    BIGINT      NOT NULL DEFAULT 0,
+    bytes_processed BIGINT      NOT NULL DEFAULT 0,
+    current_path    TEXT        NULL,
+    error           TEXT        NULL,
+    created_at      TIMESTAMP   NOT NULL,
+    started_at      TIMESTAMP   NULL,
+    finished_at     TIMESTAMP   NULL
+);
+
+CREATE INDEX idx_scan_jobs_status ON scan_jobs (status);
@@ -0,0 +1,8 @@
+ALTER TABLE scan_jobs DROP COLUMN recursive;
+ALTER TABLE scan_jobs DROP COLUMN triggered_by;
+ALTER TABLE scan_jobs ADD COLUMN dir_id INTEGER NULL;
+
+UPDATE scan_jobs
+SET dir_id = (SELECT MIN(dir_id) FROM scan_job_dirs WHERE scan_job_dirs.scan_job_id = scan_jobs.scan_job_id);
+
+DROP TABLE scan_job_dirs;
@@ -0,0 +1,16 @@
+CREATE TABLE scan_job_dirs
+(
+    scan_job_id INTEGER NOT NULL,
+    dir_id      INTEGER NOT NULL,
+    PRIMARY KEY (scan_job_id, dir_id),
+    FOREIGN KEY (scan_job_id) REFERENCES scan_jobs (scan_job_id) ON DELETE CASCADE
+);
+
+INSERT INTO scan_job_dirs(scan_job_id, dir_id)
+SELECT scan_job_id, dir_id
+FROM scan_jobs
+WHERE dir_id IS NOT NULL;
+
+ALTER TABLE scan_jobs DROP COLUMN dir_id;
+ALTER TABLE scan_jobs ADD COLUMN triggered_by VARCHAR(16) NOT NULL DEFAULT 'api';
+ALTER TABLE scan_jobs ADD COLUMN recursive BOOLEAN NOT NULL DEFAULT TRUE;
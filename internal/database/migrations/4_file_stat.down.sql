@@ -0,0 +1,7 @@
+ALTER TABLE scan_jobs DROP COLUMN deep_verify;
+
+ALTER TABLE covers DROP COLUMN inode;
+ALTER TABLE covers DROP COLUMN modified_at;
+
+ALTER TABLE audio_files DROP COLUMN inode;
+ALTER TABLE audio_files DROP COLUMN modified_at;
@@ -0,0 +1,7 @@
+ALTER TABLE audio_files ADD COLUMN modified_at TIMESTAMP NULL;
+ALTER TABLE audio_files ADD COLUMN inode BIGINT NULL;
+
+ALTER TABLE covers ADD COLUMN modified_at TIMESTAMP NULL;
+ALTER TABLE covers ADD COLUMN inode BIGINT NULL;
+
+ALTER TABLE scan_jobs ADD COLUMN deep_verify BOOLEAN NOT NULL DEFAULT FALSE;
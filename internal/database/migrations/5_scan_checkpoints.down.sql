@@ -0,0 +1,3 @@
+ALTER TABLE scan_jobs DROP COLUMN dirs_failed;
+
+DROP TABLE scan_job_pending_dirs;
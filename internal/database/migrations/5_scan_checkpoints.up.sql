@@ -0,0 +1,11 @@
+CREATE TABLE scan_job_pending_dirs
+(
+    pending_dir_id BIGSERIAL PRIMARY KEY,
+    scan_job_id    INTEGER NOT NULL,
+    dir_id         INTEGER NOT NULL,
+    UNIQUE (scan_job_id, dir_id),
+    FOREIGN KEY (scan_job_id) REFERENCES scan_jobs (scan_job_id) ON DELETE CASCADE,
+    FOREIGN KEY (dir_id) REFERENCES directories (dir_id) ON DELETE CASCADE
+);
+
+ALTER TABLE scan_jobs ADD COLUMN dirs_failed BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE scan_errors
+(
+    scan_error_id SERIAL PRIMARY KEY,
+    dir_id        INTEGER     NOT NULL,
+    filename      TEXT        NOT NULL,
+    stage         VARCHAR(16) NOT NULL,
+    reason        TEXT        NOT NULL,
+    occurred_at   TIMESTAMP   NOT NULL,
+    UNIQUE (dir_id, filename),
+    FOREIGN KEY (dir_id) REFERENCES directories (dir_id) ON DELETE CASCADE
+);
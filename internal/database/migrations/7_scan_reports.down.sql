@@ -0,0 +1,3 @@
+ALTER TABLE scan_jobs DROP COLUMN dry_run;
+
+DROP TABLE scan_report_entries;
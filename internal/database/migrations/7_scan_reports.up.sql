@@ -0,0 +1,14 @@
+CREATE TABLE scan_report_entries
+(
+    scan_report_entry_id SERIAL PRIMARY KEY,
+    scan_job_id          INTEGER     NOT NULL,
+    action               VARCHAR(16) NOT NULL,
+    entity               VARCHAR(16) NOT NULL,
+    entity_id            INTEGER     NULL,
+    path                 TEXT        NOT NULL,
+    FOREIGN KEY (scan_job_id) REFERENCES scan_jobs (scan_job_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_scan_report_entries_scan_job_id ON scan_report_entries (scan_job_id);
+
+ALTER TABLE scan_jobs ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+DROP TABLE scan_job_pending_deletions;
+
+DROP INDEX idx_covers_sha_256;
+DROP INDEX idx_audio_files_payload_sha_256;
+DROP INDEX idx_audio_files_sha_256;
+
+ALTER TABLE audio_files DROP COLUMN payload_sha_256;
@@ -0,0 +1,15 @@
+ALTER TABLE audio_files ADD COLUMN payload_sha_256 CHAR(64) NULL;
+
+CREATE INDEX idx_audio_files_sha_256 ON audio_files (sha_256);
+CREATE INDEX idx_audio_files_payload_sha_256 ON audio_files (payload_sha_256);
+CREATE INDEX idx_covers_sha_256 ON covers (sha_256);
+
+CREATE TABLE scan_job_pending_deletions
+(
+    pending_deletion_id SERIAL PRIMARY KEY,
+    scan_job_id         INTEGER     NOT NULL,
+    entity              VARCHAR(16) NOT NULL,
+    entity_id           INTEGER     NOT NULL,
+    UNIQUE (scan_job_id, entity, entity_id),
+    FOREIGN KEY (scan_job_id) REFERENCES scan_jobs (scan_job_id) ON DELETE CASCADE
+);
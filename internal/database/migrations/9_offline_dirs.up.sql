@@ -0,0 +1 @@
+ALTER TABLE directories ADD COLUMN offline_since TIMESTAMP NULL;
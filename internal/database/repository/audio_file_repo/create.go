@@ -11,8 +11,14 @@ func (r Repository) Create(tx *sqlx.Tx, audioFile model.AudioFile) (audioFileId
 	log.Debug().Interface("audioFile", audioFile).Msg("Creating new audio file in database")
 
 	query := `
-		INSERT INTO audio_files(dir_id, filename, extension, size_byte, duration_ms, bitrate_kbps, sample_rate_hz, channels_n, sha_256, last_content_update)
-		VALUES (:dir_id, :filename, :extension, :size_byte, :duration_ms, :bitrate_kbps, :sample_rate_hz, :channels_n, :sha_256, CURRENT_TIMESTAMP)
+		INSERT INTO audio_files(dir_id, filename, extension, size_byte, duration_ms, bitrate_kbps, sample_rate_hz, channels_n, sha_256, last_content_update,
+		                        modified_at, inode, payload_sha_256, embedded_cover_id,
+		                        title, artist, album, track_number, disc_number, year, genre, comment,
+		                        codec, container, bits_per_sample, lossless, bitrate_mode, encoder, sample_count)
+		VALUES (:dir_id, :filename, :extension, :size_byte, :duration_ms, :bitrate_kbps, :sample_rate_hz, :channels_n, :sha_256, CURRENT_TIMESTAMP,
+		        :modified_at, :inode, :payload_sha_256, :embedded_cover_id,
+		        :title, :artist, :album, :track_number, :disc_number, :year, :genre, :comment,
+		        :codec, :container, :bits_per_sample, :lossless, :bitrate_mode, :encoder, :sample_count)
 		RETURNING audio_file_id
 	`
 	rows, err := tx.NamedQuery(query, audioFile)
@@ -0,0 +1,73 @@
+package audio_file_repo
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"sort"
+	"strings"
+)
+
+// ReadAllByFilter reads audio files matching every condition of the filter. Tag conditions match if the value
+// is among the values of the field
+func (r Repository) ReadAllByFilter(tx *sqlx.Tx, filter model.AudioFileFilter) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Interface("filter", filter).Msg("Reading audio files by filter from database")
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i := range values {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		conditions = append(conditions, fmt.Sprintf(format, placeholders...))
+		args = append(args, values...)
+	}
+
+	keys := make([]string, 0, len(filter.Tags))
+	for key := range filter.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		addCondition(`EXISTS (
+			SELECT 1
+			FROM audio_file_tags t
+			WHERE t.audio_file_id = a.audio_file_id AND t.key = %s AND t.value = %s
+		)`, key, filter.Tags[key])
+	}
+	if filter.Codec != nil {
+		addCondition("a.codec = %s", *filter.Codec)
+	}
+	if filter.Container != nil {
+		addCondition("a.container = %s", *filter.Container)
+	}
+	if filter.BitsPerSample != nil {
+		addCondition("a.bits_per_sample = %s", *filter.BitsPerSample)
+	}
+	if filter.Lossless != nil {
+		addCondition("a.lossless = %s", *filter.Lossless)
+	}
+	if filter.BitrateMode != nil {
+		addCondition("a.bitrate_mode = %s", *filter.BitrateMode)
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "TRUE")
+	}
+
+	query := `
+		SELECT a.*
+		FROM audio_files a
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY a.audio_file_id
+	`
+	err = tx.Select(&audioFiles, query, args...)
+	if err != nil {
+		log.Error().Err(err).Interface("filter", filter).Str("query", query).Msg("Failed to execute query to read audio files by filter")
+		return nil, err
+	}
+
+	log.Debug().Interface("filter", filter).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files by filter read successfully")
+	return audioFiles, nil
+}
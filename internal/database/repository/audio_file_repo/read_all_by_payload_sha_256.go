@@ -0,0 +1,44 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadAllByPayloadSha256 finds audio files whose audio data matches regardless of tags
+func (r Repository) ReadAllByPayloadSha256(tx *sqlx.Tx, payloadSha256 string) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Str("payloadSha256", payloadSha256).Msg("Reading audio files by payload sha256 from database")
+
+	query := `
+		SELECT * 
+		FROM audio_files
+		WHERE payload_sha_256 = :payload_sha_256
+	`
+	args := map[string]interface{}{
+		"payload_sha_256": payloadSha256,
+	}
+	rows, err := tx.NamedQuery(query, args)
+	if err != nil {
+		log.Error().Err(err).Str("payloadSha256", payloadSha256).Str("query", query).Msg("Failed to execute query to read audio files by payload sha256")
+		return nil, err
+	}
+	defer func(rows *sqlx.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close rows")
+		}
+	}(rows)
+
+	for rows.Next() {
+		var audioFile model.AudioFile
+		if err = rows.StructScan(&audioFile); err != nil {
+			log.Error().Err(err).Str("payloadSha256", payloadSha256).Msg("Failed to get read result")
+			return nil, err
+		}
+		audioFiles = append(audioFiles, audioFile)
+	}
+
+	log.Debug().Str("payloadSha256", payloadSha256).Int("countOfAudioFilesWithPayloadSha256", len(audioFiles)).Msg("Audio files by payload sha256 read successfully")
+	return audioFiles, nil
+}
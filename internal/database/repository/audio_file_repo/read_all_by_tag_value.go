@@ -0,0 +1,31 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadAllByTagValue reads audio files having the value in any field whose key matches the LIKE pattern
+func (r Repository) ReadAllByTagValue(tx *sqlx.Tx, keyPattern string, value string) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Str("keyPattern", keyPattern).Str("value", value).Msg("Reading audio files by tag value from database")
+
+	query := `
+		SELECT a.*
+		FROM audio_files a
+		WHERE EXISTS (
+			SELECT 1
+			FROM audio_file_tags t
+			WHERE t.audio_file_id = a.audio_file_id AND t.key LIKE $1 AND t.value = $2
+		)
+		ORDER BY a.audio_file_id
+	`
+	err = tx.Select(&audioFiles, query, keyPattern, value)
+	if err != nil {
+		log.Error().Err(err).Str("keyPattern", keyPattern).Str("value", value).Str("query", query).Msg("Failed to execute query to read audio files by tag value")
+		return nil, err
+	}
+
+	log.Debug().Str("keyPattern", keyPattern).Str("value", value).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files by tag value read successfully")
+	return audioFiles, nil
+}
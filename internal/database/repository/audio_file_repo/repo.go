@@ -11,8 +11,17 @@ type Repo interface {
 	ReadByDirAndName(tx *sqlx.Tx, dirId int, name string) (audioFile model.AudioFile, err error)
 	ReadAll(tx *sqlx.Tx) (audioFiles []model.AudioFile, err error)
 	ReadAllBySha256(tx *sqlx.Tx, sha256 string) (audioFiles []model.AudioFile, err error)
+	ReadAllByPayloadSha256(tx *sqlx.Tx, payloadSha256 string) (audioFiles []model.AudioFile, err error)
 	ReadAllByDir(tx *sqlx.Tx, dirId int) (audioFiles []model.AudioFile, err error)
+	ReadAllByFilter(tx *sqlx.Tx, filter model.AudioFileFilter) (audioFiles []model.AudioFile, err error)
+	ReadAllByTagValue(tx *sqlx.Tx, keyPattern string, value string) (audioFiles []model.AudioFile, err error)
 	Update(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error)
+	UpdateFileStat(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error)
+	UpdateTags(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error)
+	UpdateProperties(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error)
+	UpdateLocation(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error)
+	UpdateCover(tx *sqlx.Tx, audioFileId int, coverId *int) (err error)
+	UpdateCoverOverride(tx *sqlx.Tx, audioFileId int, coverId *int) (err error)
 	Delete(tx *sqlx.Tx, audioFileId int) (err error)
 	IsExists(tx *sqlx.Tx, audioFileId int) (exists bool, err error)
 	IsExistsByDirAndName(tx *sqlx.Tx, dirId int, name string) (exists bool, err error)
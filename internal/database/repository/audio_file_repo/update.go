@@ -13,7 +13,12 @@ func (r Repository) Update(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFi
 		UPDATE audio_files
 		SET dir_id = :dir_id, filename = :filename, extension = :extension, size_byte = :size_byte,
 		    duration_ms = :duration_ms, bitrate_kbps = :bitrate_kbps, sample_rate_hz = :sample_rate_hz,
-		    channels_n = :channels_n, sha_256 = :sha_256, last_content_update = CURRENT_TIMESTAMP
+		    channels_n = :channels_n, sha_256 = :sha_256, last_content_update = CURRENT_TIMESTAMP,
+		    modified_at = :modified_at, inode = :inode, payload_sha_256 = :payload_sha_256,
+		    embedded_cover_id = :embedded_cover_id, title = :title, artist = :artist, album = :album,
+		    track_number = :track_number, disc_number = :disc_number, year = :year, genre = :genre, comment = :comment,
+		    codec = :codec, container = :container, bits_per_sample = :bits_per_sample, lossless = :lossless,
+		    bitrate_mode = :bitrate_mode, encoder = :encoder, sample_count = :sample_count
 		WHERE audio_file_id = :audio_file_id
 	`
 
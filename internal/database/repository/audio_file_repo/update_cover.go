@@ -0,0 +1,30 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateCover stores the cover resolved for an audio file, nil if it has none
+func (r Repository) UpdateCover(tx *sqlx.Tx, audioFileId int, coverId *int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating cover of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET cover_id = :cover_id
+		WHERE audio_file_id = :audio_file_id
+	`
+	args := map[string]interface{}{
+		"audio_file_id": audioFileId,
+		"cover_id":      coverId,
+	}
+	_, err = tx.NamedExec(query, args)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update cover of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover of audio file updated successfully")
+	return nil
+}
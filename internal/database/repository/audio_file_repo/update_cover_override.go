@@ -0,0 +1,30 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateCoverOverride pins a cover to an audio file, nil clears the pin
+func (r Repository) UpdateCoverOverride(tx *sqlx.Tx, audioFileId int, coverId *int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating cover override of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET cover_override_id = :cover_override_id
+		WHERE audio_file_id = :audio_file_id
+	`
+	args := map[string]interface{}{
+		"audio_file_id":     audioFileId,
+		"cover_override_id": coverId,
+	}
+	_, err = tx.NamedExec(query, args)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update cover override of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover override of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,30 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateFileStat refreshes size, modification time, inode, payload hash and embedded cover without touching the content fields
+func (r Repository) UpdateFileStat(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating file stat of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET size_byte = :size_byte, modified_at = :modified_at, inode = :inode, payload_sha_256 = :payload_sha_256,
+		    embedded_cover_id = :embedded_cover_id
+		WHERE audio_file_id = :audio_file_id
+	`
+
+	audioFile.AudioFileId = audioFileId
+	_, err = tx.NamedExec(query, audioFile)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update file stat of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("File stat of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,31 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateLocation moves an audio file to another directory or name. The content is the same, so last_content_update is kept
+func (r Repository) UpdateLocation(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Int("dirId", audioFile.DirId).Str("filename", audioFile.Filename).Msg("Updating location of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET dir_id = :dir_id, filename = :filename, extension = :extension,
+		    size_byte = :size_byte, modified_at = :modified_at, inode = :inode, payload_sha_256 = :payload_sha_256,
+		    embedded_cover_id = :embedded_cover_id
+		WHERE audio_file_id = :audio_file_id
+	`
+
+	audioFile.AudioFileId = audioFileId
+	_, err = tx.NamedExec(query, audioFile)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update location of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Location of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,31 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateProperties refreshes technical properties of the audio stream without touching the content fields
+func (r Repository) UpdateProperties(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating properties of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET duration_ms = :duration_ms, bitrate_kbps = :bitrate_kbps, sample_rate_hz = :sample_rate_hz, channels_n = :channels_n,
+		    codec = :codec, container = :container, bits_per_sample = :bits_per_sample, lossless = :lossless,
+		    bitrate_mode = :bitrate_mode, encoder = :encoder, sample_count = :sample_count
+		WHERE audio_file_id = :audio_file_id
+	`
+
+	audioFile.AudioFileId = audioFileId
+	_, err = tx.NamedExec(query, audioFile)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update properties of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Properties of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,30 @@
+package audio_file_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateTags refreshes textual tags without touching the content fields
+func (r Repository) UpdateTags(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating tags of audio file")
+
+	query := `
+		UPDATE audio_files
+		SET title = :title, artist = :artist, album = :album, track_number = :track_number, disc_number = :disc_number,
+		    year = :year, genre = :genre, comment = :comment
+		WHERE audio_file_id = :audio_file_id
+	`
+
+	audioFile.AudioFileId = audioFileId
+	_, err = tx.NamedExec(query, audioFile)
+
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to update tags of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Tags of audio file updated successfully")
+	return nil
+}
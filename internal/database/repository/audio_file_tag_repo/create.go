@@ -0,0 +1,24 @@
+package audio_file_tag_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, audioFileTag model.AudioFileTag) (err error) {
+	log.Debug().Int("audioFileId", audioFileTag.AudioFileId).Str("key", audioFileTag.Key).Int("position", audioFileTag.Position).Msg("Creating audio file tag")
+
+	query := `
+		INSERT INTO audio_file_tags(audio_file_id, key, position, value)
+		VALUES (:audio_file_id, :key, :position, :value)
+	`
+	_, err = tx.NamedExec(query, audioFileTag)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileTag.AudioFileId).Str("key", audioFileTag.Key).Str("query", query).Msg("Failed to execute query to create audio file tag")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileTag.AudioFileId).Str("key", audioFileTag.Key).Msg("Audio file tag created successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package audio_file_tag_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) DeleteAllByAudioFile(tx *sqlx.Tx, audioFileId int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Deleting tags of audio file")
+
+	query := `
+		DELETE FROM audio_file_tags
+		WHERE audio_file_id = :audio_file_id
+	`
+	args := map[string]interface{}{
+		"audio_file_id": audioFileId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to delete tags of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Tags of audio file deleted successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package audio_file_tag_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllByAudioFile(tx *sqlx.Tx, audioFileId int) (audioFileTags []model.AudioFileTag, err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Reading tags by audio file from database")
+
+	query := `
+		SELECT *
+		FROM audio_file_tags
+		WHERE audio_file_id = $1
+		ORDER BY key, position
+	`
+	err = tx.Select(&audioFileTags, query, audioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Str("query", query).Msg("Failed to execute query to read tags by audioFileId")
+		return nil, err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Int("countOfTags", len(audioFileTags)).Msg("Tags by audioFileId read successfully")
+	return audioFileTags, nil
+}
@@ -0,0 +1,19 @@
+package audio_file_tag_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, audioFileTag model.AudioFileTag) (err error)
+	ReadAllByAudioFile(tx *sqlx.Tx, audioFileId int) (audioFileTags []model.AudioFileTag, err error)
+	DeleteAllByAudioFile(tx *sqlx.Tx, audioFileId int) (err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -11,8 +11,10 @@ func (r Repository) Create(tx *sqlx.Tx, cover model.Cover) (coverId int, err err
 	log.Debug().Interface("cover", cover).Msg("Creating new cover in database")
 
 	query := `
-		INSERT INTO covers(dir_id, filename, extension, size_byte, width_px, height_px, sha_256, last_content_update)
-		VALUES (:dir_id, :filename, :extension, :size_byte, :width_px, :height_px, :sha_256, CURRENT_TIMESTAMP)
+		INSERT INTO covers(dir_id, filename, extension, size_byte, width_px, height_px, sha_256, last_content_update, modified_at, inode, kind, source, perceptual_hash,
+		                   dominant_color, vibrant_color, muted_color, average_luminance)
+		VALUES (:dir_id, :filename, :extension, :size_byte, :width_px, :height_px, :sha_256, CURRENT_TIMESTAMP, :modified_at, :inode, :kind, :source, :perceptual_hash,
+		        :dominant_color, :vibrant_color, :muted_color, :average_luminance)
 		RETURNING cover_id
 	`
 	rows, err := tx.NamedQuery(query, cover)
@@ -0,0 +1,27 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// DeleteUnusedEmbedded deletes embedded covers no audio file refers to anymore and returns them
+func (r Repository) DeleteUnusedEmbedded(tx *sqlx.Tx) (deletedCovers []model.Cover, err error) {
+	log.Debug().Msg("Deleting unused embedded covers from database")
+
+	query := `
+		DELETE FROM covers
+		WHERE source = $1
+			AND NOT EXISTS (SELECT 1 FROM audio_files WHERE audio_files.embedded_cover_id = covers.cover_id)
+		RETURNING *
+	`
+	err = tx.Select(&deletedCovers, query, model.CoverSourceEmbedded)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Failed to delete unused embedded covers from database")
+		return nil, err
+	}
+
+	log.Debug().Int("countOfDeletedCovers", len(deletedCovers)).Msg("Unused embedded covers deleted from database successfully")
+	return deletedCovers, nil
+}
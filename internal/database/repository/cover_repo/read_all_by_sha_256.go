@@ -0,0 +1,43 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllBySha256(tx *sqlx.Tx, sha256 string) (covers []model.Cover, err error) {
+	log.Debug().Str("sha256", sha256).Msg("Reading covers by sha256 from database")
+
+	query := `
+		SELECT * 
+		FROM covers
+		WHERE sha_256 = :sha_256
+	`
+	args := map[string]interface{}{
+		"sha_256": sha256,
+	}
+	rows, err := tx.NamedQuery(query, args)
+	if err != nil {
+		log.Error().Err(err).Str("sha256", sha256).Str("query", query).Msg("Failed to execute query to read covers by sha256")
+		return nil, err
+	}
+	defer func(rows *sqlx.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close rows")
+		}
+	}(rows)
+
+	for rows.Next() {
+		var cover model.Cover
+		if err = rows.StructScan(&cover); err != nil {
+			log.Error().Err(err).Str("sha256", sha256).Msg("Failed to get read result")
+			return nil, err
+		}
+		covers = append(covers, cover)
+	}
+
+	log.Debug().Str("sha256", sha256).Int("countOfCoversWithSha256", len(covers)).Msg("Covers by sha256 read successfully")
+	return covers, nil
+}
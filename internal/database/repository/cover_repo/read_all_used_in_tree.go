@@ -0,0 +1,44 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadAllUsedInTree reads covers resolved for audio files of the directory and all directories below it,
+// the ones used by more audio files first
+func (r Repository) ReadAllUsedInTree(tx *sqlx.Tx, dirId int, limit int) (covers []model.Cover, err error) {
+	log.Debug().Int("dirId", dirId).Int("limit", limit).Msg("Reading covers used in directory tree from database")
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT dir_id
+			FROM directories
+			WHERE dir_id = $1
+			UNION ALL
+			SELECT d.dir_id
+			FROM directories d
+			JOIN tree t ON d.parent_dir_id = t.dir_id
+		), usages AS (
+			SELECT a.cover_id, COUNT(*) AS count_of_audio_files
+			FROM audio_files a
+			JOIN tree t ON a.dir_id = t.dir_id
+			WHERE a.cover_id IS NOT NULL
+			GROUP BY a.cover_id
+		)
+		SELECT c.*
+		FROM covers c
+		JOIN usages u ON c.cover_id = u.cover_id
+		ORDER BY u.count_of_audio_files DESC, c.cover_id
+		LIMIT $2
+	`
+	err = tx.Select(&covers, query, dirId, limit)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to read covers used in directory tree")
+		return nil, err
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfCovers", len(covers)).Msg("Covers used in directory tree read successfully")
+	return covers, nil
+}
@@ -0,0 +1,27 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadAllWithPerceptualHash reads every cover whose picture has been hashed, ordered by id
+func (r Repository) ReadAllWithPerceptualHash(tx *sqlx.Tx) (covers []model.Cover, err error) {
+	log.Debug().Msg("Reading covers with perceptual hash from database")
+
+	query := `
+		SELECT * 
+		FROM covers
+		WHERE perceptual_hash IS NOT NULL
+		ORDER BY cover_id
+	`
+	err = tx.Select(&covers, query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Failed to execute query to read covers with perceptual hash")
+		return nil, err
+	}
+
+	log.Debug().Int("countOfCovers", len(covers)).Msg("Covers with perceptual hash read successfully")
+	return covers, nil
+}
@@ -10,8 +10,15 @@ type Repo interface {
 	Read(tx *sqlx.Tx, coverId int) (cover model.Cover, err error)
 	ReadByDirAndName(tx *sqlx.Tx, dirId int, name string) (cover model.Cover, err error)
 	ReadAllByDir(tx *sqlx.Tx, dirId int) (covers []model.Cover, err error)
+	ReadAllBySha256(tx *sqlx.Tx, sha256 string) (covers []model.Cover, err error)
+	ReadAllWithPerceptualHash(tx *sqlx.Tx) (covers []model.Cover, err error)
+	ReadAllUsedInTree(tx *sqlx.Tx, dirId int, limit int) (covers []model.Cover, err error)
 	Update(tx *sqlx.Tx, coverId int, cover model.Cover) (err error)
+	UpdateFileStat(tx *sqlx.Tx, coverId int, cover model.Cover) (err error)
+	UpdateLocation(tx *sqlx.Tx, coverId int, cover model.Cover) (err error)
+	UpdateAnalysis(tx *sqlx.Tx, coverId int, cover model.Cover) (err error)
 	Delete(tx *sqlx.Tx, coverId int) (err error)
+	DeleteUnusedEmbedded(tx *sqlx.Tx) (deletedCovers []model.Cover, err error)
 	IsExists(tx *sqlx.Tx, coverId int) (exists bool, err error)
 	IsExistsByDirAndName(tx *sqlx.Tx, dirId int, name string) (exists bool, err error)
 }
@@ -12,7 +12,10 @@ func (r Repository) Update(tx *sqlx.Tx, coverId int, cover model.Cover) (err err
 	query := `
 		UPDATE covers
 		SET dir_id = :dir_id, filename = :filename, extension = :extension, size_byte = :size_byte,
-		    width_px = :width_px, height_px = :height_px, sha_256 = :sha_256, last_content_update = CURRENT_TIMESTAMP
+		    width_px = :width_px, height_px = :height_px, sha_256 = :sha_256, last_content_update = CURRENT_TIMESTAMP,
+		    modified_at = :modified_at, inode = :inode, kind = :kind, perceptual_hash = :perceptual_hash,
+		    dominant_color = :dominant_color, vibrant_color = :vibrant_color, muted_color = :muted_color,
+		    average_luminance = :average_luminance
 		WHERE cover_id = :cover_id
 	`
 
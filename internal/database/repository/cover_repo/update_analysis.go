@@ -0,0 +1,31 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateAnalysis stores what is calculated from the picture of a cover whose content did not change:
+// the perceptual hash and the palette
+func (r Repository) UpdateAnalysis(tx *sqlx.Tx, coverId int, cover model.Cover) (err error) {
+	log.Debug().Int("coverId", coverId).Msg("Updating analysis of cover")
+
+	query := `
+		UPDATE covers
+		SET perceptual_hash = :perceptual_hash, dominant_color = :dominant_color, vibrant_color = :vibrant_color,
+		    muted_color = :muted_color, average_luminance = :average_luminance
+		WHERE cover_id = :cover_id
+	`
+
+	cover.CoverId = coverId
+	_, err = tx.NamedExec(query, cover)
+
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Str("query", query).Msg("Failed to execute query to update analysis of cover")
+		return err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("Analysis of cover updated successfully")
+	return nil
+}
@@ -0,0 +1,29 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateFileStat refreshes size, modification time, inode and kind without touching the content fields
+func (r Repository) UpdateFileStat(tx *sqlx.Tx, coverId int, cover model.Cover) (err error) {
+	log.Debug().Int("coverId", coverId).Msg("Updating file stat of cover")
+
+	query := `
+		UPDATE covers
+		SET size_byte = :size_byte, modified_at = :modified_at, inode = :inode, kind = :kind
+		WHERE cover_id = :cover_id
+	`
+
+	cover.CoverId = coverId
+	_, err = tx.NamedExec(query, cover)
+
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Str("query", query).Msg("Failed to execute query to update file stat of cover")
+		return err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("File stat of cover updated successfully")
+	return nil
+}
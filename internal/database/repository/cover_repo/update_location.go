@@ -0,0 +1,30 @@
+package cover_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateLocation moves a cover to another directory or name. The content is the same, so last_content_update is kept
+func (r Repository) UpdateLocation(tx *sqlx.Tx, coverId int, cover model.Cover) (err error) {
+	log.Debug().Int("coverId", coverId).Interface("dirId", cover.DirId).Str("filename", cover.Filename).Msg("Updating location of cover")
+
+	query := `
+		UPDATE covers
+		SET dir_id = :dir_id, filename = :filename, extension = :extension,
+		    size_byte = :size_byte, modified_at = :modified_at, inode = :inode, kind = :kind
+		WHERE cover_id = :cover_id
+	`
+
+	cover.CoverId = coverId
+	_, err = tx.NamedExec(query, cover)
+
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Str("query", query).Msg("Failed to execute query to update location of cover")
+		return err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("Location of cover updated successfully")
+	return nil
+}
@@ -11,8 +11,8 @@ func (r *Repository) Create(tx *sqlx.Tx, dir model.Directory) (dirId int, err er
 	log.Debug().Interface("parentDirId", dir.ParentDirId).Str("name", dir.Name).Msg("Creating new directory")
 
 	query := `
-		INSERT INTO directories(name, parent_dir_id)
-		VALUES (:name, :parent_dir_id)
+		INSERT INTO directories(name, parent_dir_id, symlink_policy)
+		VALUES (:name, :parent_dir_id, :symlink_policy)
 		RETURNING dir_id
 	`
 	rows, err := tx.NamedQuery(query, dir)
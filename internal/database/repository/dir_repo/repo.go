@@ -3,6 +3,7 @@ package dir_repo
 import (
 	"github.com/jmoiron/sqlx"
 	"music-files/internal/model"
+	"time"
 )
 
 type Repo interface {
@@ -13,6 +14,11 @@ type Repo interface {
 	ReadSubDirs(tx *sqlx.Tx, parentDirId int) (dirs []model.Directory, err error)
 	ReadByParentAndName(tx *sqlx.Tx, parentDirId *int, name string) (dir model.Directory, err error)
 	Update(tx *sqlx.Tx, dirId int, dir model.Directory) (err error)
+	UpdateOfflineSince(tx *sqlx.Tx, dirId int, offlineSince *time.Time) (err error)
+	UpdateMountPoint(tx *sqlx.Tx, dirId int, mountPoint bool) (err error)
+	UpdateCoversResolved(tx *sqlx.Tx, dirId int, coversResolved bool) (err error)
+	UpdateSymlinkPolicy(tx *sqlx.Tx, dirId int, symlinkPolicy string) (err error)
+	UpdateCoverOverride(tx *sqlx.Tx, dirId int, coverId *int) (err error)
 	Delete(tx *sqlx.Tx, dirId int) (err error)
 	IsExists(tx *sqlx.Tx, dirId int) (exists bool, err error)
 	IsExistsByParentAndName(tx *sqlx.Tx, parentDirId *int, name string) (exists bool, err error)
@@ -0,0 +1,28 @@
+package dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r *Repository) UpdateCoverOverride(tx *sqlx.Tx, dirId int, coverId *int) (err error) {
+	log.Debug().Int("dirId", dirId).Msg("Updating cover override of directory")
+
+	query := `
+		UPDATE directories
+		SET cover_override_id = :cover_override_id
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id":            dirId,
+		"cover_override_id": coverId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to update cover override of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Cover override of directory updated successfully")
+	return nil
+}
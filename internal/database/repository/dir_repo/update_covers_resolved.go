@@ -0,0 +1,29 @@
+package dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateCoversResolved records that covers of the directory's audio files are stored
+func (r *Repository) UpdateCoversResolved(tx *sqlx.Tx, dirId int, coversResolved bool) (err error) {
+	log.Debug().Int("dirId", dirId).Bool("coversResolved", coversResolved).Msg("Updating covers resolution state of directory")
+
+	query := `
+		UPDATE directories
+		SET covers_resolved = :covers_resolved
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id":          dirId,
+		"covers_resolved": coversResolved,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to update covers resolution state of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Covers resolution state of directory updated successfully")
+	return nil
+}
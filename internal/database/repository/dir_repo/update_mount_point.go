@@ -0,0 +1,29 @@
+package dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateMountPoint records whether a filesystem is mounted at the directory
+func (r *Repository) UpdateMountPoint(tx *sqlx.Tx, dirId int, mountPoint bool) (err error) {
+	log.Debug().Int("dirId", dirId).Bool("mountPoint", mountPoint).Msg("Updating mount point state of directory")
+
+	query := `
+		UPDATE directories
+		SET mount_point = :mount_point
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id":      dirId,
+		"mount_point": mountPoint,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to update mount point state of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Mount point state of directory updated successfully")
+	return nil
+}
@@ -0,0 +1,30 @@
+package dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// UpdateOfflineSince marks the directory offline from the given moment, nil marks it online
+func (r *Repository) UpdateOfflineSince(tx *sqlx.Tx, dirId int, offlineSince *time.Time) (err error) {
+	log.Debug().Int("dirId", dirId).Interface("offlineSince", offlineSince).Msg("Updating offline state of directory")
+
+	query := `
+		UPDATE directories
+		SET offline_since = :offline_since
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id":        dirId,
+		"offline_since": offlineSince,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to update offline state of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Offline state of directory updated successfully")
+	return nil
+}
@@ -0,0 +1,28 @@
+package dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r *Repository) UpdateSymlinkPolicy(tx *sqlx.Tx, dirId int, symlinkPolicy string) (err error) {
+	log.Debug().Int("dirId", dirId).Str("symlinkPolicy", symlinkPolicy).Msg("Updating symlink policy of directory")
+
+	query := `
+		UPDATE directories
+		SET symlink_policy = :symlink_policy
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id":         dirId,
+		"symlink_policy": symlinkPolicy,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to update symlink policy of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Symlink policy of directory updated successfully")
+	return nil
+}
@@ -0,0 +1,24 @@
+package ignore_pattern_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, ignorePattern model.IgnorePattern) (err error) {
+	log.Debug().Int("dirId", ignorePattern.DirId).Int("position", ignorePattern.Position).Str("pattern", ignorePattern.Pattern).Msg("Creating ignore pattern")
+
+	query := `
+		INSERT INTO ignore_patterns(dir_id, position, pattern)
+		VALUES (:dir_id, :position, :pattern)
+	`
+	_, err = tx.NamedExec(query, ignorePattern)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", ignorePattern.DirId).Str("pattern", ignorePattern.Pattern).Str("query", query).Msg("Failed to execute query to create ignore pattern")
+		return err
+	}
+
+	log.Debug().Int("dirId", ignorePattern.DirId).Str("pattern", ignorePattern.Pattern).Msg("Ignore pattern created successfully")
+	return nil
+}
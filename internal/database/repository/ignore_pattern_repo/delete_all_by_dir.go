@@ -0,0 +1,26 @@
+package ignore_pattern_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) DeleteAllByDir(tx *sqlx.Tx, dirId int) (err error) {
+	log.Debug().Int("dirId", dirId).Msg("Deleting ignore patterns of directory")
+
+	query := `
+		DELETE FROM ignore_patterns
+		WHERE dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"dir_id": dirId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to delete ignore patterns of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Ignore patterns of directory deleted successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package ignore_pattern_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllByDir(tx *sqlx.Tx, dirId int) (ignorePatterns []model.IgnorePattern, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Reading ignore patterns by directory from database")
+
+	query := `
+		SELECT *
+		FROM ignore_patterns
+		WHERE dir_id = $1
+		ORDER BY position
+	`
+	err = tx.Select(&ignorePatterns, query, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to read ignore patterns by dirId")
+		return nil, err
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfIgnorePatterns", len(ignorePatterns)).Msg("Ignore patterns by dirId read successfully")
+	return ignorePatterns, nil
+}
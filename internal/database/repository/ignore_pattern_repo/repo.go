@@ -0,0 +1,19 @@
+package ignore_pattern_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, ignorePattern model.IgnorePattern) (err error)
+	ReadAllByDir(tx *sqlx.Tx, dirId int) (ignorePatterns []model.IgnorePattern, err error)
+	DeleteAllByDir(tx *sqlx.Tx, dirId int) (err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -0,0 +1,27 @@
+package scan_error_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// Create stores the error. An earlier error of the same file is replaced
+func (r Repository) Create(tx *sqlx.Tx, scanError model.ScanError) (err error) {
+	log.Debug().Int("dirId", scanError.DirId).Str("filename", scanError.Filename).Str("stage", scanError.Stage).Msg("Creating scan error")
+
+	query := `
+		INSERT INTO scan_errors(dir_id, filename, stage, reason, occurred_at)
+		VALUES (:dir_id, :filename, :stage, :reason, CURRENT_TIMESTAMP)
+		ON CONFLICT (dir_id, filename) DO UPDATE
+		SET stage = EXCLUDED.stage, reason = EXCLUDED.reason, occurred_at = EXCLUDED.occurred_at
+	`
+	_, err = tx.NamedExec(query, scanError)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", scanError.DirId).Str("filename", scanError.Filename).Str("query", query).Msg("Failed to execute query to create scan error")
+		return err
+	}
+
+	log.Debug().Int("dirId", scanError.DirId).Str("filename", scanError.Filename).Msg("Scan error created successfully")
+	return nil
+}
@@ -0,0 +1,25 @@
+package scan_error_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAll(tx *sqlx.Tx) (scanErrors []model.ScanError, err error) {
+	log.Debug().Msg("Reading all scan errors from database")
+
+	query := `
+		SELECT *
+		FROM scan_errors
+		ORDER BY scan_error_id
+	`
+	err = tx.Select(&scanErrors, query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Failed to execute query to read scan errors")
+		return nil, err
+	}
+
+	log.Debug().Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors read successfully")
+	return scanErrors, nil
+}
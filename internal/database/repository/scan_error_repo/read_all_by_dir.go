@@ -0,0 +1,26 @@
+package scan_error_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllByDir(tx *sqlx.Tx, dirId int) (scanErrors []model.ScanError, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Reading scan errors by directory from database")
+
+	query := `
+		SELECT *
+		FROM scan_errors
+		WHERE dir_id = $1
+		ORDER BY scan_error_id
+	`
+	err = tx.Select(&scanErrors, query, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("query", query).Msg("Failed to execute query to read scan errors by dirId")
+		return nil, err
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors by dirId read successfully")
+	return scanErrors, nil
+}
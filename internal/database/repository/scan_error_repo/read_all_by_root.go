@@ -0,0 +1,36 @@
+package scan_error_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadAllByRoot reads errors of the directory and all directories below it
+func (r Repository) ReadAllByRoot(tx *sqlx.Tx, rootDirId int) (scanErrors []model.ScanError, err error) {
+	log.Debug().Int("rootDirId", rootDirId).Msg("Reading scan errors by root from database")
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT dir_id
+			FROM directories
+			WHERE dir_id = $1
+			UNION ALL
+			SELECT d.dir_id
+			FROM directories d
+			JOIN tree t ON d.parent_dir_id = t.dir_id
+		)
+		SELECT e.*
+		FROM scan_errors e
+		JOIN tree t ON e.dir_id = t.dir_id
+		ORDER BY e.scan_error_id
+	`
+	err = tx.Select(&scanErrors, query, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Str("query", query).Msg("Failed to execute query to read scan errors by root")
+		return nil, err
+	}
+
+	log.Debug().Int("rootDirId", rootDirId).Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors by root read successfully")
+	return scanErrors, nil
+}
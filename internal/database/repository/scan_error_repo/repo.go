@@ -0,0 +1,21 @@
+package scan_error_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, scanError model.ScanError) (err error)
+	ReadAll(tx *sqlx.Tx) (scanErrors []model.ScanError, err error)
+	ReadAllByDir(tx *sqlx.Tx, dirId int) (scanErrors []model.ScanError, err error)
+	ReadAllByRoot(tx *sqlx.Tx, rootDirId int) (scanErrors []model.ScanError, err error)
+	DeleteAllByDir(tx *sqlx.Tx, dirId int) (err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -0,0 +1,26 @@
+package scan_job_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) ReadAllByScanJob(tx *sqlx.Tx, scanJobId int) (dirIds []int, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Reading directories of scan job")
+
+	query := `
+		SELECT dir_id
+		FROM scan_job_dirs
+		WHERE scan_job_id = $1
+		ORDER BY dir_id
+	`
+	dirIds = make([]int, 0)
+	err = tx.Select(&dirIds, query, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to read directories of scan job")
+		return nil, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("countOfDirs", len(dirIds)).Msg("Directories of scan job read successfully")
+	return dirIds, nil
+}
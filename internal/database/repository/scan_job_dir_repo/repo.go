@@ -0,0 +1,17 @@
+package scan_job_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, scanJobId int, dirId int) (err error)
+	ReadAllByScanJob(tx *sqlx.Tx, scanJobId int) (dirIds []int, err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
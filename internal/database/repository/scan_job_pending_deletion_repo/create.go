@@ -0,0 +1,25 @@
+package scan_job_pending_deletion_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, deletion model.ScanPendingDeletion) (err error) {
+	log.Debug().Int("scanJobId", deletion.ScanJobId).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Adding pending deletion to scan job")
+
+	query := `
+		INSERT INTO scan_job_pending_deletions(scan_job_id, entity, entity_id)
+		VALUES (:scan_job_id, :entity, :entity_id)
+		ON CONFLICT DO NOTHING
+	`
+	_, err = tx.NamedExec(query, deletion)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", deletion.ScanJobId).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Str("query", query).Msg("Failed to add pending deletion to scan job")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", deletion.ScanJobId).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Pending deletion added to scan job successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package scan_job_pending_deletion_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) Delete(tx *sqlx.Tx, pendingDeletionId int) (err error) {
+	log.Debug().Int("pendingDeletionId", pendingDeletionId).Msg("Deleting pending deletion of scan job")
+
+	query := `
+		DELETE FROM scan_job_pending_deletions
+		WHERE pending_deletion_id = :pending_deletion_id
+	`
+	args := map[string]interface{}{
+		"pending_deletion_id": pendingDeletionId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("pendingDeletionId", pendingDeletionId).Str("query", query).Msg("Failed to delete pending deletion of scan job")
+		return err
+	}
+
+	log.Debug().Int("pendingDeletionId", pendingDeletionId).Msg("Pending deletion of scan job deleted successfully")
+	return nil
+}
@@ -0,0 +1,28 @@
+package scan_job_pending_deletion_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// ReadFirstByScanJob returns the earliest pending deletion, so deletions are applied in the order they were found
+func (r Repository) ReadFirstByScanJob(tx *sqlx.Tx, scanJobId int) (deletion model.ScanPendingDeletion, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Reading first pending deletion of scan job")
+
+	query := `
+		SELECT *
+		FROM scan_job_pending_deletions
+		WHERE scan_job_id = $1
+		ORDER BY pending_deletion_id
+		LIMIT 1
+	`
+	err = tx.Get(&deletion, query, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to read first pending deletion of scan job")
+		return model.ScanPendingDeletion{}, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("pendingDeletionId", deletion.PendingDeletionId).Msg("First pending deletion of scan job read successfully")
+	return deletion, nil
+}
@@ -0,0 +1,20 @@
+package scan_job_pending_deletion_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, deletion model.ScanPendingDeletion) (err error)
+	ReadFirstByScanJob(tx *sqlx.Tx, scanJobId int) (deletion model.ScanPendingDeletion, err error)
+	Delete(tx *sqlx.Tx, pendingDeletionId int) (err error)
+	IsExistsByScanJob(tx *sqlx.Tx, scanJobId int) (exists bool, err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -0,0 +1,28 @@
+package scan_job_pending_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, scanJobId int, dirId int) (err error) {
+	log.Debug().Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Adding pending directory to scan job")
+
+	query := `
+		INSERT INTO scan_job_pending_dirs(scan_job_id, dir_id)
+		VALUES (:scan_job_id, :dir_id)
+		ON CONFLICT DO NOTHING
+	`
+	args := map[string]interface{}{
+		"scan_job_id": scanJobId,
+		"dir_id":      dirId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Int("dirId", dirId).Str("query", query).Msg("Failed to add pending directory to scan job")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Pending directory added to scan job successfully")
+	return nil
+}
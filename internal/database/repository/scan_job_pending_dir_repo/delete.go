@@ -0,0 +1,28 @@
+package scan_job_pending_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) Delete(tx *sqlx.Tx, scanJobId int, dirId int) (err error) {
+	log.Debug().Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Deleting pending directory of scan job")
+
+	query := `
+		DELETE FROM scan_job_pending_dirs
+		WHERE scan_job_id = :scan_job_id
+			AND dir_id = :dir_id
+	`
+	args := map[string]interface{}{
+		"scan_job_id": scanJobId,
+		"dir_id":      dirId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Int("dirId", dirId).Str("query", query).Msg("Failed to delete pending directory of scan job")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Pending directory of scan job deleted successfully")
+	return nil
+}
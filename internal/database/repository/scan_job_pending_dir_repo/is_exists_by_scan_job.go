@@ -0,0 +1,41 @@
+package scan_job_pending_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) IsExistsByScanJob(tx *sqlx.Tx, scanJobId int) (exists bool, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Checking for pending directories of a scan job in the database")
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 
+			FROM scan_job_pending_dirs
+			WHERE scan_job_id = :scan_job_id
+		)
+	`
+	args := map[string]interface{}{
+		"scan_job_id": scanJobId,
+	}
+	row, err := tx.NamedQuery(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to check existence in database")
+		return false, err
+	}
+	defer func(row *sqlx.Rows) {
+		err := row.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close row")
+		}
+	}(row)
+	if row.Next() {
+		if err = row.Scan(&exists); err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get existence check results")
+			return false, err
+		}
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Bool("exists", exists).Msg("Pending directories of the scan job were checked successfully")
+	return exists, nil
+}
@@ -0,0 +1,27 @@
+package scan_job_pending_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// ReadLastByScanJob returns the most recently added pending directory, so the tree is walked depth-first
+func (r Repository) ReadLastByScanJob(tx *sqlx.Tx, scanJobId int) (dirId int, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Reading last pending directory of scan job")
+
+	query := `
+		SELECT dir_id
+		FROM scan_job_pending_dirs
+		WHERE scan_job_id = $1
+		ORDER BY pending_dir_id DESC
+		LIMIT 1
+	`
+	err = tx.Get(&dirId, query, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to read last pending directory of scan job")
+		return 0, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Last pending directory of scan job read successfully")
+	return dirId, nil
+}
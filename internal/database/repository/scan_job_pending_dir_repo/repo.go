@@ -0,0 +1,19 @@
+package scan_job_pending_dir_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, scanJobId int, dirId int) (err error)
+	ReadLastByScanJob(tx *sqlx.Tx, scanJobId int) (dirId int, err error)
+	Delete(tx *sqlx.Tx, scanJobId int, dirId int) (err error)
+	IsExistsByScanJob(tx *sqlx.Tx, scanJobId int) (exists bool, err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -0,0 +1,43 @@
+package scan_job_repo
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, scanJob model.ScanJob) (scanJobId int, err error) {
+	log.Debug().Interface("scanJob", scanJob).Msg("Creating new scan job in database")
+
+	query := `
+		INSERT INTO scan_jobs(status, triggered_by, recursive, deep_verify, dry_run, created_at)
+		VALUES (:status, :triggered_by, :recursive, :deep_verify, :dry_run, CURRENT_TIMESTAMP)
+		RETURNING scan_job_id
+	`
+	rows, err := tx.NamedQuery(query, scanJob)
+	if err != nil {
+		log.Error().Err(err).Interface("scanJob", scanJob).Str("query", query).Msg("Failed to create scan job in database")
+		return 0, err
+	}
+	defer func(rows *sqlx.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close rows")
+		}
+	}(rows)
+
+	if rows.Next() {
+		if err := rows.Scan(&scanJobId); err != nil {
+			log.Error().Err(err).Msg("Failed to scan scanJobId of created scan job")
+			return 0, err
+		}
+	} else {
+		err := fmt.Errorf("no id returned after scan job insert")
+		log.Error().Err(err).Interface("scanJob", scanJob).Msg("No id returned after scan job insert")
+		return 0, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Interface("scanJob", scanJob).Msg("New scan job in database created successfully")
+	return scanJobId, nil
+}
@@ -0,0 +1,45 @@
+package scan_job_repo
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Read(tx *sqlx.Tx, scanJobId int) (scanJob model.ScanJob, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Reading scan job from database")
+
+	query := `
+		SELECT *
+		FROM scan_jobs
+		WHERE scan_job_id = :scan_job_id
+	`
+	args := map[string]interface{}{
+		"scan_job_id": scanJobId,
+	}
+	rows, err := tx.NamedQuery(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to read scan job")
+		return model.ScanJob{}, err
+	}
+	defer func(rows *sqlx.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close rows")
+		}
+	}(rows)
+	if rows.Next() {
+		if err = rows.StructScan(&scanJob); err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get read result")
+			return model.ScanJob{}, err
+		}
+	} else {
+		err := fmt.Errorf("no scan job found with scan_job_id: %d", scanJobId)
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Scan job not found")
+		return model.ScanJob{}, err
+	}
+
+	log.Debug().Interface("scanJob", scanJob).Msg("Scan job read successfully")
+	return scanJob, nil
+}
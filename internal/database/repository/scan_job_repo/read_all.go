@@ -0,0 +1,25 @@
+package scan_job_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAll(tx *sqlx.Tx) (scanJobs []model.ScanJob, err error) {
+	log.Debug().Msg("Reading all scan jobs")
+
+	query := `
+		SELECT *
+		FROM scan_jobs
+		ORDER BY scan_job_id DESC
+	`
+	err = tx.Select(&scanJobs, query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Failed to execute query to read scan jobs")
+		return nil, err
+	}
+
+	log.Debug().Int("countOfScanJobs", len(scanJobs)).Msg("All scan jobs read successfully")
+	return scanJobs, nil
+}
@@ -0,0 +1,44 @@
+package scan_job_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllByStatus(tx *sqlx.Tx, status string) (scanJobs []model.ScanJob, err error) {
+	log.Debug().Str("status", status).Msg("Reading scan jobs by status from database")
+
+	query := `
+		SELECT *
+		FROM scan_jobs
+		WHERE status = :status
+		ORDER BY scan_job_id
+	`
+	args := map[string]interface{}{
+		"status": status,
+	}
+	rows, err := tx.NamedQuery(query, args)
+	if err != nil {
+		log.Error().Err(err).Str("status", status).Str("query", query).Msg("Failed to execute query to read scan jobs by status")
+		return nil, err
+	}
+	defer func(rows *sqlx.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to close rows")
+		}
+	}(rows)
+
+	for rows.Next() {
+		var scanJob model.ScanJob
+		if err = rows.StructScan(&scanJob); err != nil {
+			log.Error().Err(err).Str("status", status).Msg("Failed to get read result")
+			return nil, err
+		}
+		scanJobs = append(scanJobs, scanJob)
+	}
+
+	log.Debug().Str("status", status).Int("countOfScanJobs", len(scanJobs)).Msg("Scan jobs by status read successfully")
+	return scanJobs, nil
+}
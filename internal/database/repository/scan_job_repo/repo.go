@@ -0,0 +1,23 @@
+package scan_job_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, scanJob model.ScanJob) (scanJobId int, err error)
+	Read(tx *sqlx.Tx, scanJobId int) (scanJob model.ScanJob, err error)
+	ReadAll(tx *sqlx.Tx) (scanJobs []model.ScanJob, err error)
+	ReadAllByStatus(tx *sqlx.Tx, status string) (scanJobs []model.ScanJob, err error)
+	Update(tx *sqlx.Tx, scanJobId int, scanJob model.ScanJob) (err error)
+	UpdateIfStatus(tx *sqlx.Tx, scanJobId int, expectedStatus string, scanJob model.ScanJob) (updated bool, err error)
+	IsExists(tx *sqlx.Tx, scanJobId int) (exists bool, err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
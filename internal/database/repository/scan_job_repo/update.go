@@ -0,0 +1,30 @@
+package scan_job_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Update(tx *sqlx.Tx, scanJobId int, scanJob model.ScanJob) (err error) {
+	log.Debug().Int("scanJobId", scanJobId).Interface("scanJob", scanJob).Msg("Updating scan job")
+
+	query := `
+		UPDATE scan_jobs
+		SET status = :status, dirs_visited = :dirs_visited, dirs_failed = :dirs_failed, files_hashed = :files_hashed,
+		    bytes_processed = :bytes_processed, current_path = :current_path, error = :error,
+		    started_at = :started_at, finished_at = :finished_at
+		WHERE scan_job_id = :scan_job_id
+	`
+
+	scanJob.ScanJobId = scanJobId
+	_, err = tx.NamedExec(query, scanJob)
+
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to update scan job")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Msg("Scan job updated successfully")
+	return nil
+}
@@ -0,0 +1,44 @@
+package scan_job_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateIfStatus updates the scan job only while it still has the expected status.
+// Returns false when the status has already been changed by someone else
+func (r Repository) UpdateIfStatus(tx *sqlx.Tx, scanJobId int, expectedStatus string, scanJob model.ScanJob) (updated bool, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Str("expectedStatus", expectedStatus).Interface("scanJob", scanJob).Msg("Updating scan job with expected status")
+
+	query := `
+		UPDATE scan_jobs
+		SET status = :status, dirs_visited = :dirs_visited, dirs_failed = :dirs_failed, files_hashed = :files_hashed,
+		    bytes_processed = :bytes_processed, current_path = :current_path, error = :error,
+		    started_at = :started_at, finished_at = :finished_at
+		WHERE scan_job_id = :scan_job_id
+			AND status = :expected_status
+	`
+
+	scanJob.ScanJobId = scanJobId
+	args := struct {
+		model.ScanJob
+		ExpectedStatus string `db:"expected_status"`
+	}{
+		ScanJob:        scanJob,
+		ExpectedStatus: expectedStatus,
+	}
+	result, err := tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to update scan job with expected status")
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get number of updated scan jobs")
+		return false, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Bool("updated", affected > 0).Msg("Scan job with expected status updated successfully")
+	return affected > 0, nil
+}
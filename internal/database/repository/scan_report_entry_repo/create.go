@@ -0,0 +1,24 @@
+package scan_report_entry_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) Create(tx *sqlx.Tx, entry model.ScanReportEntry) (err error) {
+	log.Debug().Int("scanJobId", entry.ScanJobId).Str("action", entry.Action).Str("entity", entry.Entity).Msg("Creating scan report entry")
+
+	query := `
+		INSERT INTO scan_report_entries(scan_job_id, action, entity, entity_id, path)
+		VALUES (:scan_job_id, :action, :entity, :entity_id, :path)
+	`
+	_, err = tx.NamedExec(query, entry)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", entry.ScanJobId).Str("query", query).Msg("Failed to execute query to create scan report entry")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", entry.ScanJobId).Msg("Scan report entry created successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package scan_report_entry_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+func (r Repository) DeleteAllByScanJob(tx *sqlx.Tx, scanJobId int) (err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Deleting scan report entries")
+
+	query := `
+		DELETE FROM scan_report_entries
+		WHERE scan_job_id = :scan_job_id
+	`
+	args := map[string]interface{}{
+		"scan_job_id": scanJobId,
+	}
+	_, err = tx.NamedExec(query, args)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to delete scan report entries")
+		return err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Msg("Scan report entries deleted successfully")
+	return nil
+}
@@ -0,0 +1,27 @@
+package scan_report_entry_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (r Repository) ReadAllByScanJob(tx *sqlx.Tx, scanJobId int) (entries []model.ScanReportEntry, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Reading scan report entries from database")
+
+	query := `
+		SELECT *
+		FROM scan_report_entries
+		WHERE scan_job_id = $1
+		ORDER BY scan_report_entry_id
+	`
+	entries = make([]model.ScanReportEntry, 0)
+	err = tx.Select(&entries, query, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Str("query", query).Msg("Failed to execute query to read scan report entries")
+		return nil, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("countOfEntries", len(entries)).Msg("Scan report entries read successfully")
+	return entries, nil
+}
@@ -0,0 +1,19 @@
+package scan_report_entry_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/model"
+)
+
+type Repo interface {
+	Create(tx *sqlx.Tx, entry model.ScanReportEntry) (err error)
+	ReadAllByScanJob(tx *sqlx.Tx, scanJobId int) (entries []model.ScanReportEntry, err error)
+	DeleteAllByScanJob(tx *sqlx.Tx, scanJobId int) (err error)
+}
+
+type Repository struct {
+}
+
+func NewRepository() Repo {
+	return &Repository{}
+}
@@ -0,0 +1,60 @@
+package audio_file_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// ClearCoverOverride
+// @Summary Unpin the cover of an audio file
+// @Description The audio file goes back to the cover of its directory or the ones found on disk
+// @Tags AudioFiles
+// @Produce  json
+// @Param   audioFileId path int true "Audio File ID"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid audioFileId format"
+// @Failure 404 {object} response.Error "Audio file not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /audio-files/{audioFileId}/cover-override [delete]
+func (h *Handler) ClearCoverOverride(c *gin.Context) {
+	log.Debug().Msg("Clearing cover override of audio file")
+
+	audioFileIdStr := c.Param("audioFileId")
+	audioFileId, err := strconv.Atoi(audioFileIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("audioFileIdStr", audioFileIdStr).Msg("Invalid audioFileId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid audioFileId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("audioFileId", audioFileId).Msg("Url parameter read successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.SetAudioFileCoverOverride(tx, audioFileId, nil)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clear cover override")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Audio file not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to clear cover override",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover override cleared successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -4,7 +4,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
 	"music-files/internal/handler/response"
+	"music-files/internal/utils"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -15,12 +17,13 @@ import (
 // @Description Downloads a audio file identified by the audioFileId
 // @Tags AudioFiles
 // @Accept  json
-// @Produce  octet-stream
+// @Produce  octet-stream,audio/mpeg,audio/flac,audio/ogg,audio/mp4,audio/wav,audio/aiff
 // @Param   audioFileId      path    int     true        "Audio File ID"
 // @Success 200 {file} byte "Audio File"
-// @Header 200 {string} Content-Type "application/octet-stream"
+// @Header 200 {string} Content-Type "MIME type of the audio format, application/octet-stream if it is unknown"
 // @Header 200 {string} Content-Disposition "attachment; filename=[name of the file]"
 // @Failure 400 {object} response.Error "Invalid audioFileId format"
+// @Failure 404 {object} response.Error "Audio file not found, missing on disk or not allowed by the symlink policy"
 // @Failure 500 {object} response.Error "Internal Server Error, Failed to calculate absolute path"
 // @Router /audio-files/{audioFileId}/download [get]
 func (h *Handler) Download(c *gin.Context) {
@@ -48,15 +51,22 @@ func (h *Handler) Download(c *gin.Context) {
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to calculate absolute path")
-		c.JSON(http.StatusInternalServerError, response.Error{
-			Message: "Failed to calculate absolute path",
-			Reason:  err.Error(),
-		})
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Audio file not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to calculate absolute path",
+				Reason:  err.Error(),
+			})
+		}
 		return
 	}
 
 	log.Debug().Msg("Audio file sent successfully")
-	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Type", utils.AudioContentType(absolutePath))
 	c.Header("Content-Disposition", "attachment; filename="+filepath.Base(absolutePath))
 	c.File(absolutePath)
 }
@@ -7,6 +7,7 @@ import (
 	"music-files/internal/errors"
 	"music-files/internal/handler/response"
 	"music-files/internal/model"
+	"music-files/internal/utils"
 	"net/http"
 	"strconv"
 	"time"
@@ -36,6 +37,42 @@ type getAudioFileResponse struct {
 	Sha256 string `json:"sha256"`
 	// Time of the last update to the audioFile's content
 	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// Identifier of the cover resolved for the audioFile, null if it has none
+	CoverId *int `json:"coverId"`
+	// Identifier of the cover pinned to the audioFile, null if none is pinned
+	CoverOverrideId *int `json:"coverOverrideId"`
+	// Title from the tags, null if the file has none
+	Title *string `json:"title"`
+	// Artist from the tags, null if the file has none
+	Artist *string `json:"artist"`
+	// Album from the tags, null if the file has none
+	Album *string `json:"album"`
+	// Track number on the disc, null if the tags don't have it
+	TrackNumber *int `json:"trackNumber"`
+	// Disc number, null if the tags don't have it
+	DiscNumber *int `json:"discNumber"`
+	// Release year, null if the tags don't have it
+	Year *int `json:"year"`
+	// Genre from the tags, null if the file has none
+	Genre *string `json:"genre"`
+	// Comment from the tags, null if the file has none
+	Comment *string `json:"comment"`
+	// Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known
+	Codec *string `json:"codec"`
+	// Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known
+	Container *string `json:"container"`
+	// Bits per sample of lossless streams, null for lossy ones
+	BitsPerSample *int `json:"bitsPerSample"`
+	// Whether the stream is lossless, null if it isn't known
+	Lossless *bool `json:"lossless"`
+	// CBR or VBR, null if it isn't known
+	BitrateMode *string `json:"bitrateMode"`
+	// Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known
+	Encoder *string `json:"encoder"`
+	// Number of samples per channel, null if it isn't known
+	SampleCount *int64 `json:"sampleCount"`
+	// All tag fields of the audioFile by their upper case names, such as ALBUMARTIST or MUSICBRAINZ_ALBUMID
+	Tags map[string][]string `json:"tags"`
 }
 
 // GetAudioFile retrieves a audioFile by its identifier
@@ -64,11 +101,16 @@ func (h *Handler) GetAudioFile(c *gin.Context) {
 	log.Debug().Int("audioFileId", audioFileId).Msg("Url parameter read successfully")
 
 	var audioFile model.AudioFile
+	var tags utils.Tags
 	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
 		audioFile, err = h.AudioFileService.GetAudioFile(tx, audioFileId)
 		if err != nil {
 			return err
 		}
+		tags, err = h.AudioFileService.GetTags(tx, audioFileId)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 	if err != nil {
@@ -100,5 +142,23 @@ func (h *Handler) GetAudioFile(c *gin.Context) {
 		ChannelsN:         audioFile.ChannelsN,
 		Sha256:            audioFile.Sha256,
 		LastContentUpdate: audioFile.LastContentUpdate,
+		CoverId:           audioFile.CoverId,
+		CoverOverrideId:   audioFile.CoverOverrideId,
+		Title:             audioFile.Title,
+		Artist:            audioFile.Artist,
+		Album:             audioFile.Album,
+		TrackNumber:       audioFile.TrackNumber,
+		DiscNumber:        audioFile.DiscNumber,
+		Year:              audioFile.Year,
+		Genre:             audioFile.Genre,
+		Comment:           audioFile.Comment,
+		Codec:             audioFile.Codec,
+		Container:         audioFile.Container,
+		BitsPerSample:     audioFile.BitsPerSample,
+		Lossless:          audioFile.Lossless,
+		BitrateMode:       audioFile.BitrateMode,
+		Encoder:           audioFile.Encoder,
+		SampleCount:       audioFile.SampleCount,
+		Tags:              tags,
 	})
 }
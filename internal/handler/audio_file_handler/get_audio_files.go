@@ -1,15 +1,22 @@
 package audio_file_handler
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
 	"music-files/internal/handler/response"
 	"music-files/internal/model"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Prefix of query parameters filtering audioFiles by tags
+const tagFilterPrefix = "tag."
+
 // getAudioFilesResponseItem represents each audioFile item in the getAudioFiles response
 type getAudioFilesResponseItem struct {
 	// Unique identifier for the audioFile
@@ -34,6 +41,38 @@ type getAudioFilesResponseItem struct {
 	Sha256 string `json:"sha256"`
 	// Time of the last update to the audioFile's content
 	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// Identifier of the cover resolved for the audioFile, null if it has none
+	CoverId *int `json:"coverId"`
+	// Title from the tags, null if the file has none
+	Title *string `json:"title"`
+	// Artist from the tags, null if the file has none
+	Artist *string `json:"artist"`
+	// Album from the tags, null if the file has none
+	Album *string `json:"album"`
+	// Track number on the disc, null if the tags don't have it
+	TrackNumber *int `json:"trackNumber"`
+	// Disc number, null if the tags don't have it
+	DiscNumber *int `json:"discNumber"`
+	// Release year, null if the tags don't have it
+	Year *int `json:"year"`
+	// Genre from the tags, null if the file has none
+	Genre *string `json:"genre"`
+	// Comment from the tags, null if the file has none
+	Comment *string `json:"comment"`
+	// Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known
+	Codec *string `json:"codec"`
+	// Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known
+	Container *string `json:"container"`
+	// Bits per sample of lossless streams, null for lossy ones
+	BitsPerSample *int `json:"bitsPerSample"`
+	// Whether the stream is lossless, null if it isn't known
+	Lossless *bool `json:"lossless"`
+	// CBR or VBR, null if it isn't known
+	BitrateMode *string `json:"bitrateMode"`
+	// Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known
+	Encoder *string `json:"encoder"`
+	// Number of samples per channel, null if it isn't known
+	SampleCount *int64 `json:"sampleCount"`
 }
 
 // getAudioFilesResponse is the response model for the GetAll API
@@ -44,19 +83,43 @@ type getAudioFilesResponse struct {
 
 // GetAll retrieves all audioFiles
 // @Summary Retrieve all audioFiles
-// @Description Retrieves a list of all audioFiles in the system
+// @Description Retrieves a list of all audioFiles in the system. Query parameters named tag.FIELD, e.g.
+// @Description tag.MUSICBRAINZ_ALBUMID or tag.GENRE, keep only audioFiles having the value among the values of the field.
+// @Description The other parameters filter by technical properties. All given filters must match
 // @Tags AudioFiles
 // @Accept  json
 // @Produce  json
+// @Param   tag.FIELD query string false "Value of the tag field FIELD"
+// @Param   codec query string false "Codec, e.g. flac, alac, aac or mp3"
+// @Param   container query string false "Container: mpeg, flac, ogg, mp4, wav or aiff"
+// @Param   bitsPerSample query int false "Bits per sample of lossless audioFiles"
+// @Param   lossless query bool false "Keep only lossless or only lossy audioFiles"
+// @Param   bitrateMode query string false "CBR or VBR"
 // @Success 200 {object} getAudioFilesResponse
+// @Failure 400 {object} response.Error "Invalid filter"
 // @Failure 500 {object} response.Error "Internal Server Error"
 // @Router /audio-files [get]
 func (h *Handler) GetAll(c *gin.Context) {
 	log.Debug().Msg("Getting audioFiles")
 
+	filter, err := readAudioFileFilter(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid filter")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid filter",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Interface("filter", filter).Msg("Query parameters read successfully")
+
 	var audioFiles []model.AudioFile
-	err := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
-		audioFiles, err = h.AudioFileService.GetAll(tx)
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		if !filter.IsEmpty() {
+			audioFiles, err = h.AudioFileService.GetAllByFilter(tx, filter)
+		} else {
+			audioFiles, err = h.AudioFileService.GetAll(tx)
+		}
 		if err != nil {
 			return err
 		}
@@ -64,10 +127,18 @@ func (h *Handler) GetAll(c *gin.Context) {
 	})
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get audioFiles")
-		c.JSON(http.StatusInternalServerError, response.Error{
-			Message: "Failed to get audioFiles",
-			Reason:  err.Error(),
-		})
+		switch err.(type) {
+		case errors.BadRequest:
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid filter",
+				Reason:  err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get audioFiles",
+				Reason:  err.Error(),
+			})
+		}
 		return
 	}
 
@@ -85,6 +156,22 @@ func (h *Handler) GetAll(c *gin.Context) {
 			ChannelsN:         audioFile.ChannelsN,
 			Sha256:            audioFile.Sha256,
 			LastContentUpdate: audioFile.LastContentUpdate,
+			CoverId:           audioFile.CoverId,
+			Title:             audioFile.Title,
+			Artist:            audioFile.Artist,
+			Album:             audioFile.Album,
+			TrackNumber:       audioFile.TrackNumber,
+			DiscNumber:        audioFile.DiscNumber,
+			Year:              audioFile.Year,
+			Genre:             audioFile.Genre,
+			Comment:           audioFile.Comment,
+			Codec:             audioFile.Codec,
+			Container:         audioFile.Container,
+			BitsPerSample:     audioFile.BitsPerSample,
+			Lossless:          audioFile.Lossless,
+			BitrateMode:       audioFile.BitrateMode,
+			Encoder:           audioFile.Encoder,
+			SampleCount:       audioFile.SampleCount,
 		}
 	}
 
@@ -93,3 +180,43 @@ func (h *Handler) GetAll(c *gin.Context) {
 		AudioFiles: audioFilesResponseItems,
 	})
 }
+
+// readAudioFileFilter reads tag.FIELD and technical property query parameters
+func readAudioFileFilter(c *gin.Context) (filter model.AudioFileFilter, err error) {
+	filter.Tags = make(map[string]string)
+	for name, values := range c.Request.URL.Query() {
+		key, found := strings.CutPrefix(name, tagFilterPrefix)
+		if !found {
+			continue
+		}
+		if key == "" {
+			return model.AudioFileFilter{}, fmt.Errorf("%s must be followed by the name of a tag field", tagFilterPrefix)
+		}
+		filter.Tags[key] = values[0]
+	}
+
+	if codec, ok := c.GetQuery("codec"); ok {
+		filter.Codec = &codec
+	}
+	if container, ok := c.GetQuery("container"); ok {
+		filter.Container = &container
+	}
+	if bitrateMode, ok := c.GetQuery("bitrateMode"); ok {
+		filter.BitrateMode = &bitrateMode
+	}
+	if bitsPerSampleStr, ok := c.GetQuery("bitsPerSample"); ok {
+		bitsPerSample, err := strconv.Atoi(bitsPerSampleStr)
+		if err != nil {
+			return model.AudioFileFilter{}, fmt.Errorf("invalid bitsPerSample format: %w", err)
+		}
+		filter.BitsPerSample = &bitsPerSample
+	}
+	if losslessStr, ok := c.GetQuery("lossless"); ok {
+		lossless, err := strconv.ParseBool(losslessStr)
+		if err != nil {
+			return model.AudioFileFilter{}, fmt.Errorf("invalid lossless format: %w", err)
+		}
+		filter.Lossless = &lossless
+	}
+	return filter, nil
+}
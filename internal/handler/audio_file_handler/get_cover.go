@@ -24,10 +24,22 @@ type getCoverResponse struct {
 	WidthPx int `json:"widthPx"`
 	// Height of the cover in pixels.
 	HeightPx int `json:"heightPx"`
+	// The most common color as "#rrggbb", null until the picture is analyzed by a scan.
+	DominantColor *string `json:"dominantColor"`
+	// The most common saturated color of medium lightness, null if the picture has none.
+	VibrantColor *string `json:"vibrantColor"`
+	// The most common unsaturated color of medium lightness, null if the picture has none.
+	MutedColor *string `json:"mutedColor"`
+	// Mean relative luminance from 0 (black) to 1 (white), null until the picture is analyzed by a scan.
+	AverageLuminance *float64 `json:"averageLuminance"`
 	// SHA-256 hash of the cover.
 	Sha256 string `json:"sha256"`
 	// Timestamp of the last content update.
 	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// What the cover shows: front, back, disc or booklet.
+	Kind string `json:"kind"`
+	// Where the cover comes from: file in a directory or embedded into audio files.
+	Source string `json:"source"`
 }
 
 // GetCover retrieves a cover for a specific audioFile.
@@ -88,7 +100,13 @@ func (h *Handler) GetCover(c *gin.Context) {
 		SizeByte:          cover.SizeByte,
 		WidthPx:           cover.WidthPx,
 		HeightPx:          cover.HeightPx,
+		DominantColor:     cover.DominantColor,
+		VibrantColor:      cover.VibrantColor,
+		MutedColor:        cover.MutedColor,
+		AverageLuminance:  cover.AverageLuminance,
 		Sha256:            cover.Sha256,
 		LastContentUpdate: cover.LastContentUpdate,
+		Kind:              cover.Kind,
+		Source:            cover.Source,
 	})
 }
@@ -0,0 +1,134 @@
+package audio_file_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"time"
+)
+
+// searchResponseItem represents a single audioFile item in the responses of searches.
+type searchResponseItem struct {
+	// Unique identifier for the audioFile.
+	AudioFileId int `json:"audioFileId"`
+	// Directory ID where the audioFile is located.
+	DirId int `json:"dirId"`
+	// Filename of the audioFile.
+	Filename string `json:"filename"`
+	// File extension of the audioFile.
+	Extension string `json:"extension"`
+	// File size of the audioFile in bytes.
+	SizeByte int64 `json:"sizeByte"`
+	// Duration of the audioFile in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	// Bitrate of the audioFile in Kbps.
+	BitrateKbps int `json:"bitrateKbps"`
+	// Sample rate of the audioFile in Hz.
+	SampleRateHz int `json:"sampleRateHz"`
+	// Number of channels in the audioFile.
+	ChannelsN int `json:"channelsN"`
+	// SHA-256 hash of the audioFile.
+	Sha256 string `json:"sha256"`
+	// Timestamp of the last content update.
+	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// Identifier of the cover resolved for the audioFile, null if it has none
+	CoverId *int `json:"coverId"`
+	// Title from the tags, null if the file has none
+	Title *string `json:"title"`
+	// Artist from the tags, null if the file has none
+	Artist *string `json:"artist"`
+	// Album from the tags, null if the file has none
+	Album *string `json:"album"`
+	// Track number on the disc, null if the tags don't have it
+	TrackNumber *int `json:"trackNumber"`
+	// Disc number, null if the tags don't have it
+	DiscNumber *int `json:"discNumber"`
+	// Release year, null if the tags don't have it
+	Year *int `json:"year"`
+	// Genre from the tags, null if the file has none
+	Genre *string `json:"genre"`
+	// Comment from the tags, null if the file has none
+	Comment *string `json:"comment"`
+	// Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known
+	Codec *string `json:"codec"`
+	// Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known
+	Container *string `json:"container"`
+	// Bits per sample of lossless streams, null for lossy ones
+	BitsPerSample *int `json:"bitsPerSample"`
+	// Whether the stream is lossless, null if it isn't known
+	Lossless *bool `json:"lossless"`
+	// CBR or VBR, null if it isn't known
+	BitrateMode *string `json:"bitrateMode"`
+	// Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known
+	Encoder *string `json:"encoder"`
+	// Number of samples per channel, null if it isn't known
+	SampleCount *int64 `json:"sampleCount"`
+}
+
+// searchResponse represents the response of searches by sha256, MusicBrainz id and ISRC.
+type searchResponse struct {
+	// Array of audioFiles that match the search query.
+	AudioFiles []searchResponseItem `json:"audioFiles"`
+}
+
+// newSearchResponseItem copies the audioFile into the response item
+func newSearchResponseItem(audioFile model.AudioFile) (item searchResponseItem) {
+	return searchResponseItem{
+		AudioFileId:       audioFile.AudioFileId,
+		DirId:             audioFile.DirId,
+		Filename:          audioFile.Filename,
+		Extension:         audioFile.Extension,
+		SizeByte:          audioFile.SizeByte,
+		DurationMs:        audioFile.DurationMs,
+		BitrateKbps:       audioFile.BitrateKbps,
+		SampleRateHz:      audioFile.SampleRateHz,
+		ChannelsN:         audioFile.ChannelsN,
+		Sha256:            audioFile.Sha256,
+		LastContentUpdate: audioFile.LastContentUpdate,
+		CoverId:           audioFile.CoverId,
+		Title:             audioFile.Title,
+		Artist:            audioFile.Artist,
+		Album:             audioFile.Album,
+		TrackNumber:       audioFile.TrackNumber,
+		DiscNumber:        audioFile.DiscNumber,
+		Year:              audioFile.Year,
+		Genre:             audioFile.Genre,
+		Comment:           audioFile.Comment,
+		Codec:             audioFile.Codec,
+		Container:         audioFile.Container,
+		BitsPerSample:     audioFile.BitsPerSample,
+		Lossless:          audioFile.Lossless,
+		BitrateMode:       audioFile.BitrateMode,
+		Encoder:           audioFile.Encoder,
+		SampleCount:       audioFile.SampleCount,
+	}
+}
+
+// search sends the audioFiles found by find
+func (h *Handler) search(c *gin.Context, find func(tx *sqlx.Tx) (audioFiles []model.AudioFile, err error)) {
+	var audioFiles []model.AudioFile
+	err := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		audioFiles, err = find(tx)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to search audioFiles")
+		c.JSON(http.StatusInternalServerError, response.Error{
+			Message: "Failed to get audioFiles",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	audioFilesResponseItems := make([]searchResponseItem, len(audioFiles))
+	for i, audioFile := range audioFiles {
+		audioFilesResponseItems[i] = newSearchResponseItem(audioFile)
+	}
+
+	c.JSON(http.StatusOK, searchResponse{
+		AudioFiles: audioFilesResponseItems,
+	})
+}
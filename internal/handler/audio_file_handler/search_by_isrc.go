@@ -0,0 +1,27 @@
+package audio_file_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// SearchByIsrc retrieves a list of audioFiles of a recording by its ISRC.
+// @Summary Search audioFiles by ISRC
+// @Description Retrieves a list of audioFiles that have the specified ISRC tag.
+// @Tags AudioFiles
+// @Accept  json
+// @Produce  json
+// @Param   isrc     path    string  true        "ISRC, with or without dashes"
+// @Success 200 {object} searchResponse
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /audio-files/isrc/{isrc} [get]
+func (h *Handler) SearchByIsrc(c *gin.Context) {
+	isrc := c.Param("isrc")
+	log.Debug().Str("isrc", isrc).Msg("Url parameter read successfully")
+
+	h.search(c, func(tx *sqlx.Tx) (audioFiles []model.AudioFile, err error) {
+		return h.AudioFileService.SearchByIsrc(tx, isrc)
+	})
+}
@@ -0,0 +1,27 @@
+package audio_file_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// SearchByMbid retrieves a list of audioFiles tagged with a MusicBrainz id.
+// @Summary Search audioFiles by MusicBrainz id
+// @Description Retrieves a list of audioFiles that have the id in any of their MUSICBRAINZ_*ID tags.
+// @Tags AudioFiles
+// @Accept  json
+// @Produce  json
+// @Param   mbid     path    string  true        "MusicBrainz id of a recording, release, release group, artist or work"
+// @Success 200 {object} searchResponse
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /audio-files/mbid/{mbid} [get]
+func (h *Handler) SearchByMbid(c *gin.Context) {
+	mbid := c.Param("mbid")
+	log.Debug().Str("mbid", mbid).Msg("Url parameter read successfully")
+
+	h.search(c, func(tx *sqlx.Tx) (audioFiles []model.AudioFile, err error) {
+		return h.AudioFileService.SearchByMbid(tx, mbid)
+	})
+}
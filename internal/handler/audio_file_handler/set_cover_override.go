@@ -0,0 +1,79 @@
+package audio_file_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// setCoverOverrideRequest is the request model for SetCoverOverride API
+type setCoverOverrideRequest struct {
+	// Identifier of any known cover, it doesn't have to be in the directory of the audio file
+	CoverId int `json:"coverId" binding:"required"`
+}
+
+// SetCoverOverride
+// @Summary Pin a cover to an audio file
+// @Description The pinned cover goes before the cover pinned to the directory, track pictures, embedded pictures and covers found on disk
+// @Tags AudioFiles
+// @Accept  json
+// @Produce  json
+// @Param   audioFileId path int true "Audio File ID"
+// @Param   request body setCoverOverrideRequest true "Cover to pin"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid audioFileId format, Failed to decode request"
+// @Failure 404 {object} response.Error "Audio file or cover not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /audio-files/{audioFileId}/cover-override [put]
+func (h *Handler) SetCoverOverride(c *gin.Context) {
+	log.Debug().Msg("Setting cover override of audio file")
+
+	audioFileIdStr := c.Param("audioFileId")
+	audioFileId, err := strconv.Atoi(audioFileIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("audioFileIdStr", audioFileIdStr).Msg("Invalid audioFileId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid audioFileId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("audioFileId", audioFileId).Msg("Url parameter read successfully")
+
+	var request setCoverOverrideRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Error().Err(err).Msg("Failed to encode request")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Failed to encode request",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("coverId", request.CoverId).Msg("Request encoded successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.SetAudioFileCoverOverride(tx, audioFileId, &request.CoverId)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to set cover override")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Audio file or cover not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to set cover override",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover override set successfully")
+	c.Status(http.StatusNoContent)
+}
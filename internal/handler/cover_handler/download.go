@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
 	"music-files/internal/handler/response"
 	"net/http"
 	"path/filepath"
@@ -21,6 +22,7 @@ import (
 // @Header 200 {string} Content-Type "application/octet-stream"
 // @Header 200 {string} Content-Disposition "attachment; filename=[name of the file]"
 // @Failure 400 {object} response.Error "Invalid coverId format"
+// @Failure 404 {object} response.Error "Cover not found, missing on disk or not allowed by the symlink policy"
 // @Failure 500 {object} response.Error "Internal Server Error, Failed to calculate absolute path"
 // @Router /covers/{coverId}/download [get]
 func (h *Handler) Download(c *gin.Context) {
@@ -48,10 +50,17 @@ func (h *Handler) Download(c *gin.Context) {
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to calculate absolute path")
-		c.JSON(http.StatusInternalServerError, response.Error{
-			Message: "Failed to calculate absolute path",
-			Reason:  err.Error(),
-		})
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Cover not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to calculate absolute path",
+				Reason:  err.Error(),
+			})
+		}
 		return
 	}
 
@@ -0,0 +1,80 @@
+package cover_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+)
+
+// coverGroupResponse is the model for each group in the GetDuplicates response
+type coverGroupResponse struct {
+	// The variant with the highest resolution, its distance is 0
+	Canonical similarCoverResponse `json:"canonical"`
+	// The other variants with their distance to the canonical one
+	Variants []similarCoverResponse `json:"variants"`
+}
+
+// GetDuplicates
+// @Summary Find groups of near-duplicate covers in the library
+// @Description Covers are grouped when their perceptual hashes are linked by a chain of covers each within maxDistance bits of the next.
+// @Description The variant with the highest resolution is the canonical one. Covers that have no duplicates are left out
+// @Tags Covers
+// @Produce  json
+// @Param   maxDistance query   int     false       "Largest number of differing bits from 0 to 64, COVERS_SIMILARITY_MAX_DISTANCE by default"
+// @Success 200 {array} coverGroupResponse
+// @Failure 400 {object} response.Error "Invalid maxDistance"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /covers/duplicates [get]
+func (h *Handler) GetDuplicates(c *gin.Context) {
+	log.Debug().Msg("Getting groups of duplicate covers")
+
+	maxDistance, err := readMaxDistance(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid maxDistance format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid maxDistance format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	var groups []model.CoverGroup
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		groups, err = h.CoverService.GetDuplicateGroups(tx, maxDistance)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get groups of duplicate covers")
+		if _, ok := err.(errors.BadRequest); ok {
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid maxDistance",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get groups of duplicate covers",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	result := make([]coverGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		groupResponse := coverGroupResponse{
+			Canonical: toSimilarCoverResponse(model.SimilarCover{Cover: group.Canonical}),
+			Variants:  make([]similarCoverResponse, 0, len(group.Variants)),
+		}
+		for _, variant := range group.Variants {
+			groupResponse.Variants = append(groupResponse.Variants, toSimilarCoverResponse(variant))
+		}
+		result = append(result, groupResponse)
+	}
+
+	log.Debug().Int("countOfGroups", len(result)).Msg("Groups of duplicate covers got successfully")
+	c.JSON(http.StatusOK, result)
+}
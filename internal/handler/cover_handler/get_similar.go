@@ -0,0 +1,142 @@
+package cover_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"strconv"
+)
+
+// similarCoverResponse is the model for each cover in the GetSimilar and GetDuplicates responses
+type similarCoverResponse struct {
+	// Unique identifier for the cover
+	CoverId int `json:"coverId"`
+	// Directory of the cover file, null for covers embedded into audio files
+	DirId *int `json:"dirId"`
+	// Name of the cover file
+	Filename string `json:"filename"`
+	// File size of the cover in bytes
+	SizeByte int64 `json:"sizeByte"`
+	// Width of the cover in pixels
+	WidthPx int `json:"widthPx"`
+	// Height of the cover in pixels
+	HeightPx int `json:"heightPx"`
+	// SHA-256 hash of the cover
+	Sha256 string `json:"sha256"`
+	// What the cover shows: front, back, disc or booklet
+	Kind string `json:"kind"`
+	// Where the cover comes from: file in a directory or embedded into audio files
+	Source string `json:"source"`
+	// Number of bits the perceptual hashes differ in, 0 for the same picture
+	Distance int `json:"distance"`
+}
+
+// GetSimilar
+// @Summary Find covers that look like a cover
+// @Description Compares perceptual hashes, so the same artwork at another resolution or compression is found. Nearest covers go first
+// @Tags Covers
+// @Produce  json
+// @Param   coverId     path    int     true        "Cover ID"
+// @Param   maxDistance query   int     false       "Largest number of differing bits from 0 to 64, COVERS_SIMILARITY_MAX_DISTANCE by default"
+// @Success 200 {array} similarCoverResponse
+// @Failure 400 {object} response.Error "Invalid coverId or maxDistance"
+// @Failure 404 {object} response.Error "Cover not found"
+// @Failure 409 {object} response.Error "The cover has no perceptual hash yet"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /covers/{coverId}/similar [get]
+func (h *Handler) GetSimilar(c *gin.Context) {
+	log.Debug().Msg("Getting similar covers")
+
+	coverIdStr := c.Param("coverId")
+	coverId, err := strconv.Atoi(coverIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("coverIdStr", coverIdStr).Msg("Invalid coverId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid coverId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	maxDistance, err := readMaxDistance(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid maxDistance format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid maxDistance format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("coverId", coverId).Msg("Url parameters read successfully")
+
+	var similarCovers []model.SimilarCover
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		similarCovers, err = h.CoverService.GetSimilar(tx, coverId, maxDistance)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get similar covers")
+		switch err.(type) {
+		case errors.NotFound:
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Cover not found",
+				Reason:  err.Error(),
+			})
+		case errors.BadRequest:
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid maxDistance",
+				Reason:  err.Error(),
+			})
+		case errors.Conflict:
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Cover has no perceptual hash",
+				Reason:  err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get similar covers",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	result := make([]similarCoverResponse, 0, len(similarCovers))
+	for _, similarCover := range similarCovers {
+		result = append(result, toSimilarCoverResponse(similarCover))
+	}
+
+	log.Debug().Int("countOfSimilarCovers", len(result)).Msg("Similar covers got successfully")
+	c.JSON(http.StatusOK, result)
+}
+
+// readMaxDistance reads the optional maxDistance parameter, nil if it is absent
+func readMaxDistance(c *gin.Context) (maxDistance *int, err error) {
+	maxDistanceStr := c.Query("maxDistance")
+	if maxDistanceStr == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(maxDistanceStr)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func toSimilarCoverResponse(similarCover model.SimilarCover) similarCoverResponse {
+	return similarCoverResponse{
+		CoverId:  similarCover.CoverId,
+		DirId:    similarCover.DirId,
+		Filename: similarCover.Filename,
+		SizeByte: similarCover.SizeByte,
+		WidthPx:  similarCover.WidthPx,
+		HeightPx: similarCover.HeightPx,
+		Sha256:   similarCover.Sha256,
+		Kind:     similarCover.Kind,
+		Source:   similarCover.Source,
+		Distance: similarCover.Distance,
+	}
+}
@@ -0,0 +1,100 @@
+package cover_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Image
+// @Summary Get a cover image, optionally resized or converted
+// @Description Without query parameters the original file is sent, AVIF pictures are only sent this way. Otherwise the image is turned upright according to its EXIF orientation,
+// @Description scaled down to the box (never up) and encoded in the requested format. Variants are cached on disk
+// @Tags Covers
+// @Produce  jpeg,png,gif,image/webp,image/bmp,image/tiff,image/avif
+// @Param   coverId     path    int     true        "Cover ID"
+// @Param   size        query   int     false       "Width and height of the box, can't be combined with w and h"
+// @Param   w           query   int     false       "Width of the box"
+// @Param   h           query   int     false       "Height of the box"
+// @Param   mode        query   string  false       "fit keeps the whole image inside the box, fill crops it to cover the box"  Enums(fit, fill)
+// @Param   format      query   string  false       "Format of the variant, jpeg by default"  Enums(jpeg, png, webp)
+// @Success 200 {file} byte "Cover image"
+// @Failure 400 {object} response.Error "Invalid coverId or variant parameters"
+// @Failure 404 {object} response.Error "Cover not found, missing on disk or not allowed by the symlink policy"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /covers/{coverId}/image [get]
+func (h *Handler) Image(c *gin.Context) {
+	log.Debug().Msg("Getting cover image")
+
+	coverIdStr := c.Param("coverId")
+	coverId, err := strconv.Atoi(coverIdStr)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid coverId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid coverId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	spec, isVariant, err := utils.ParseThumbnailQuery(c.Query("size"), c.Query("w"), c.Query("h"), c.Query("mode"), c.Query("format"))
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid variant parameters")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid variant parameters",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("coverId", coverId).Bool("isVariant", isVariant).Msg("Url parameters read successfully")
+
+	var cover model.Cover
+	var absolutePath string
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		cover, absolutePath, err = h.ThumbnailService.Locate(tx, coverId)
+		return err
+	})
+	var thumbnail *os.File
+	if err == nil && isVariant {
+		thumbnail, spec, err = h.ThumbnailService.GetThumbnail(cover, absolutePath, spec)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get cover image")
+		switch err.(type) {
+		case errors.NotFound:
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Cover not found",
+				Reason:  err.Error(),
+			})
+		case errors.BadRequest:
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid variant parameters",
+				Reason:  err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get cover image",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	if isVariant {
+		// The variant is served from the open file, so it is readable even if the cache evicts it meanwhile
+		defer thumbnail.Close()
+		log.Debug().Str("thumbnailPath", thumbnail.Name()).Msg("Cover image sent successfully")
+		c.Header("Content-Type", spec.ContentType())
+		response.File(c, thumbnail)
+		return
+	}
+	log.Debug().Str("absolutePath", absolutePath).Msg("Cover image sent successfully")
+	c.Header("Content-Type", utils.ImageContentType(absolutePath))
+	c.File(absolutePath)
+}
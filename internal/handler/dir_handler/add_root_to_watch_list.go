@@ -14,6 +14,8 @@ import (
 type addRootToWatchListRequest struct {
 	// Path to the directory on disk
 	Path string `json:"path" bind:"required"`
+	// How scans treat symlinks: ignore, within_root or anywhere. Default is ignore
+	SymlinkPolicy string `json:"symlinkPolicy"`
 }
 
 // addRootToWatchListResponse is the response model after successfully adding a tracked directory
@@ -22,6 +24,8 @@ type addRootToWatchListResponse struct {
 	DirId int `json:"dirId"`
 	// Name of the directory
 	Name string `json:"name"`
+	// How scans treat symlinks below the root
+	SymlinkPolicy string `json:"symlinkPolicy"`
 }
 
 // AddRootToWatchList
@@ -32,7 +36,7 @@ type addRootToWatchListResponse struct {
 // @Produce  json
 // @Param   request body addRootToWatchListRequest true "Directory Data"
 // @Success 201 {object} addRootToWatchListResponse
-// @Failure 400 {object} response.Error "Failed to decode request"
+// @Failure 400 {object} response.Error "Failed to decode request, Unknown symlink policy"
 // @Failure 404 {object} response.Error "Directory not found"
 // @Failure 409 {object} response.Error "Directory already tracked"
 // @Failure 500 {object} response.Error "Internal Server Error"
@@ -53,9 +57,14 @@ func (h *Handler) AddRootToWatchList(c *gin.Context) {
 
 	var createdDir model.Directory
 	err := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		symlinkPolicy := model.SymlinkPolicyIgnore
+		if request.SymlinkPolicy != "" {
+			symlinkPolicy = request.SymlinkPolicy
+		}
 		dirToCreate := model.Directory{
-			ParentDirId: nil,
-			Name:        request.Path,
+			ParentDirId:   nil,
+			Name:          request.Path,
+			SymlinkPolicy: &symlinkPolicy,
 		}
 		createdDir, err = h.DirService.AddRootToWatchList(tx, dirToCreate)
 		if err != nil {
@@ -70,6 +79,11 @@ func (h *Handler) AddRootToWatchList(c *gin.Context) {
 				Message: "Directory not found on disk",
 				Reason:  err.Error(),
 			})
+		} else if _, ok = err.(errors.BadRequest); ok {
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid symlink policy",
+				Reason:  err.Error(),
+			})
 		} else if _, ok = err.(errors.Conflict); ok {
 			c.JSON(http.StatusConflict, response.Error{
 				Message: "The directory is already being tracked",
@@ -84,9 +98,14 @@ func (h *Handler) AddRootToWatchList(c *gin.Context) {
 		return
 	}
 
+	if err = h.WatcherService.Refresh(); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh watched roots")
+	}
+
 	log.Debug().Msg("Directory added to watch list successfully")
 	c.JSON(http.StatusCreated, addRootToWatchListResponse{
-		DirId: createdDir.DirId,
-		Name:  createdDir.Name,
+		DirId:         createdDir.DirId,
+		Name:          createdDir.Name,
+		SymlinkPolicy: createdDir.SymlinkPolicyOrDefault(),
 	})
 }
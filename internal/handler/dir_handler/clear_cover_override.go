@@ -0,0 +1,60 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// ClearCoverOverride
+// @Summary Unpin the cover of a directory
+// @Description Audio files of the directory and below go back to covers found on disk
+// @Tags Directories
+// @Produce  json
+// @Param   dirId path int true "Dir ID"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid dirId format"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /dirs/{dirId}/cover-override [delete]
+func (h *Handler) ClearCoverOverride(c *gin.Context) {
+	log.Debug().Msg("Clearing cover override of directory")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.SetCoverOverride(tx, dirId, nil)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clear cover override")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to clear cover override",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Cover override cleared successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -18,6 +18,8 @@ type contentResponseDirItem struct {
 	DirId int `json:"dirId"`
 	// Name of the directory
 	Name string `json:"name"`
+	// Time since which the directory is unavailable on disk. Absent while it is online
+	OfflineSince *time.Time `json:"offlineSince,omitempty"`
 }
 
 // contentResponseDirItem represents a audioFile item in the content response
@@ -44,6 +46,38 @@ type contentResponseAudioFileItem struct {
 	Sha256 string `json:"sha256"`
 	// Time of the last update to the audioFile's content
 	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// Identifier of the cover resolved for the audioFile, null if it has none
+	CoverId *int `json:"coverId"`
+	// Title from the tags, null if the file has none
+	Title *string `json:"title"`
+	// Artist from the tags, null if the file has none
+	Artist *string `json:"artist"`
+	// Album from the tags, null if the file has none
+	Album *string `json:"album"`
+	// Track number on the disc, null if the tags don't have it
+	TrackNumber *int `json:"trackNumber"`
+	// Disc number, null if the tags don't have it
+	DiscNumber *int `json:"discNumber"`
+	// Release year, null if the tags don't have it
+	Year *int `json:"year"`
+	// Genre from the tags, null if the file has none
+	Genre *string `json:"genre"`
+	// Comment from the tags, null if the file has none
+	Comment *string `json:"comment"`
+	// Codec of the audio stream, e.g. mp3, aac, alac, flac, vorbis, opus or pcm, null if it isn't known
+	Codec *string `json:"codec"`
+	// Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff, null if it isn't known
+	Container *string `json:"container"`
+	// Bits per sample of lossless streams, null for lossy ones
+	BitsPerSample *int `json:"bitsPerSample"`
+	// Whether the stream is lossless, null if it isn't known
+	Lossless *bool `json:"lossless"`
+	// CBR or VBR, null if it isn't known
+	BitrateMode *string `json:"bitrateMode"`
+	// Encoder that wrote the stream, e.g. LAME3.100, null if it isn't known
+	Encoder *string `json:"encoder"`
+	// Number of samples per channel, null if it isn't known
+	SampleCount *int64 `json:"sampleCount"`
 }
 
 // contentResponse is the response model for the Content API
@@ -114,6 +148,7 @@ func (h *Handler) Content(c *gin.Context) {
 	for i := range subDirs {
 		subDirsResponse[i].DirId = subDirs[i].DirId
 		subDirsResponse[i].Name = subDirs[i].Name
+		subDirsResponse[i].OfflineSince = subDirs[i].OfflineSince
 	}
 
 	audioFilesResponse := make([]contentResponseAudioFileItem, len(audioFiles))
@@ -130,6 +165,22 @@ func (h *Handler) Content(c *gin.Context) {
 			ChannelsN:         audioFile.ChannelsN,
 			Sha256:            audioFile.Sha256,
 			LastContentUpdate: audioFile.LastContentUpdate,
+			CoverId:           audioFile.CoverId,
+			Title:             audioFile.Title,
+			Artist:            audioFile.Artist,
+			Album:             audioFile.Album,
+			TrackNumber:       audioFile.TrackNumber,
+			DiscNumber:        audioFile.DiscNumber,
+			Year:              audioFile.Year,
+			Genre:             audioFile.Genre,
+			Comment:           audioFile.Comment,
+			Codec:             audioFile.Codec,
+			Container:         audioFile.Container,
+			BitsPerSample:     audioFile.BitsPerSample,
+			Lossless:          audioFile.Lossless,
+			BitrateMode:       audioFile.BitrateMode,
+			Encoder:           audioFile.Encoder,
+			SampleCount:       audioFile.SampleCount,
 		}
 	}
 
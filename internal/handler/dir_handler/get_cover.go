@@ -0,0 +1,110 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/service/thumbnail_service"
+	"music-files/internal/utils"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// GetCover
+// @Summary Get the cover image of a directory
+// @Description A directory with its own cover (pinned or the best front cover of it and its artwork subfolders) is shown with it.
+// @Description A directory without one, like an artist or a compilation, gets a 3x3 or 2x2 mosaic of the most used covers of audio files below it,
+// @Description or the most used cover alone when there are fewer than 4 different ones. Parameters resize and convert the image as for /covers/{coverId}/image,
+// @Description a mosaic is always square cells filling the box, THUMBNAILS_MOSAIC_SIZE by default. Mosaics are cached on disk and rendered again once covers below change
+// @Tags Directories
+// @Produce  jpeg,png,gif,image/webp,image/bmp,image/tiff,image/avif
+// @Param   dirId       path    int     true        "Dir ID"
+// @Param   size        query   int     false       "Width and height of the box, can't be combined with w and h"
+// @Param   w           query   int     false       "Width of the box"
+// @Param   h           query   int     false       "Height of the box"
+// @Param   mode        query   string  false       "fit keeps the whole image inside the box, fill crops it to cover the box. Ignored for mosaics"  Enums(fit, fill)
+// @Param   format      query   string  false       "Format of the image, jpeg by default"  Enums(jpeg, png, webp)
+// @Success 200 {file} byte "Cover image"
+// @Failure 400 {object} response.Error "Invalid dirId or image parameters"
+// @Failure 404 {object} response.Error "Directory not found or there are no covers in it and below it"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /dirs/{dirId}/cover [get]
+func (h *Handler) GetCover(c *gin.Context) {
+	log.Debug().Msg("Getting cover image of directory")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	spec, isVariant, err := utils.ParseThumbnailQuery(c.Query("size"), c.Query("w"), c.Query("h"), c.Query("mode"), c.Query("format"))
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid image parameters")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid image parameters",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Bool("isVariant", isVariant).Msg("Url parameters read successfully")
+
+	var dirCover thumbnail_service.DirCover
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		dirCover, err = h.ThumbnailService.LocateDir(tx, dirId)
+		return err
+	})
+	var absolutePath string
+	var variant *os.File
+	if err == nil {
+		switch {
+		case dirCover.IsMosaic():
+			variant, spec, err = h.ThumbnailService.GetMosaic(dirCover, spec)
+			isVariant = true
+		case isVariant:
+			variant, spec, err = h.ThumbnailService.GetThumbnail(dirCover.Covers[0], dirCover.AbsolutePaths[0], spec)
+		default:
+			absolutePath = dirCover.AbsolutePaths[0]
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get cover image of directory")
+		switch err.(type) {
+		case errors.NotFound:
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Cover not found",
+				Reason:  err.Error(),
+			})
+		case errors.BadRequest:
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid image parameters",
+				Reason:  err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get cover image of directory",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	if isVariant {
+		// The variant is served from the open file, so it is readable even if the cache evicts it meanwhile
+		defer variant.Close()
+		log.Debug().Str("variantPath", variant.Name()).Msg("Cover image of directory sent successfully")
+		c.Header("Content-Type", spec.ContentType())
+		response.File(c, variant)
+		return
+	}
+	log.Debug().Str("absolutePath", absolutePath).Msg("Cover image of directory sent successfully")
+	c.Header("Content-Type", utils.ImageContentType(absolutePath))
+	c.File(absolutePath)
+}
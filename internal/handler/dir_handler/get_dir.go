@@ -22,6 +22,10 @@ type getDirResponse struct {
 	AbsolutePath string `json:"absolutePath"`
 	// Last time the directory was scanned
 	LastScanned *time.Time `json:"lastScanned,omitempty"`
+	// Time since which the directory is unavailable on disk. Absent while it is online
+	OfflineSince *time.Time `json:"offlineSince,omitempty"`
+	// Identifier of the cover pinned to the directory, null if none is pinned
+	CoverOverrideId *int `json:"coverOverrideId"`
 }
 
 // GetDir
@@ -82,9 +86,11 @@ func (h *Handler) GetDir(c *gin.Context) {
 
 	log.Debug().Msg("Directory content got successfully")
 	c.JSON(http.StatusOK, getDirResponse{
-		DirId:        dir.DirId,
-		Name:         dir.Name,
-		AbsolutePath: absolutePath,
-		LastScanned:  dir.LastScanned,
+		DirId:           dir.DirId,
+		Name:            dir.Name,
+		AbsolutePath:    absolutePath,
+		LastScanned:     dir.LastScanned,
+		OfflineSince:    dir.OfflineSince,
+		CoverOverrideId: dir.CoverOverrideId,
 	})
 }
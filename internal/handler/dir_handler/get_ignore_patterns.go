@@ -0,0 +1,76 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// getIgnorePatternsResponse is the response model for GetIgnorePatterns API
+type getIgnorePatternsResponse struct {
+	// Gitignore-style patterns in the order they apply
+	Patterns []string `json:"patterns"`
+}
+
+// GetIgnorePatterns
+// @Summary Retrieve ignore patterns of a root
+// @Description Retrieves gitignore-style patterns excluding paths of the root from scans. Patterns of .wakarimiignore files are not included
+// @Tags Directories
+// @Accept  json
+// @Produce  json
+// @Param   dirId path int true "Root Directory ID"
+// @Success 200 {object} getIgnorePatternsResponse
+// @Failure 400 {object} response.Error "Invalid dirId format, The directory is not root"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /roots/{dirId}/ignore [get]
+func (h *Handler) GetIgnorePatterns(c *gin.Context) {
+	log.Debug().Msg("Getting ignore patterns of root")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	var patterns []string
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		patterns, err = h.IgnoreService.GetPatterns(tx, dirId)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get ignore patterns")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.BadRequest); ok {
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "The directory is not root",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get ignore patterns",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Ignore patterns got successfully")
+	c.JSON(http.StatusOK, getIgnorePatternsResponse{
+		Patterns: patterns,
+	})
+}
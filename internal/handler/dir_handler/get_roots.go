@@ -18,6 +18,10 @@ type getRootsResponseItem struct {
 	Name string `json:"name"`
 	// Last time the directory was scanned
 	LastScanned *time.Time `json:"lastScanned,omitempty"`
+	// Time since which the directory is unavailable on disk. Absent while it is online
+	OfflineSince *time.Time `json:"offlineSince,omitempty"`
+	// How scans treat symlinks below the root: ignore, within_root or anywhere
+	SymlinkPolicy string `json:"symlinkPolicy"`
 }
 
 // getRootsResponse is the response model for GetRoots API
@@ -58,9 +62,11 @@ func (h *Handler) GetRoots(c *gin.Context) {
 	responseRootItems := make([]getRootsResponseItem, len(roots))
 	for i, root := range roots {
 		responseRootItems[i] = getRootsResponseItem{
-			DirId:       root.DirId,
-			Name:        root.Name,
-			LastScanned: root.LastScanned,
+			DirId:         root.DirId,
+			Name:          root.Name,
+			LastScanned:   root.LastScanned,
+			OfflineSince:  root.OfflineSince,
+			SymlinkPolicy: root.SymlinkPolicyOrDefault(),
 		}
 	}
 
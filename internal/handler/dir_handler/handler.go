@@ -3,18 +3,34 @@ package dir_handler
 import (
 	"music-files/internal/service"
 	"music-files/internal/service/dir_service"
+	"music-files/internal/service/ignore_service"
+	"music-files/internal/service/scan_job_service"
+	"music-files/internal/service/thumbnail_service"
+	"music-files/internal/service/watcher_service"
 )
 
 type Handler struct {
 	DirService         dir_service.Service
+	IgnoreService      ignore_service.Service
+	ScanJobService     scan_job_service.Service
+	ThumbnailService   thumbnail_service.Service
+	WatcherService     watcher_service.Service
 	TransactionManager service.TransactionManager
 }
 
 func NewHandler(dirService dir_service.Service,
+	ignoreService ignore_service.Service,
+	scanJobService scan_job_service.Service,
+	thumbnailService thumbnail_service.Service,
+	watcherService watcher_service.Service,
 	transactionManager service.TransactionManager) (h *Handler) {
 
 	h = &Handler{
 		DirService:         dirService,
+		IgnoreService:      ignoreService,
+		ScanJobService:     scanJobService,
+		ThumbnailService:   thumbnailService,
+		WatcherService:     watcherService,
 		TransactionManager: transactionManager,
 	}
 
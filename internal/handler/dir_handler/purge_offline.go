@@ -0,0 +1,67 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// PurgeOffline
+// @Summary Purge content of an offline directory
+// @Description Deletes subdirectories, audio files and covers stored below an offline directory without waiting for the grace period. The directory itself is kept
+// @Tags Directories
+// @Accept  json
+// @Produce  json
+// @Param   dirId path int true "Directory ID"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid dirId format"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 409 {object} response.Error "Directory is not offline"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /dirs/{dirId}/purge [post]
+func (h *Handler) PurgeOffline(c *gin.Context) {
+	log.Debug().Msg("Purging offline directory")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.PurgeOffline(tx, dirId)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to purge offline directory")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.Conflict); ok {
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Directory is not offline",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to purge offline directory",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Offline directory purged")
+	c.Status(http.StatusNoContent)
+}
@@ -65,6 +65,10 @@ func (h *Handler) RemoveRootFromWatchList(c *gin.Context) {
 		return
 	}
 
+	if err = h.WatcherService.Refresh(); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh watched roots")
+	}
+
 	log.Debug().Msg("Directory removed from tracked")
 	c.Status(http.StatusNoContent)
 }
@@ -2,24 +2,35 @@ package dir_handler
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
 	"music-files/internal/errors"
 	"music-files/internal/handler/response"
+	"music-files/internal/model"
 	"net/http"
 	"strconv"
 )
 
+// scanResponse is the response model for Scan API
+type scanResponse struct {
+	// Identifier of the submitted scan job
+	ScanJobId int `json:"scanJobId"`
+	// State of the submitted scan job
+	Status string `json:"status"`
+}
+
 // Scan scans a directory for new or updated files.
 // @Summary Scan a directory by ID
-// @Description Initiates a scan in the specified directory to identify new or updated files.
+// @Description Submits a scan job for the specified directory to identify new or updated files. Progress is available via /jobs/{jobId}
 // @Tags Directories
 // @Accept  json
 // @Produce  json
 // @Param   dirId     path    int     true        "Directory ID"
-// @Success 200 "Directory scanned successfully"
-// @Failure 400 {object} response.Error "Invalid dirId format"
+// @Param   deepVerify query   bool    false       "Hash every file even if its size and modification time did not change"
+// @Param   dryRun     query   bool    false       "Only compute the report of changes without writing them"
+// @Success 202 {object} scanResponse
+// @Failure 400 {object} response.Error "Invalid dirId, deepVerify or dryRun format"
 // @Failure 404 {object} response.Error "Directory not found"
+// @Failure 409 {object} response.Error "Scan queue is full"
 // @Failure 500 {object} response.Error "Internal Server Error"
 // @Router /dirs/{dirId}/scan [post]
 func (h *Handler) Scan(c *gin.Context) {
@@ -37,29 +48,58 @@ func (h *Handler) Scan(c *gin.Context) {
 	}
 	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
 
-	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
-		err = h.DirService.Scan(tx, dirId)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	deepVerifyStr := c.DefaultQuery("deepVerify", "false")
+	deepVerify, err := strconv.ParseBool(deepVerifyStr)
+	if err != nil {
+		log.Error().Err(err).Str("deepVerifyStr", deepVerifyStr).Msg("Invalid deepVerify format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid deepVerify format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	dryRunStr := c.DefaultQuery("dryRun", "false")
+	dryRun, err := strconv.ParseBool(dryRunStr)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to get scan directory")
+		log.Error().Err(err).Str("dryRunStr", dryRunStr).Msg("Invalid dryRun format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dryRun format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	scanJob, err := h.ScanJobService.Submit(model.ScanJob{
+		TriggeredBy: model.ScanJobTriggeredByApi,
+		Recursive:   true,
+		DeepVerify:  deepVerify,
+		DryRun:      dryRun,
+	}, []int{dirId})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to submit directory scan")
 		if _, ok := err.(errors.NotFound); ok {
 			c.JSON(http.StatusNotFound, response.Error{
 				Message: "Directory not found",
 				Reason:  err.Error(),
 			})
+		} else if _, ok = err.(errors.Conflict); ok {
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Scan queue is full",
+				Reason:  err.Error(),
+			})
 		} else {
 			c.JSON(http.StatusInternalServerError, response.Error{
-				Message: "Failed to get scan directory",
+				Message: "Failed to submit directory scan",
 				Reason:  err.Error(),
 			})
 		}
 		return
 	}
 
-	log.Debug().Msg("Directory scanned successfully")
-	c.Status(http.StatusOK)
+	log.Debug().Int("scanJobId", scanJob.ScanJobId).Msg("Directory scan submitted successfully")
+	c.JSON(http.StatusAccepted, scanResponse{
+		ScanJobId: scanJob.ScanJobId,
+		Status:    scanJob.Status,
+	})
 }
@@ -2,40 +2,85 @@ package dir_handler
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
 	"music-files/internal/handler/response"
+	"music-files/internal/model"
 	"net/http"
+	"strconv"
 )
 
+// scanAllResponse is the response model for ScanAll API
+type scanAllResponse struct {
+	// Identifier of the submitted scan job
+	ScanJobId int `json:"scanJobId"`
+	// State of the submitted scan job
+	Status string `json:"status"`
+}
+
 // ScanAll scans all directories for new or updated files.
 // @Summary Scan all directories
-// @Description Initiates a scan in all directories to identify new or updated files.
+// @Description Submits a scan job for all root directories to identify new or updated files. Progress is available via /jobs/{jobId}
 // @Tags Directories
 // @Accept  json
 // @Produce  json
-// @Success 200 "All directories scanned successfully"
+// @Param   deepVerify query   bool    false       "Hash every file even if its size and modification time did not change"
+// @Param   dryRun     query   bool    false       "Only compute the report of changes without writing them"
+// @Success 202 {object} scanAllResponse
+// @Failure 400 {object} response.Error "Invalid deepVerify or dryRun format"
+// @Failure 409 {object} response.Error "Scan queue is full"
 // @Failure 500 {object} response.Error "Internal Server Error"
 // @Router /dirs/scan [post]
 func (h *Handler) ScanAll(c *gin.Context) {
 	log.Debug().Msg("Scanning directory")
 
-	err := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
-		err = h.DirService.ScanAll(tx)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	deepVerifyStr := c.DefaultQuery("deepVerify", "false")
+	deepVerify, err := strconv.ParseBool(deepVerifyStr)
+	if err != nil {
+		log.Error().Err(err).Str("deepVerifyStr", deepVerifyStr).Msg("Invalid deepVerify format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid deepVerify format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	dryRunStr := c.DefaultQuery("dryRun", "false")
+	dryRun, err := strconv.ParseBool(dryRunStr)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to scan directories")
-		c.JSON(http.StatusInternalServerError, response.Error{
-			Message: "Failed to scan directories",
+		log.Error().Err(err).Str("dryRunStr", dryRunStr).Msg("Invalid dryRun format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dryRun format",
 			Reason:  err.Error(),
 		})
 		return
 	}
 
-	log.Debug().Msg("Directories scanned successfully")
-	c.Status(http.StatusOK)
+	scanJob, err := h.ScanJobService.Submit(model.ScanJob{
+		TriggeredBy: model.ScanJobTriggeredByApi,
+		Recursive:   true,
+		DeepVerify:  deepVerify,
+		DryRun:      dryRun,
+	}, nil)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to submit scan of all directories")
+		if _, ok := err.(errors.Conflict); ok {
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Scan queue is full",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to scan directories",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("scanJobId", scanJob.ScanJobId).Msg("Scan of all directories submitted successfully")
+	c.JSON(http.StatusAccepted, scanAllResponse{
+		ScanJobId: scanJob.ScanJobId,
+		Status:    scanJob.Status,
+	})
 }
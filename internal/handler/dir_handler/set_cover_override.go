@@ -0,0 +1,79 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// setCoverOverrideRequest is the request model for SetCoverOverride API
+type setCoverOverrideRequest struct {
+	// Identifier of any known cover, it doesn't have to be in this directory
+	CoverId int `json:"coverId" binding:"required"`
+}
+
+// SetCoverOverride
+// @Summary Pin a cover to a directory
+// @Description The pinned cover replaces the best front cover of the directory for its audio files and for the directories below that have no covers of their own. Covers pinned to audio files still go first
+// @Tags Directories
+// @Accept  json
+// @Produce  json
+// @Param   dirId path int true "Dir ID"
+// @Param   request body setCoverOverrideRequest true "Cover to pin"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid dirId format, Failed to decode request"
+// @Failure 404 {object} response.Error "Directory or cover not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /dirs/{dirId}/cover-override [put]
+func (h *Handler) SetCoverOverride(c *gin.Context) {
+	log.Debug().Msg("Setting cover override of directory")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	var request setCoverOverrideRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Error().Err(err).Msg("Failed to encode request")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Failed to encode request",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("coverId", request.CoverId).Msg("Request encoded successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.SetCoverOverride(tx, dirId, &request.CoverId)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to set cover override")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory or cover not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to set cover override",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Cover override set successfully")
+	c.Status(http.StatusNoContent)
+}
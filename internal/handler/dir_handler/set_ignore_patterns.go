@@ -0,0 +1,89 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// setIgnorePatternsRequest is the request model for SetIgnorePatterns API
+type setIgnorePatternsRequest struct {
+	// Gitignore-style patterns relative to the root, e.g. "@eaDir/", ".*" or "Samples/". Later patterns win, "!" negates
+	Patterns []string `json:"patterns"`
+}
+
+// SetIgnorePatterns
+// @Summary Replace ignore patterns of a root
+// @Description Replaces gitignore-style patterns excluding paths of the root from scans. They take effect on the next scan, stored entities that become ignored are deleted by it
+// @Tags Directories
+// @Accept  json
+// @Produce  json
+// @Param   dirId path int true "Root Directory ID"
+// @Param   request body setIgnorePatternsRequest true "Ignore patterns"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid dirId format, Failed to decode request, Invalid pattern, The directory is not root"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /roots/{dirId}/ignore [put]
+func (h *Handler) SetIgnorePatterns(c *gin.Context) {
+	log.Debug().Msg("Setting ignore patterns of root")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	var request setIgnorePatternsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Error().Err(err).Msg("Failed to encode request")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Failed to encode request",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("countOfPatterns", len(request.Patterns)).Msg("Request encoded successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.IgnoreService.SetPatterns(tx, dirId, request.Patterns)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to set ignore patterns")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.BadRequest); ok {
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid ignore patterns",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to set ignore patterns",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	// Watched directories follow the new filter right away, scans only on their next run
+	if err = h.WatcherService.Refresh(); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh watched roots")
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Ignore patterns set successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package dir_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// setSymlinkPolicyRequest is the request model for SetSymlinkPolicy API
+type setSymlinkPolicyRequest struct {
+	// ignore skips symlinks, within_root follows those leading inside the root, anywhere follows all of them
+	SymlinkPolicy string `json:"symlinkPolicy" binding:"required"`
+}
+
+// SetSymlinkPolicy
+// @Summary Change symlink policy of a root
+// @Description Changes how scans of the root treat symlinks to directories, symlinks to files are scanned under every policy. It takes effect on the next scan, stored entities reached through links that are no longer followed are deleted by it. Downloads resolve files by the same policy
+// @Tags Directories
+// @Accept  json
+// @Produce  json
+// @Param   dirId path int true "Root Directory ID"
+// @Param   request body setSymlinkPolicyRequest true "Symlink policy"
+// @Success 204
+// @Failure 400 {object} response.Error "Invalid dirId format, Failed to decode request, Unknown symlink policy, The directory is not root"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /roots/{dirId}/symlink-policy [put]
+func (h *Handler) SetSymlinkPolicy(c *gin.Context) {
+	log.Debug().Msg("Setting symlink policy of root")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("dirId", dirId).Msg("Url parameter read successfully")
+
+	var request setSymlinkPolicyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Error().Err(err).Msg("Failed to encode request")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Failed to encode request",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Str("symlinkPolicy", request.SymlinkPolicy).Msg("Request encoded successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return h.DirService.SetSymlinkPolicy(tx, dirId, request.SymlinkPolicy)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to set symlink policy")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.BadRequest); ok {
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid symlink policy",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to set symlink policy",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	// Watched directories follow the new filter right away, scans only on their next run
+	if err = h.WatcherService.Refresh(); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh watched roots")
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Symlink policy set successfully")
+	c.Status(http.StatusNoContent)
+}
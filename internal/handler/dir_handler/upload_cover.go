@@ -0,0 +1,214 @@
+package dir_handler
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"io"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Room left in the request body for the multipart framing and the other form fields
+const formOverheadBytes = 64 * 1024
+
+// errFileTooLarge is returned by readFormFile when the uploaded file exceeds the limit
+var errFileTooLarge = stderrors.New("uploaded file is too large")
+
+// uploadCoverResponse is the response model for UploadCover API
+type uploadCoverResponse struct {
+	// Unique identifier for the cover
+	CoverId int `json:"coverId"`
+	// Name of the file written into the directory
+	Filename string `json:"filename"`
+	// File size of the cover in bytes
+	SizeByte int64 `json:"sizeByte"`
+	// Width of the cover in pixels
+	WidthPx int `json:"widthPx"`
+	// Height of the cover in pixels
+	HeightPx int `json:"heightPx"`
+	// The most common color as "#rrggbb", null if the picture can't be decoded
+	DominantColor *string `json:"dominantColor"`
+	// The most common saturated color of medium lightness, null if the picture has none
+	VibrantColor *string `json:"vibrantColor"`
+	// The most common unsaturated color of medium lightness, null if the picture has none
+	MutedColor *string `json:"mutedColor"`
+	// Mean relative luminance from 0 (black) to 1 (white), null if the picture can't be decoded
+	AverageLuminance *float64 `json:"averageLuminance"`
+	// SHA-256 hash of the cover
+	Sha256 string `json:"sha256"`
+	// Timestamp of the last content update
+	LastContentUpdate time.Time `json:"lastContentUpdate"`
+	// What the cover shows, assigned by the cover rules
+	Kind string `json:"kind"`
+}
+
+// UploadCover
+// @Summary Upload a cover into a directory
+// @Description Writes the image into the directory as cover.jpg (the name is set by COVERS_UPLOAD_NAME, the extension follows the format) and registers it.
+// @Description An existing file with that name is kept and 409 is returned unless overwrite=true. The file is moved in place once the cover is stored.
+// @Description With pin=true the cover is also pinned to the directory. The image can't be larger than COVERS_UPLOAD_MAX_BYTES
+// @Tags Directories
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   dirId path     int    true  "Dir ID"
+// @Param   file  formData file   true  "JPEG, PNG, GIF, WebP, BMP, TIFF or AVIF image"
+// @Param   overwrite query bool   false "Replace an existing file with the name of the upload"
+// @Param   pin   query    bool   false "Pin the uploaded cover to the directory"
+// @Success 201 {object} uploadCoverResponse
+// @Failure 400 {object} response.Error "Invalid dirId format, Missing file, Not a supported image, Not a cover by the cover rules"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 409 {object} response.Error "A file with the name of the upload exists"
+// @Failure 413 {object} response.Error "Uploaded file is too large"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router  /dirs/{dirId}/covers [post]
+func (h *Handler) UploadCover(c *gin.Context) {
+	log.Debug().Msg("Uploading cover into directory")
+
+	dirIdStr := c.Param("dirId")
+	dirId, err := strconv.Atoi(dirIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("dirIdStr", dirIdStr).Msg("Invalid dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid dirId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	overwrite := false
+	if overwriteStr := c.Query("overwrite"); overwriteStr != "" {
+		overwrite, err = strconv.ParseBool(overwriteStr)
+		if err != nil {
+			log.Error().Err(err).Str("overwriteStr", overwriteStr).Msg("Invalid overwrite format")
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid overwrite format",
+				Reason:  err.Error(),
+			})
+			return
+		}
+	}
+	pin := false
+	if pinStr := c.Query("pin"); pinStr != "" {
+		pin, err = strconv.ParseBool(pinStr)
+		if err != nil {
+			log.Error().Err(err).Str("pinStr", pinStr).Msg("Invalid pin format")
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid pin format",
+				Reason:  err.Error(),
+			})
+			return
+		}
+	}
+	log.Debug().Int("dirId", dirId).Bool("overwrite", overwrite).Bool("pin", pin).Msg("Url parameters read successfully")
+
+	data, err := readFormFile(c, "file", h.DirService.CoverService.UploadMaxBytes())
+	if stderrors.Is(err, errFileTooLarge) {
+		log.Error().Err(err).Msg("Uploaded file is too large")
+		c.JSON(http.StatusRequestEntityTooLarge, response.Error{
+			Message: "Uploaded file is too large",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read uploaded file")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Failed to read uploaded file",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	var cover model.Cover
+	var upload dir_service.CoverUpload
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		cover, upload, err = h.DirService.UploadCover(tx, dirId, data, overwrite, pin)
+		return err
+	})
+	if err == nil {
+		err = h.DirService.FinishUpload(upload)
+		if err != nil {
+			// The cover and the pin are committed already, they must not describe a file that isn't there
+			revertErr := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+				return h.DirService.RevertUpload(tx, upload)
+			})
+			if revertErr != nil {
+				log.Error().Err(revertErr).Msg("Failed to revert cover upload")
+			}
+		}
+	} else {
+		h.DirService.DiscardUpload(upload)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to upload cover")
+		switch err.(type) {
+		case errors.NotFound:
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		case errors.BadRequest:
+			c.JSON(http.StatusBadRequest, response.Error{
+				Message: "Invalid cover",
+				Reason:  err.Error(),
+			})
+		case errors.Conflict:
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Cover file already exists",
+				Reason:  err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to upload cover",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("coverId", cover.CoverId).Msg("Cover uploaded successfully")
+	c.JSON(http.StatusCreated, uploadCoverResponse{
+		CoverId:           cover.CoverId,
+		Filename:          cover.Filename,
+		SizeByte:          cover.SizeByte,
+		WidthPx:           cover.WidthPx,
+		HeightPx:          cover.HeightPx,
+		DominantColor:     cover.DominantColor,
+		VibrantColor:      cover.VibrantColor,
+		MutedColor:        cover.MutedColor,
+		AverageLuminance:  cover.AverageLuminance,
+		Sha256:            cover.Sha256,
+		LastContentUpdate: cover.LastContentUpdate,
+		Kind:              cover.Kind,
+	})
+}
+
+// readFormFile reads the file of the form field, failing with errFileTooLarge when it is larger than maxBytes
+func readFormFile(c *gin.Context, field string, maxBytes int64) (data []byte, err error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+formOverheadBytes)
+	fileHeader, err := c.FormFile(field)
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) || err == nil && fileHeader.Size > maxBytes {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", errFileTooLarge, maxBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err = io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err == nil && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", errFileTooLarge, maxBytes)
+	}
+	return data, err
+}
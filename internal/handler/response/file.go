@@ -0,0 +1,17 @@
+package response
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"time"
+)
+
+// File sends an open file, handling range and conditional requests like gin's File does for paths
+func File(c *gin.Context, file *os.File) {
+	var modTime time.Time
+	if fileInfo, err := file.Stat(); err == nil {
+		modTime = fileInfo.ModTime()
+	}
+	http.ServeContent(c.Writer, c.Request, file.Name(), modTime, file)
+}
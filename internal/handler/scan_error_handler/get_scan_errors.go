@@ -0,0 +1,142 @@
+package scan_error_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// getScanErrorsResponseItem represents a file or directory that could not be scanned
+type getScanErrorsResponseItem struct {
+	// Unique identifier of the scan error
+	ScanErrorId int `json:"scanErrorId"`
+	// Directory containing the problem file
+	DirId int `json:"dirId"`
+	// Name of the problem file, empty when the directory itself could not be scanned
+	Filename string `json:"filename"`
+	// Absolute path to the problem file or directory
+	Path string `json:"path"`
+	// Step that failed: directory, detect, stat, hash, tags or cover
+	Stage string `json:"stage"`
+	// Description of the failure
+	Reason string `json:"reason"`
+	// Time of the last failed attempt
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// getScanErrorsResponse is the response model for GetScanErrors API
+type getScanErrorsResponse struct {
+	// Array containing scan errors
+	ScanErrors []getScanErrorsResponseItem `json:"scanErrors"`
+}
+
+// GetScanErrors
+// @Summary Retrieve scan errors
+// @Description Retrieves files and directories skipped by scans. An error disappears once a later scan of the file succeeds
+// @Tags ScanErrors
+// @Accept  json
+// @Produce  json
+// @Param   rootId    query   int     false       "Only errors in this directory and below it"
+// @Param   dirId     query   int     false       "Only errors directly in this directory"
+// @Success 200 {object} getScanErrorsResponse
+// @Failure 400 {object} response.Error "Invalid rootId or dirId"
+// @Failure 404 {object} response.Error "Directory not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /scan-errors [get]
+func (h *Handler) GetScanErrors(c *gin.Context) {
+	log.Debug().Msg("Getting scan errors")
+
+	rootIdStr := c.Query("rootId")
+	dirIdStr := c.Query("dirId")
+	if rootIdStr != "" && dirIdStr != "" {
+		log.Error().Str("rootIdStr", rootIdStr).Str("dirIdStr", dirIdStr).Msg("Both rootId and dirId specified")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid rootId or dirId",
+			Reason:  "only one of rootId and dirId can be specified",
+		})
+		return
+	}
+	var rootId, dirId int
+	var err error
+	if rootIdStr != "" {
+		rootId, err = strconv.Atoi(rootIdStr)
+	} else if dirIdStr != "" {
+		dirId, err = strconv.Atoi(dirIdStr)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("rootIdStr", rootIdStr).Str("dirIdStr", dirIdStr).Msg("Invalid rootId or dirId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid rootId or dirId",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Str("rootId", rootIdStr).Str("dirId", dirIdStr).Msg("Query parameters read successfully")
+
+	var scanErrors []model.ScanError
+	dirPaths := make(map[int]string)
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		switch {
+		case rootIdStr != "":
+			scanErrors, err = h.ScanErrorService.GetAllByRoot(tx, rootId)
+		case dirIdStr != "":
+			scanErrors, err = h.ScanErrorService.GetAllByDir(tx, dirId)
+		default:
+			scanErrors, err = h.ScanErrorService.GetAll(tx)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, scanError := range scanErrors {
+			if _, ok := dirPaths[scanError.DirId]; ok {
+				continue
+			}
+			dirPaths[scanError.DirId], err = h.DirService.AbsolutePath(tx, scanError.DirId)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get scan errors")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Directory not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get scan errors",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	scanErrorsResponseItems := make([]getScanErrorsResponseItem, len(scanErrors))
+	for i, scanError := range scanErrors {
+		scanErrorsResponseItems[i] = getScanErrorsResponseItem{
+			ScanErrorId: scanError.ScanErrorId,
+			DirId:       scanError.DirId,
+			Filename:    scanError.Filename,
+			Path:        filepath.Join(dirPaths[scanError.DirId], scanError.Filename),
+			Stage:       scanError.Stage,
+			Reason:      scanError.Reason,
+			OccurredAt:  scanError.OccurredAt,
+		}
+	}
+
+	log.Debug().Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors got successfully")
+	c.JSON(http.StatusOK, getScanErrorsResponse{
+		ScanErrors: scanErrorsResponseItems,
+	})
+}
@@ -0,0 +1,26 @@
+package scan_error_handler
+
+import (
+	"music-files/internal/service"
+	"music-files/internal/service/dir_service"
+	"music-files/internal/service/scan_error_service"
+)
+
+type Handler struct {
+	ScanErrorService   scan_error_service.Service
+	DirService         dir_service.Service
+	TransactionManager service.TransactionManager
+}
+
+func NewHandler(scanErrorService scan_error_service.Service,
+	dirService dir_service.Service,
+	transactionManager service.TransactionManager) (h *Handler) {
+
+	h = &Handler{
+		ScanErrorService:   scanErrorService,
+		DirService:         dirService,
+		TransactionManager: transactionManager,
+	}
+
+	return h
+}
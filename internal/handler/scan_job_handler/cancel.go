@@ -0,0 +1,71 @@
+package scan_job_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// Cancel
+// @Summary Cancel a scan job
+// @Description Cancels a queued or running scan job. A running job stops at the next file or directory
+// @Tags Jobs
+// @Accept  json
+// @Produce  json
+// @Param   jobId     path    int     true        "Scan Job ID"
+// @Success 202
+// @Failure 400 {object} response.Error "Invalid jobId format"
+// @Failure 404 {object} response.Error "Scan job not found"
+// @Failure 409 {object} response.Error "Scan job already finished"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /jobs/{jobId} [delete]
+func (h *Handler) Cancel(c *gin.Context) {
+	log.Debug().Msg("Cancelling scan job")
+
+	jobIdStr := c.Param("jobId")
+	jobId, err := strconv.Atoi(jobIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("jobIdStr", jobIdStr).Msg("Invalid jobId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid jobId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("jobId", jobId).Msg("Url parameter read successfully")
+
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		_, err = h.ScanJobService.Cancel(tx, jobId)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to cancel scan job")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Scan job not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.Conflict); ok {
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Scan job already finished",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to cancel scan job",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Msg("Scan job cancelled successfully")
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,121 @@
+package scan_job_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// getJobResponse is the response model for GetJob API
+type getJobResponse struct {
+	// Unique identifier of the scan job
+	ScanJobId int `json:"scanJobId"`
+	// Scanned directories, empty when all roots are scanned
+	DirIds []int `json:"dirIds"`
+	// State of the job: queued, running, completed, failed or cancelled
+	Status string `json:"status"`
+	// Source of the job: api or watcher
+	TriggeredBy string `json:"triggeredBy"`
+	// Whether existing subdirectories are scanned too
+	Recursive bool `json:"recursive"`
+	// Whether every file is hashed regardless of its size and modification time
+	DeepVerify bool `json:"deepVerify"`
+	// Whether the job only computes the report without writing changes
+	DryRun bool `json:"dryRun"`
+	// Number of directories visited so far
+	DirsVisited int64 `json:"dirsVisited"`
+	// Number of directories skipped because their scan failed
+	DirsFailed int64 `json:"dirsFailed"`
+	// Number of files hashed so far
+	FilesHashed int64 `json:"filesHashed"`
+	// Number of bytes read while hashing
+	BytesProcessed int64 `json:"bytesProcessed"`
+	// Path that is being processed right now or was processed last
+	CurrentPath *string `json:"currentPath,omitempty"`
+	// Reason of the failure
+	Error *string `json:"error,omitempty"`
+	// Time the job was submitted
+	CreatedAt time.Time `json:"createdAt"`
+	// Time the job was started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Time the job was finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// GetJob
+// @Summary Retrieve a scan job by ID
+// @Description Retrieves the state and the live progress of a scan job
+// @Tags Jobs
+// @Accept  json
+// @Produce  json
+// @Param   jobId     path    int     true        "Scan Job ID"
+// @Success 200 {object} getJobResponse
+// @Failure 400 {object} response.Error "Invalid jobId format"
+// @Failure 404 {object} response.Error "Scan job not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /jobs/{jobId} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	log.Debug().Msg("Getting scan job")
+
+	jobIdStr := c.Param("jobId")
+	jobId, err := strconv.Atoi(jobIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("jobIdStr", jobIdStr).Msg("Invalid jobId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid jobId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("jobId", jobId).Msg("Url parameter read successfully")
+
+	var scanJob model.ScanJob
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		scanJob, err = h.ScanJobService.GetJob(tx, jobId)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get scan job")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Scan job not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get scan job",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Msg("Scan job got successfully")
+	c.JSON(http.StatusOK, getJobResponse{
+		ScanJobId:      scanJob.ScanJobId,
+		DirIds:         scanJob.DirIds,
+		Status:         scanJob.Status,
+		TriggeredBy:    scanJob.TriggeredBy,
+		Recursive:      scanJob.Recursive,
+		DeepVerify:     scanJob.DeepVerify,
+		DryRun:         scanJob.DryRun,
+		DirsVisited:    scanJob.DirsVisited,
+		DirsFailed:     scanJob.DirsFailed,
+		FilesHashed:    scanJob.FilesHashed,
+		BytesProcessed: scanJob.BytesProcessed,
+		CurrentPath:    scanJob.CurrentPath,
+		Error:          scanJob.Error,
+		CreatedAt:      scanJob.CreatedAt,
+		StartedAt:      scanJob.StartedAt,
+		FinishedAt:     scanJob.FinishedAt,
+	})
+}
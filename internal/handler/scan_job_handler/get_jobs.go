@@ -0,0 +1,107 @@
+package scan_job_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"time"
+)
+
+// getJobsResponseItem is the model for each item in the getJobs response
+type getJobsResponseItem struct {
+	// Unique identifier of the scan job
+	ScanJobId int `json:"scanJobId"`
+	// Scanned directories, empty when all roots are scanned
+	DirIds []int `json:"dirIds"`
+	// State of the job: queued, running, completed, failed or cancelled
+	Status string `json:"status"`
+	// Source of the job: api or watcher
+	TriggeredBy string `json:"triggeredBy"`
+	// Whether existing subdirectories are scanned too
+	Recursive bool `json:"recursive"`
+	// Whether every file is hashed regardless of its size and modification time
+	DeepVerify bool `json:"deepVerify"`
+	// Whether the job only computes the report without writing changes
+	DryRun bool `json:"dryRun"`
+	// Number of directories visited
+	DirsVisited int64 `json:"dirsVisited"`
+	// Number of directories skipped because their scan failed
+	DirsFailed int64 `json:"dirsFailed"`
+	// Number of files hashed
+	FilesHashed int64 `json:"filesHashed"`
+	// Number of bytes read while hashing
+	BytesProcessed int64 `json:"bytesProcessed"`
+	// Reason of the failure
+	Error *string `json:"error,omitempty"`
+	// Time the job was submitted
+	CreatedAt time.Time `json:"createdAt"`
+	// Time the job was started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Time the job was finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// getJobsResponse is the response model for GetJobs API
+type getJobsResponse struct {
+	// Array containing scan jobs, newest first
+	Jobs []getJobsResponseItem `json:"jobs"`
+}
+
+// GetJobs
+// @Summary Retrieve scan jobs history
+// @Description Retrieves all submitted scan jobs with their outcome, newest first
+// @Tags Jobs
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} getJobsResponse
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /jobs [get]
+func (h *Handler) GetJobs(c *gin.Context) {
+	log.Debug().Msg("Getting scan jobs")
+
+	var scanJobs []model.ScanJob
+	err := h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		scanJobs, err = h.ScanJobService.GetJobs(tx)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get scan jobs")
+		c.JSON(http.StatusInternalServerError, response.Error{
+			Message: "Failed to get scan jobs",
+			Reason:  err.Error(),
+		})
+		return
+	}
+
+	responseItems := make([]getJobsResponseItem, len(scanJobs))
+	for i, scanJob := range scanJobs {
+		responseItems[i] = getJobsResponseItem{
+			ScanJobId:      scanJob.ScanJobId,
+			DirIds:         scanJob.DirIds,
+			Status:         scanJob.Status,
+			TriggeredBy:    scanJob.TriggeredBy,
+			Recursive:      scanJob.Recursive,
+			DeepVerify:     scanJob.DeepVerify,
+			DryRun:         scanJob.DryRun,
+			DirsVisited:    scanJob.DirsVisited,
+			DirsFailed:     scanJob.DirsFailed,
+			FilesHashed:    scanJob.FilesHashed,
+			BytesProcessed: scanJob.BytesProcessed,
+			Error:          scanJob.Error,
+			CreatedAt:      scanJob.CreatedAt,
+			StartedAt:      scanJob.StartedAt,
+			FinishedAt:     scanJob.FinishedAt,
+		}
+	}
+
+	log.Debug().Msg("Scan jobs got successfully")
+	c.JSON(http.StatusOK, getJobsResponse{
+		Jobs: responseItems,
+	})
+}
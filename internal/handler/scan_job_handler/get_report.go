@@ -0,0 +1,180 @@
+package scan_job_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"music-files/internal/model"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// getReportResponseSummary counts changes of the scan
+type getReportResponseSummary struct {
+	// Number of directories found on disk
+	DirsCreated int `json:"dirsCreated"`
+	// Number of directories no longer on disk
+	DirsDeleted int `json:"dirsDeleted"`
+	// Number of new audio files
+	AudioFilesCreated int `json:"audioFilesCreated"`
+	// Number of audio files with changed content
+	AudioFilesUpdated int `json:"audioFilesUpdated"`
+	// Number of audio files no longer on disk
+	AudioFilesDeleted int `json:"audioFilesDeleted"`
+	// Number of audio files found under another directory or name, they keep their ids
+	AudioFilesMoved int `json:"audioFilesMoved"`
+	// Number of new covers
+	CoversCreated int `json:"coversCreated"`
+	// Number of covers with changed content
+	CoversUpdated int `json:"coversUpdated"`
+	// Number of covers no longer on disk
+	CoversDeleted int `json:"coversDeleted"`
+	// Number of covers found under another directory or name, they keep their ids
+	CoversMoved int `json:"coversMoved"`
+	// Number of directories and files skipped by ignore patterns
+	PathsIgnored int `json:"pathsIgnored"`
+}
+
+// getReportResponseEntry is a single change made by the scan
+type getReportResponseEntry struct {
+	// What happened: created, updated, moved, deleted or ignored
+	Action string `json:"action"`
+	// What it happened to: directory, audio_file, cover or file for ignored paths
+	Entity string `json:"entity"`
+	// Identifier of the directory, audio file or cover. Absent for entities created by a dry run and for ignored paths
+	EntityId *int `json:"entityId,omitempty"`
+	// Absolute path on disk. For moved entities it is the new location
+	Path string `json:"path"`
+}
+
+// getReportResponse is the response model for GetReport API
+type getReportResponse struct {
+	// Unique identifier of the scan job
+	ScanJobId int `json:"scanJobId"`
+	// State of the job: queued, running, completed, failed or cancelled
+	Status string `json:"status"`
+	// Whether the changes were only computed and not written
+	DryRun bool `json:"dryRun"`
+	// Time the job was submitted
+	CreatedAt time.Time `json:"createdAt"`
+	// Time the job was started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Time the job was finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Time between start and finish in milliseconds
+	DurationMs *int64 `json:"durationMs,omitempty"`
+	// Number of directories visited
+	DirsVisited int64 `json:"dirsVisited"`
+	// Number of directories skipped because their scan failed
+	DirsFailed int64 `json:"dirsFailed"`
+	// Number of files hashed
+	FilesHashed int64 `json:"filesHashed"`
+	// Number of bytes read while hashing
+	BytesProcessed int64 `json:"bytesProcessed"`
+	// Counters of changes
+	Summary getReportResponseSummary `json:"summary"`
+	// All changes in the order they were made
+	Entries []getReportResponseEntry `json:"entries"`
+}
+
+// GetReport
+// @Summary Retrieve the report of a scan job
+// @Description Retrieves directories, audio files and covers created, updated, moved and deleted by the scan job, with counters and timings
+// @Tags Jobs
+// @Accept  json
+// @Produce  json
+// @Param   jobId     path    int     true        "Scan Job ID"
+// @Success 200 {object} getReportResponse
+// @Failure 400 {object} response.Error "Invalid jobId format"
+// @Failure 404 {object} response.Error "Scan job not found"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /jobs/{jobId}/report [get]
+func (h *Handler) GetReport(c *gin.Context) {
+	log.Debug().Msg("Getting scan report")
+
+	jobIdStr := c.Param("jobId")
+	jobId, err := strconv.Atoi(jobIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("jobIdStr", jobIdStr).Msg("Invalid jobId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid jobId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("jobId", jobId).Msg("Url parameter read successfully")
+
+	var report model.ScanReport
+	err = h.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		report, err = h.ScanJobService.GetReport(tx, jobId)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get scan report")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Scan job not found",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to get scan report",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	scanJob := report.ScanJob
+	var durationMs *int64
+	if scanJob.StartedAt != nil && scanJob.FinishedAt != nil {
+		duration := scanJob.FinishedAt.Sub(*scanJob.StartedAt).Milliseconds()
+		durationMs = &duration
+	}
+
+	entries := make([]getReportResponseEntry, len(report.Entries))
+	for i, entry := range report.Entries {
+		entries[i] = getReportResponseEntry{
+			Action:   entry.Action,
+			Entity:   entry.Entity,
+			EntityId: entry.EntityId,
+			Path:     entry.Path,
+		}
+	}
+
+	log.Debug().Int("scanJobId", scanJob.ScanJobId).Msg("Scan report got successfully")
+	c.JSON(http.StatusOK, getReportResponse{
+		ScanJobId:      scanJob.ScanJobId,
+		Status:         scanJob.Status,
+		DryRun:         scanJob.DryRun,
+		CreatedAt:      scanJob.CreatedAt,
+		StartedAt:      scanJob.StartedAt,
+		FinishedAt:     scanJob.FinishedAt,
+		DurationMs:     durationMs,
+		DirsVisited:    scanJob.DirsVisited,
+		DirsFailed:     scanJob.DirsFailed,
+		FilesHashed:    scanJob.FilesHashed,
+		BytesProcessed: scanJob.BytesProcessed,
+		Summary: getReportResponseSummary{
+			DirsCreated:       report.Count(model.ScanReportEntityDirectory, model.ScanReportActionCreated),
+			DirsDeleted:       report.Count(model.ScanReportEntityDirectory, model.ScanReportActionDeleted),
+			AudioFilesCreated: report.Count(model.ScanReportEntityAudioFile, model.ScanReportActionCreated),
+			AudioFilesUpdated: report.Count(model.ScanReportEntityAudioFile, model.ScanReportActionUpdated),
+			AudioFilesDeleted: report.Count(model.ScanReportEntityAudioFile, model.ScanReportActionDeleted),
+			AudioFilesMoved:   report.Count(model.ScanReportEntityAudioFile, model.ScanReportActionMoved),
+			CoversCreated:     report.Count(model.ScanReportEntityCover, model.ScanReportActionCreated),
+			CoversUpdated:     report.Count(model.ScanReportEntityCover, model.ScanReportActionUpdated),
+			CoversDeleted:     report.Count(model.ScanReportEntityCover, model.ScanReportActionDeleted),
+			CoversMoved:       report.Count(model.ScanReportEntityCover, model.ScanReportActionMoved),
+			PathsIgnored: report.Count(model.ScanReportEntityDirectory, model.ScanReportActionIgnored) +
+				report.Count(model.ScanReportEntityFile, model.ScanReportActionIgnored),
+		},
+		Entries: entries,
+	})
+}
@@ -0,0 +1,22 @@
+package scan_job_handler
+
+import (
+	"music-files/internal/service"
+	"music-files/internal/service/scan_job_service"
+)
+
+type Handler struct {
+	ScanJobService     scan_job_service.Service
+	TransactionManager service.TransactionManager
+}
+
+func NewHandler(scanJobService scan_job_service.Service,
+	transactionManager service.TransactionManager) (h *Handler) {
+
+	h = &Handler{
+		ScanJobService:     scanJobService,
+		TransactionManager: transactionManager,
+	}
+
+	return h
+}
@@ -0,0 +1,75 @@
+package scan_job_handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/handler/response"
+	"net/http"
+	"strconv"
+)
+
+// resumeResponse is the response model for Resume API
+type resumeResponse struct {
+	// Identifier of the resumed scan job
+	ScanJobId int `json:"scanJobId"`
+	// State of the resumed scan job
+	Status string `json:"status"`
+}
+
+// Resume
+// @Summary Resume a scan job
+// @Description Puts a failed or cancelled scan job back in the queue. The job continues from the directories it has not scanned yet
+// @Tags Jobs
+// @Accept  json
+// @Produce  json
+// @Param   jobId     path    int     true        "Scan Job ID"
+// @Success 202 {object} resumeResponse
+// @Failure 400 {object} response.Error "Invalid jobId format"
+// @Failure 404 {object} response.Error "Scan job not found"
+// @Failure 409 {object} response.Error "Scan job can't be resumed"
+// @Failure 500 {object} response.Error "Internal Server Error"
+// @Router /jobs/{jobId}/resume [post]
+func (h *Handler) Resume(c *gin.Context) {
+	log.Debug().Msg("Resuming scan job")
+
+	jobIdStr := c.Param("jobId")
+	jobId, err := strconv.Atoi(jobIdStr)
+	if err != nil {
+		log.Error().Err(err).Str("jobIdStr", jobIdStr).Msg("Invalid jobId format")
+		c.JSON(http.StatusBadRequest, response.Error{
+			Message: "Invalid jobId format",
+			Reason:  err.Error(),
+		})
+		return
+	}
+	log.Debug().Int("jobId", jobId).Msg("Url parameter read successfully")
+
+	scanJob, err := h.ScanJobService.Resume(jobId)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to resume scan job")
+		if _, ok := err.(errors.NotFound); ok {
+			c.JSON(http.StatusNotFound, response.Error{
+				Message: "Scan job not found",
+				Reason:  err.Error(),
+			})
+		} else if _, ok = err.(errors.Conflict); ok {
+			c.JSON(http.StatusConflict, response.Error{
+				Message: "Scan job can't be resumed",
+				Reason:  err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, response.Error{
+				Message: "Failed to resume scan job",
+				Reason:  err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Debug().Int("scanJobId", scanJob.ScanJobId).Msg("Scan job resumed successfully")
+	c.JSON(http.StatusAccepted, resumeResponse{
+		ScanJobId: scanJob.ScanJobId,
+		Status:    scanJob.Status,
+	})
+}
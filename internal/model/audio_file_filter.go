@@ -0,0 +1,18 @@
+package model
+
+// AudioFileFilter selects audio files by tags and technical properties. Nil and empty conditions are not applied,
+// the ones that are must all match
+type AudioFileFilter struct {
+	// Field to value, the value must be among the values of the tag field
+	Tags          map[string]string
+	Codec         *string
+	Container     *string
+	BitsPerSample *int
+	Lossless      *bool
+	BitrateMode   *string
+}
+
+func (f AudioFileFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && f.Codec == nil && f.Container == nil && f.BitsPerSample == nil &&
+		f.Lossless == nil && f.BitrateMode == nil
+}
@@ -0,0 +1,10 @@
+package model
+
+// AudioFileTag is a value of a tag field of an audio file. Fields with several values have a row per value
+// in order of Position
+type AudioFileTag struct {
+	AudioFileId int    `db:"audio_file_id"`
+	Key         string `db:"key"`
+	Position    int    `db:"position"`
+	Value       string `db:"value"`
+}
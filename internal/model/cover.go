@@ -2,14 +2,61 @@ package model
 
 import "time"
 
+// What a cover image shows
+const (
+	CoverKindFront   = "front"
+	CoverKindBack    = "back"
+	CoverKindDisc    = "disc"
+	CoverKindBooklet = "booklet"
+)
+
+// Where a cover comes from
+const (
+	// An image file in a directory
+	CoverSourceFile = "file"
+	// A picture inside the tags of audio files, stored once per content in the embedded covers store
+	CoverSourceEmbedded = "embedded"
+)
+
 type Cover struct {
-	CoverId           int       `db:"cover_id"`
-	DirId             int       `db:"dir_id"`
-	Filename          string    `db:"filename"`
-	Extension         string    `db:"extension"`
-	SizeByte          int64     `db:"size_byte"`
-	WidthPx           int       `db:"width_px"`
-	HeightPx          int       `db:"height_px"`
-	Sha256            string    `db:"sha_256"`
-	LastContentUpdate time.Time `db:"last_content_update"`
+	CoverId int `db:"cover_id"`
+	// Nil for embedded covers
+	DirId             *int       `db:"dir_id"`
+	Filename          string     `db:"filename"`
+	Extension         string     `db:"extension"`
+	SizeByte          int64      `db:"size_byte"`
+	WidthPx           int        `db:"width_px"`
+	HeightPx          int        `db:"height_px"`
+	Sha256            string     `db:"sha_256"`
+	LastContentUpdate time.Time  `db:"last_content_update"`
+	ModifiedAt        *time.Time `db:"modified_at"`
+	Inode             *int64     `db:"inode"`
+	// One of CoverKind* values, assigned by the cover rules
+	Kind string `db:"kind"`
+	// One of CoverSource* values
+	Source string `db:"source"`
+	// Difference hash of the picture, nil until the picture is decoded by a scan, see utils.PerceptualHash
+	PerceptualHash *int64 `db:"perceptual_hash"`
+	// Palette of the picture as "#rrggbb", nil until the picture is decoded by a scan, see utils.ExtractPalette.
+	// Vibrant and muted colors stay nil if the picture has none
+	DominantColor *string `db:"dominant_color"`
+	VibrantColor  *string `db:"vibrant_color"`
+	MutedColor    *string `db:"muted_color"`
+	// Mean relative luminance from 0 (black) to 1 (white)
+	AverageLuminance *float64 `db:"average_luminance"`
+}
+
+// SimilarCover is a cover that looks like another one
+type SimilarCover struct {
+	Cover
+	// Hamming distance between the perceptual hashes, 0 for the same picture
+	Distance int
+}
+
+// CoverGroup is a set of covers of the same artwork at different sizes or compressions
+type CoverGroup struct {
+	// The variant with the highest resolution
+	Canonical Cover
+	// The other variants with their distance to the canonical one
+	Variants []SimilarCover
 }
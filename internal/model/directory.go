@@ -2,9 +2,39 @@ package model
 
 import "time"
 
+// How a scan treats symlinks to directories below a root. Symlinks to files are scanned under every policy
+const (
+	// Symlinks are skipped
+	SymlinkPolicyIgnore = "ignore"
+	// Symlinks are followed if they lead to a place inside the same root
+	SymlinkPolicyWithinRoot = "within_root"
+	// Symlinks are followed wherever they lead
+	SymlinkPolicyAnywhere = "anywhere"
+)
+
 type Directory struct {
 	DirId       int        `db:"dir_id"`
 	Name        string     `db:"name"`
 	ParentDirId *int       `db:"parent_dir_id"`
 	LastScanned *time.Time `db:"last_scanned"`
+	// Set while the directory is unavailable on disk, e.g. its disk is not mounted. Its content is kept meanwhile
+	OfflineSince *time.Time `db:"offline_since"`
+	// Set when a filesystem was mounted at the directory the last time it was scanned. Such a directory found
+	// empty is offline rather than emptied
+	MountPoint bool `db:"mount_point"`
+	// One of SymlinkPolicy* values, set for roots only. Nil means SymlinkPolicyIgnore
+	SymlinkPolicy *string `db:"symlink_policy"`
+	// Cover pinned by a user, it replaces the best front cover of the directory
+	CoverOverrideId *int `db:"cover_override_id"`
+	// Set once covers of the directory's audio files are stored. Directories scanned before covers were stored
+	// per audio file don't have it
+	CoversResolved bool `db:"covers_resolved"`
+}
+
+// SymlinkPolicyOrDefault returns the symlink policy of a root, SymlinkPolicyIgnore when none is set
+func (d Directory) SymlinkPolicyOrDefault() (symlinkPolicy string) {
+	if d.SymlinkPolicy == nil {
+		return SymlinkPolicyIgnore
+	}
+	return *d.SymlinkPolicy
 }
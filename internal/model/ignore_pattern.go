@@ -0,0 +1,9 @@
+package model
+
+// IgnorePattern is a gitignore-style pattern configured for a root. Patterns of a root apply in order of Position
+type IgnorePattern struct {
+	IgnorePatternId int    `db:"ignore_pattern_id"`
+	DirId           int    `db:"dir_id"`
+	Position        int    `db:"position"`
+	Pattern         string `db:"pattern"`
+}
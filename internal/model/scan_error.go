@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	// The directory itself could not be scanned
+	ScanErrorStageDirectory = "directory"
+	ScanErrorStageDetect    = "detect"
+	ScanErrorStageStat      = "stat"
+	ScanErrorStageHash      = "hash"
+	ScanErrorStageTags      = "tags"
+	ScanErrorStageCover     = "cover"
+	// The file was read but could not be stored in the database
+	ScanErrorStageStore = "store"
+	// The subdirectory leads back to one of its ancestors and is not descended into
+	ScanErrorStageLoop = "loop"
+)
+
+type ScanError struct {
+	ScanErrorId int `db:"scan_error_id"`
+	DirId       int `db:"dir_id"`
+	// Empty when the error belongs to the directory itself
+	Filename   string    `db:"filename"`
+	Stage      string    `db:"stage"`
+	Reason     string    `db:"reason"`
+	OccurredAt time.Time `db:"occurred_at"`
+}
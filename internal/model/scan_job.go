@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	ScanJobStatusQueued    = "queued"
+	ScanJobStatusRunning   = "running"
+	ScanJobStatusCompleted = "completed"
+	ScanJobStatusFailed    = "failed"
+	ScanJobStatusCancelled = "cancelled"
+)
+
+const (
+	ScanJobTriggeredByApi     = "api"
+	ScanJobTriggeredByWatcher = "watcher"
+)
+
+type ScanJob struct {
+	ScanJobId      int        `db:"scan_job_id"`
+	Status         string     `db:"status"`
+	TriggeredBy    string     `db:"triggered_by"`
+	Recursive      bool       `db:"recursive"`
+	DeepVerify     bool       `db:"deep_verify"`
+	DryRun         bool       `db:"dry_run"`
+	DirsVisited    int64      `db:"dirs_visited"`
+	DirsFailed     int64      `db:"dirs_failed"`
+	FilesHashed    int64      `db:"files_hashed"`
+	BytesProcessed int64      `db:"bytes_processed"`
+	CurrentPath    *string    `db:"current_path"`
+	Error          *string    `db:"error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	StartedAt      *time.Time `db:"started_at"`
+	FinishedAt     *time.Time `db:"finished_at"`
+	// Directories to scan, empty when all roots are scanned. Stored in scan_job_dirs
+	DirIds []int `db:"-"`
+}
+
+func (j ScanJob) IsFinished() bool {
+	return j.Status == ScanJobStatusCompleted ||
+		j.Status == ScanJobStatusFailed ||
+		j.Status == ScanJobStatusCancelled
+}
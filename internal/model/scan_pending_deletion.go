@@ -0,0 +1,11 @@
+package model
+
+// ScanPendingDeletion is an entity missing on disk. It is deleted at the end of the scan job
+// unless a moved file claims it first
+type ScanPendingDeletion struct {
+	PendingDeletionId int `db:"pending_deletion_id"`
+	ScanJobId         int `db:"scan_job_id"`
+	// One of ScanReportEntity* values
+	Entity   string `db:"entity"`
+	EntityId int    `db:"entity_id"`
+}
@@ -0,0 +1,46 @@
+package model
+
+const (
+	ScanReportActionCreated = "created"
+	ScanReportActionUpdated = "updated"
+	ScanReportActionDeleted = "deleted"
+	// The entity kept its id while its file got a new location
+	ScanReportActionMoved = "moved"
+	// The path matched an ignore pattern, or the symlink policy or the depth limit excluded it, and was skipped
+	ScanReportActionIgnored = "ignored"
+)
+
+const (
+	ScanReportEntityDirectory = "directory"
+	ScanReportEntityAudioFile = "audio_file"
+	ScanReportEntityCover     = "cover"
+	// Any file that is not a directory, used for ignored paths
+	ScanReportEntityFile = "file"
+)
+
+// ScanReportEntry is a single change made by a scan job
+type ScanReportEntry struct {
+	ScanReportEntryId int    `db:"scan_report_entry_id"`
+	ScanJobId         int    `db:"scan_job_id"`
+	Action            string `db:"action"`
+	Entity            string `db:"entity"`
+	// Nil for entities created by a dry run, they never got a real id, and for ignored paths
+	EntityId *int   `db:"entity_id"`
+	Path     string `db:"path"`
+}
+
+// ScanReport is everything a scan job did
+type ScanReport struct {
+	ScanJob ScanJob
+	Entries []ScanReportEntry
+}
+
+// Count returns the number of entries with the given entity and action
+func (r ScanReport) Count(entity string, action string) (count int) {
+	for _, entry := range r.Entries {
+		if entry.Entity == entity && entry.Action == action {
+			count++
+		}
+	}
+	return count
+}
@@ -3,15 +3,44 @@ package model
 import "time"
 
 type AudioFile struct {
-	AudioFileId       int       `db:"audio_file_id"`
-	DirId             int       `db:"dir_id"`
-	Filename          string    `db:"filename"`
-	Extension         string    `db:"extension"`
-	SizeByte          int64     `db:"size_byte"`
-	DurationMs        int64     `db:"duration_ms"`
-	BitrateKbps       int       `db:"bitrate_kbps"`
-	SampleRateHz      int       `db:"sample_rate_hz"`
-	ChannelsN         int       `db:"channels_n"`
-	Sha256            string    `db:"sha_256"`
-	LastContentUpdate time.Time `db:"last_content_update"`
+	AudioFileId       int        `db:"audio_file_id"`
+	DirId             int        `db:"dir_id"`
+	Filename          string     `db:"filename"`
+	Extension         string     `db:"extension"`
+	SizeByte          int64      `db:"size_byte"`
+	DurationMs        int64      `db:"duration_ms"`
+	BitrateKbps       int        `db:"bitrate_kbps"`
+	SampleRateHz      int        `db:"sample_rate_hz"`
+	ChannelsN         int        `db:"channels_n"`
+	Sha256            string     `db:"sha_256"`
+	PayloadSha256     *string    `db:"payload_sha_256"`
+	LastContentUpdate time.Time  `db:"last_content_update"`
+	ModifiedAt        *time.Time `db:"modified_at"`
+	Inode             *int64     `db:"inode"`
+	// Picture from the file's own tags, nil if it has none
+	EmbeddedCoverId *int `db:"embedded_cover_id"`
+	// Cover resolved for the file by the scan, nil if it has none
+	CoverId *int `db:"cover_id"`
+	// Cover pinned by a user, it goes before anything found on disk
+	CoverOverrideId *int `db:"cover_override_id"`
+	// Textual tags, nil when the file doesn't have them
+	Title       *string `db:"title"`
+	Artist      *string `db:"artist"`
+	Album       *string `db:"album"`
+	TrackNumber *int    `db:"track_number"`
+	DiscNumber  *int    `db:"disc_number"`
+	Year        *int    `db:"year"`
+	Genre       *string `db:"genre"`
+	Comment     *string `db:"comment"`
+	// Technical properties of the audio stream, nil when the reader doesn't know them
+	Codec     *string `db:"codec"`
+	Container *string `db:"container"`
+	// Only set for lossless streams
+	BitsPerSample *int  `db:"bits_per_sample"`
+	Lossless      *bool `db:"lossless"`
+	// utils.BitrateModeConstant or utils.BitrateModeVariable
+	BitrateMode *string `db:"bitrate_mode"`
+	Encoder     *string `db:"encoder"`
+	// Samples per channel
+	SampleCount *int64 `db:"sample_count"`
 }
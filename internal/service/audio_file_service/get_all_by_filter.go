@@ -0,0 +1,50 @@
+package audio_file_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"strings"
+)
+
+// GetAllByFilter returns audio files matching every condition of the filter. Tag fields, codecs, containers
+// and bitrate modes are case-insensitive, tag values are compared in the form they are stored in
+func (s *Service) GetAllByFilter(tx *sqlx.Tx, filter model.AudioFileFilter) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Interface("filter", filter).Msg("Fetching audio files by filter")
+
+	tags := make(map[string]string, len(filter.Tags))
+	for key, value := range filter.Tags {
+		key = strings.ToUpper(key)
+		tags[key] = utils.NormalizeTagValue(key, value)
+	}
+	filter.Tags = tags
+	if filter.Codec != nil {
+		codec := strings.ToLower(*filter.Codec)
+		filter.Codec = &codec
+	}
+	if filter.Container != nil {
+		container := strings.ToLower(*filter.Container)
+		filter.Container = &container
+	}
+	if filter.BitrateMode != nil {
+		bitrateMode := strings.ToUpper(*filter.BitrateMode)
+		if bitrateMode != utils.BitrateModeConstant && bitrateMode != utils.BitrateModeVariable {
+			log.Error().Str("bitrateMode", *filter.BitrateMode).Msg("Unknown bitrate mode")
+			return make([]model.AudioFile, 0), errors.BadRequest{Message: fmt.Sprintf("bitrate mode must be %s or %s, got %s",
+				utils.BitrateModeConstant, utils.BitrateModeVariable, *filter.BitrateMode)}
+		}
+		filter.BitrateMode = &bitrateMode
+	}
+
+	audioFiles, err = s.AudioFileRepo.ReadAllByFilter(tx, filter)
+	if err != nil {
+		log.Error().Err(err).Interface("filter", filter).Msg("Failed to fetch audio files by filter")
+		return make([]model.AudioFile, 0), err
+	}
+
+	log.Debug().Interface("filter", filter).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files by filter fetched successfully")
+	return audioFiles, nil
+}
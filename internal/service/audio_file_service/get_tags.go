@@ -0,0 +1,26 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/utils"
+)
+
+// GetTags returns all tag values stored for the audio file
+func (s *Service) GetTags(tx *sqlx.Tx, audioFileId int) (tags utils.Tags, err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Fetching tags of audio file")
+
+	audioFileTags, err := s.AudioFileTagRepo.ReadAllByAudioFile(tx, audioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to fetch tags of audio file")
+		return nil, err
+	}
+
+	tags = make(utils.Tags)
+	for _, audioFileTag := range audioFileTags {
+		tags[audioFileTag.Key] = append(tags[audioFileTag.Key], audioFileTag.Value)
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Int("countOfFields", len(tags)).Msg("Tags of audio file fetched successfully")
+	return tags, nil
+}
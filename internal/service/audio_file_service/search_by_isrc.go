@@ -0,0 +1,22 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// SearchByIsrc returns audio files of the recording with the ISRC, dashes in it are ignored
+func (s *Service) SearchByIsrc(tx *sqlx.Tx, isrc string) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Str("isrc", isrc).Msg("Fetching audio files by ISRC")
+
+	audioFiles, err = s.AudioFileRepo.ReadAllByTagValue(tx, "ISRC", utils.NormalizeTagValue("ISRC", isrc))
+	if err != nil {
+		log.Error().Err(err).Str("isrc", isrc).Msg("Failed to fetch audio files by ISRC")
+		return make([]model.AudioFile, 0), err
+	}
+
+	log.Debug().Str("isrc", isrc).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files by ISRC fetched successfully")
+	return audioFiles, nil
+}
@@ -0,0 +1,23 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// SearchByMbid returns audio files referring to the MusicBrainz id in any of their MUSICBRAINZ_*ID fields,
+// so it finds the tracks of a recording, a release, an artist or a work alike
+func (s *Service) SearchByMbid(tx *sqlx.Tx, mbid string) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Str("mbid", mbid).Msg("Fetching audio files by MusicBrainz id")
+
+	audioFiles, err = s.AudioFileRepo.ReadAllByTagValue(tx, `MUSICBRAINZ\_%ID`, utils.NormalizeTagValue("MUSICBRAINZ_TRACKID", mbid))
+	if err != nil {
+		log.Error().Err(err).Str("mbid", mbid).Msg("Failed to fetch audio files by MusicBrainz id")
+		return make([]model.AudioFile, 0), err
+	}
+
+	log.Debug().Str("mbid", mbid).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files by MusicBrainz id fetched successfully")
+	return audioFiles, nil
+}
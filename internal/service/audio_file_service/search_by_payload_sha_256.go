@@ -0,0 +1,21 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// SearchByPayloadSha256 finds audio files with the same audio data, tags are not taken into account
+func (s *Service) SearchByPayloadSha256(tx *sqlx.Tx, payloadSha256 string) (audioFiles []model.AudioFile, err error) {
+	log.Debug().Str("payloadSha256", payloadSha256).Msg("Fetching audio files by payload sha256")
+
+	audioFiles, err = s.AudioFileRepo.ReadAllByPayloadSha256(tx, payloadSha256)
+	if err != nil {
+		log.Error().Err(err).Str("payloadSha256", payloadSha256).Msg("Failed to fetch audio files")
+		return make([]model.AudioFile, 0), err
+	}
+
+	log.Debug().Str("payloadSha256", payloadSha256).Int("countOfAudioFiles", len(audioFiles)).Msg("Audio files fetched successfully")
+	return audioFiles, nil
+}
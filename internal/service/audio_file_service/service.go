@@ -2,16 +2,19 @@ package audio_file_service
 
 import (
 	"music-files/internal/database/repository/audio_file_repo"
+	"music-files/internal/database/repository/audio_file_tag_repo"
 )
 
 type Service struct {
-	AudioFileRepo audio_file_repo.Repo
+	AudioFileRepo    audio_file_repo.Repo
+	AudioFileTagRepo audio_file_tag_repo.Repo
 }
 
-func NewService(audioFileRepo audio_file_repo.Repo) (s *Service) {
+func NewService(audioFileRepo audio_file_repo.Repo, audioFileTagRepo audio_file_tag_repo.Repo) (s *Service) {
 
 	s = &Service{
-		AudioFileRepo: audioFileRepo,
+		AudioFileRepo:    audioFileRepo,
+		AudioFileTagRepo: audioFileTagRepo,
 	}
 
 	return s
@@ -0,0 +1,43 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"sort"
+)
+
+// SetTags replaces all tag values stored for the audio file with the ones read from it
+func (s *Service) SetTags(tx *sqlx.Tx, audioFileId int, tags utils.Tags) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Int("countOfFields", len(tags)).Msg("Setting tags of audio file")
+
+	err = s.AudioFileTagRepo.DeleteAllByAudioFile(tx, audioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to delete tags of audio file")
+		return err
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for i, value := range tags[key] {
+			err = s.AudioFileTagRepo.Create(tx, model.AudioFileTag{
+				AudioFileId: audioFileId,
+				Key:         key,
+				Position:    i,
+				Value:       value,
+			})
+			if err != nil {
+				log.Error().Err(err).Int("audioFileId", audioFileId).Str("key", key).Msg("Failed to create tag of audio file")
+				return err
+			}
+		}
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Tags of audio file set successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateCover stores the cover resolved for an audio file, nil if it has none
+func (s *Service) UpdateCover(tx *sqlx.Tx, audioFileId int, coverId *int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating cover of audio file")
+
+	err = s.AudioFileRepo.UpdateCover(tx, audioFileId, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to update cover of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package audio_file_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateCoverOverride pins a cover to an audio file, nil clears the pin. The resolved cover is not updated here
+func (s *Service) UpdateCoverOverride(tx *sqlx.Tx, audioFileId int, coverId *int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating cover override of audio file")
+
+	err = s.AudioFileRepo.UpdateCoverOverride(tx, audioFileId, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to update cover override of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover override of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,33 @@
+package audio_file_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// UpdateFileStat stores new size, modification time, inode, payload hash and embedded cover of an audio file whose content did not change
+func (s *Service) UpdateFileStat(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating file stat of audio file")
+
+	exists, err := s.AudioFileRepo.IsExists(tx, audioFileId)
+	if err != nil {
+		log.Error().Int("audioFileId", audioFileId).Msg("Failed to check audio file existence")
+		return err
+	}
+	if !exists {
+		log.Error().Int("audioFileId", audioFileId).Msg("Audio file not found")
+		return errors.NotFound{Resource: fmt.Sprintf("audioFile with audioFileId=%d in database", audioFileId)}
+	}
+
+	err = s.AudioFileRepo.UpdateFileStat(tx, audioFileId, audioFile)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to update file stat of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("File stat of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,33 @@
+package audio_file_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// UpdateLocation moves an audio file whose content did not change to another directory or name
+func (s *Service) UpdateLocation(tx *sqlx.Tx, audioFileId int, audioFile model.AudioFile) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Updating location of audio file")
+
+	exists, err := s.AudioFileRepo.IsExists(tx, audioFileId)
+	if err != nil {
+		log.Error().Int("audioFileId", audioFileId).Msg("Failed to check audio file existence")
+		return err
+	}
+	if !exists {
+		log.Error().Int("audioFileId", audioFileId).Msg("Audio file not found")
+		return errors.NotFound{Resource: fmt.Sprintf("audioFile with audioFileId=%d in database", audioFileId)}
+	}
+
+	err = s.AudioFileRepo.UpdateLocation(tx, audioFileId, audioFile)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to update location of audio file")
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Location of audio file updated successfully")
+	return nil
+}
@@ -0,0 +1,42 @@
+package cover_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// Analyze decodes the picture of the cover and fills in its perceptual hash and palette. A picture that can't be
+// decoded leaves them nil: the cover is still stored, it just isn't compared with others. Safe to call concurrently
+func (s *Service) Analyze(cover model.Cover, data []byte) (analyzed model.Cover) {
+	if !utils.IsDecodableImage(cover.Filename) {
+		return cover
+	}
+	img, err := utils.DecodeUpright(data)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", cover.Filename).Msg("Failed to decode picture of cover for analysis")
+		return cover
+	}
+
+	perceptualHash := utils.PerceptualHash(img)
+	palette := utils.ExtractPalette(img)
+	cover.PerceptualHash = &perceptualHash
+	cover.DominantColor = optionalString(palette.Dominant)
+	cover.VibrantColor = optionalString(palette.Vibrant)
+	cover.MutedColor = optionalString(palette.Muted)
+	cover.AverageLuminance = &palette.AverageLuminance
+	return cover
+}
+
+// IsAnalyzed tells whether the cover has the results of Analyze, covers stored before they were introduced don't.
+// Pictures of formats that can't be decoded count as analyzed, there is nothing to calculate for them
+func (s *Service) IsAnalyzed(cover model.Cover) (analyzed bool) {
+	return !utils.IsDecodableImage(cover.Filename) || cover.PerceptualHash != nil && cover.DominantColor != nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
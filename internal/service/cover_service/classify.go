@@ -0,0 +1,44 @@
+package cover_service
+
+import (
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// MayBeCover tells whether an image found in the directory has to be examined as a cover.
+// Its size is needed to tell for sure, see Classify
+func (s *Service) MayBeCover(dirName string, filename string) (mayBe bool) {
+	return s.rules.MayBeCover(dirName, filename)
+}
+
+// Classify returns the kind of an image in the directory. ok is false if the image is not a cover
+func (s *Service) Classify(dirName string, cover model.Cover) (kind string, ok bool) {
+	kind, _, ok = s.rules.Classify(dirName, cover.Filename, cover.WidthPx, cover.HeightPx)
+	return kind, ok
+}
+
+// Rank tells how well a cover in the directory fits its kind, lower is better
+func (s *Service) Rank(dirName string, cover model.Cover) (rank int) {
+	_, rank, _ = s.rules.Classify(dirName, cover.Filename, cover.WidthPx, cover.HeightPx)
+	return rank
+}
+
+// IsSubfolder tells whether the directory holds artwork of its parent, like Artwork/ or Scans/
+func (s *Service) IsSubfolder(dirName string) (isSubfolder bool) {
+	return s.rules.IsSubfolder(dirName)
+}
+
+// PreferEmbedded tells whether the picture embedded in an audio file goes before cover files next to it
+func (s *Service) PreferEmbedded() (prefer bool) {
+	return s.preferEmbedded
+}
+
+// UploadFilename returns the name an uploaded image is written under, format is the one reported by image.DecodeConfig
+func (s *Service) UploadFilename(format string) (filename string) {
+	return s.uploadName + utils.ImageExtension(format)
+}
+
+// UploadMaxBytes returns the size of the largest uploaded image that is accepted
+func (s *Service) UploadMaxBytes() (maxBytes int64) {
+	return s.uploadMaxBytes
+}
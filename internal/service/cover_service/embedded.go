@@ -0,0 +1,131 @@
+package cover_service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"image"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+)
+
+// PrepareEmbedded describes a picture taken from audio file tags as a cover. Safe to call concurrently
+func (s *Service) PrepareEmbedded(picture utils.EmbeddedPicture) (cover model.Cover, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(picture.Data))
+	if err != nil {
+		return model.Cover{}, fmt.Errorf("failed to decode embedded picture of type %q: %w", picture.MimeType, err)
+	}
+	extension := utils.ImageExtension(format)
+	hash := sha256.Sum256(picture.Data)
+	checksum := hex.EncodeToString(hash[:])
+
+	cover = model.Cover{
+		Filename:  checksum + extension,
+		Extension: extension,
+		SizeByte:  int64(len(picture.Data)),
+		WidthPx:   config.Width,
+		HeightPx:  config.Height,
+		Sha256:    checksum,
+		Kind:      embeddedKind(picture.PictureType),
+		Source:    model.CoverSourceEmbedded,
+	}
+	return s.Analyze(cover, picture.Data), nil
+}
+
+// StoreEmbedded returns the id of the embedded cover with the content, creating it if it is new.
+// Identical pictures of many audio files are stored once. A dry run creates the row only, the picture is not written
+func (s *Service) StoreEmbedded(tx *sqlx.Tx, cover model.Cover, data []byte, dryRun bool) (coverId int, err error) {
+	log.Debug().Str("sha256", cover.Sha256).Msg("Storing embedded cover")
+
+	candidates, err := s.CoverRepo.ReadAllBySha256(tx, cover.Sha256)
+	if err != nil {
+		log.Error().Err(err).Str("sha256", cover.Sha256).Msg("Failed to read covers by sha256")
+		return 0, err
+	}
+	var known model.Cover
+	for _, candidate := range candidates {
+		if candidate.Source == model.CoverSourceEmbedded {
+			coverId, known = candidate.CoverId, candidate
+		}
+	}
+
+	// The file is written even for a known cover, it could have been lost with a rolled back cleanup
+	if !dryRun {
+		err = s.writeEmbeddedFile(cover, data)
+		if err != nil {
+			log.Error().Err(err).Str("sha256", cover.Sha256).Msg("Failed to write embedded cover")
+			return 0, err
+		}
+	}
+	if coverId != 0 {
+		// Covers stored before the analysis was introduced get it here
+		if !s.IsAnalyzed(known) && s.IsAnalyzed(cover) {
+			err = s.UpdateAnalysis(tx, coverId, cover)
+			if err != nil {
+				return 0, err
+			}
+		}
+		log.Debug().Int("coverId", coverId).Msg("Embedded cover already stored")
+		return coverId, nil
+	}
+
+	coverId, err = s.CoverRepo.Create(tx, cover)
+	if err != nil {
+		log.Error().Err(err).Str("sha256", cover.Sha256).Msg("Failed to create embedded cover")
+		return 0, err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("Embedded cover stored successfully")
+	return coverId, nil
+}
+
+// EmbeddedPath returns where the picture of an embedded cover is kept
+func (s *Service) EmbeddedPath(cover model.Cover) (absolutePath string) {
+	return filepath.Join(s.embeddedDir, cover.Sha256[:2], cover.Filename)
+}
+
+// DeleteUnusedEmbedded removes embedded covers that no audio file has anymore, with their pictures
+func (s *Service) DeleteUnusedEmbedded(tx *sqlx.Tx) (countOfDeleted int, err error) {
+	log.Debug().Msg("Deleting unused embedded covers")
+
+	deletedCovers, err := s.CoverRepo.DeleteUnusedEmbedded(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete unused embedded covers")
+		return 0, err
+	}
+	for _, cover := range deletedCovers {
+		err = os.Remove(s.EmbeddedPath(cover))
+		if err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Int("coverId", cover.CoverId).Msg("Failed to remove picture of embedded cover")
+		}
+	}
+
+	log.Debug().Int("countOfDeleted", len(deletedCovers)).Msg("Unused embedded covers deleted successfully")
+	return len(deletedCovers), nil
+}
+
+func (s *Service) writeEmbeddedFile(cover model.Cover, data []byte) (err error) {
+	absolutePath := s.EmbeddedPath(cover)
+	if _, err = os.Stat(absolutePath); err == nil {
+		return nil
+	}
+	return utils.WriteFileAtomically(absolutePath, data)
+}
+
+func embeddedKind(pictureType int) (kind string) {
+	switch pictureType {
+	case utils.PictureTypeBackCover:
+		return model.CoverKindBack
+	case utils.PictureTypeLeaflet:
+		return model.CoverKindBooklet
+	case utils.PictureTypeMedia:
+		return model.CoverKindDisc
+	default:
+		return model.CoverKindFront
+	}
+}
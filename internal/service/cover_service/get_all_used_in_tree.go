@@ -0,0 +1,21 @@
+package cover_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// GetAllUsedInTree returns up to limit covers of audio files in the directory and below it, the most used first
+func (s *Service) GetAllUsedInTree(tx *sqlx.Tx, dirId int, limit int) (covers []model.Cover, err error) {
+	log.Debug().Int("dirId", dirId).Int("limit", limit).Msg("Getting covers used in directory tree")
+
+	covers, err = s.CoverRepo.ReadAllUsedInTree(tx, dirId, limit)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read covers used in directory tree")
+		return nil, err
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfCovers", len(covers)).Msg("Covers used in directory tree got successfully")
+	return covers, nil
+}
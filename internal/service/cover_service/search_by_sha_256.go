@@ -0,0 +1,20 @@
+package cover_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (s *Service) SearchBySha256(tx *sqlx.Tx, sha256 string) (covers []model.Cover, err error) {
+	log.Debug().Str("sha256", sha256).Msg("Fetching covers by sha256")
+
+	covers, err = s.CoverRepo.ReadAllBySha256(tx, sha256)
+	if err != nil {
+		log.Error().Err(err).Str("sha256", sha256).Msg("Failed to fetch covers")
+		return make([]model.Cover, 0), err
+	}
+
+	log.Debug().Str("sha256", sha256).Int("countOfCovers", len(covers)).Msg("Covers fetched successfully")
+	return covers, nil
+}
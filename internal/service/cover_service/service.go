@@ -1,15 +1,37 @@
 package cover_service
 
-import "music-files/internal/database/repository/cover_repo"
+import (
+	"music-files/internal/config"
+	"music-files/internal/database/repository/cover_repo"
+	"music-files/internal/utils"
+)
 
 type Service struct {
 	CoverRepo cover_repo.Repo
+
+	rules utils.CoverRules
+	// Root of the content-addressed store of embedded covers
+	embeddedDir string
+	// Take the picture embedded in an audio file before cover files
+	preferEmbedded bool
+	// Name of uploaded cover files without extension
+	uploadName string
+	// Largest uploaded image that is accepted
+	uploadMaxBytes int64
+	// Default largest Hamming distance between perceptual hashes of variants of the same artwork
+	similarityMaxDistance int
 }
 
-func NewService(coverRepo cover_repo.Repo) (s *Service) {
+func NewService(coverRepo cover_repo.Repo, coversConfig config.Covers) (s *Service) {
 
 	s = &Service{
-		CoverRepo: coverRepo,
+		CoverRepo:             coverRepo,
+		rules:                 coversConfig.Rules,
+		embeddedDir:           coversConfig.EmbeddedDir,
+		preferEmbedded:        coversConfig.PreferEmbedded,
+		uploadName:            coversConfig.UploadName,
+		uploadMaxBytes:        coversConfig.UploadMaxBytes,
+		similarityMaxDistance: coversConfig.SimilarityMaxDistance,
 	}
 
 	return s
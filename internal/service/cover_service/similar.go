@@ -0,0 +1,155 @@
+package cover_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"sort"
+)
+
+// GetSimilar returns covers whose perceptual hash is within maxDistance bits of the cover's one, nearest first.
+// Nil maxDistance takes the configured one
+func (s *Service) GetSimilar(tx *sqlx.Tx, coverId int, maxDistance *int) (similarCovers []model.SimilarCover, err error) {
+	log.Debug().Int("coverId", coverId).Msg("Getting similar covers")
+
+	distance, err := s.maxDistance(maxDistance)
+	if err != nil {
+		return nil, err
+	}
+	cover, err := s.GetCover(tx, coverId)
+	if err != nil {
+		return nil, err
+	}
+	if cover.PerceptualHash == nil {
+		log.Error().Int("coverId", coverId).Msg("Cover has no perceptual hash")
+		return nil, errors.Conflict{Message: fmt.Sprintf("cover with id=%d has no perceptual hash yet, it is calculated by the next scan of its directory", coverId)}
+	}
+	covers, err := s.CoverRepo.ReadAllWithPerceptualHash(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read covers with perceptual hash")
+		return nil, err
+	}
+
+	similarCovers = []model.SimilarCover{}
+	for _, candidate := range covers {
+		if candidate.CoverId == coverId {
+			continue
+		}
+		candidateDistance := utils.HammingDistance(*cover.PerceptualHash, *candidate.PerceptualHash)
+		if candidateDistance <= distance {
+			similarCovers = append(similarCovers, model.SimilarCover{Cover: candidate, Distance: candidateDistance})
+		}
+	}
+	sort.SliceStable(similarCovers, func(i, j int) bool {
+		if similarCovers[i].Distance != similarCovers[j].Distance {
+			return similarCovers[i].Distance < similarCovers[j].Distance
+		}
+		return isBetterVariant(similarCovers[i].Cover, similarCovers[j].Cover)
+	})
+
+	log.Debug().Int("coverId", coverId).Int("countOfSimilarCovers", len(similarCovers)).Msg("Similar covers got successfully")
+	return similarCovers, nil
+}
+
+// GetDuplicateGroups splits covers of the library into groups of variants of the same artwork. Covers are in one group
+// when they are linked by a chain of covers each within maxDistance bits of the next. Groups of a single cover are left out.
+// Nil maxDistance takes the configured one
+func (s *Service) GetDuplicateGroups(tx *sqlx.Tx, maxDistance *int) (groups []model.CoverGroup, err error) {
+	log.Debug().Msg("Getting groups of duplicate covers")
+
+	distance, err := s.maxDistance(maxDistance)
+	if err != nil {
+		return nil, err
+	}
+	covers, err := s.CoverRepo.ReadAllWithPerceptualHash(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read covers with perceptual hash")
+		return nil, err
+	}
+
+	parents := make([]int, len(covers))
+	for i := range parents {
+		parents[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+		return parents[i]
+	}
+	for i := range covers {
+		for j := i + 1; j < len(covers); j++ {
+			if utils.HammingDistance(*covers[i].PerceptualHash, *covers[j].PerceptualHash) <= distance {
+				parents[root(j)] = root(i)
+			}
+		}
+	}
+
+	// Covers are ordered by id, so are the groups by their first cover
+	members := make(map[int][]model.Cover)
+	var roots []int
+	for i, cover := range covers {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], cover)
+	}
+
+	groups = []model.CoverGroup{}
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+		canonical := members[r][0]
+		for _, cover := range members[r][1:] {
+			if isBetterVariant(cover, canonical) {
+				canonical = cover
+			}
+		}
+		group := model.CoverGroup{Canonical: canonical}
+		for _, cover := range members[r] {
+			if cover.CoverId == canonical.CoverId {
+				continue
+			}
+			group.Variants = append(group.Variants, model.SimilarCover{
+				Cover:    cover,
+				Distance: utils.HammingDistance(*canonical.PerceptualHash, *cover.PerceptualHash),
+			})
+		}
+		groups = append(groups, group)
+	}
+
+	log.Debug().Int("countOfCovers", len(covers)).Int("countOfGroups", len(groups)).Msg("Groups of duplicate covers got successfully")
+	return groups, nil
+}
+
+func (s *Service) maxDistance(maxDistance *int) (distance int, err error) {
+	if maxDistance == nil {
+		return s.similarityMaxDistance, nil
+	}
+	if *maxDistance < 0 || *maxDistance > 64 {
+		return 0, errors.BadRequest{Message: fmt.Sprintf("maxDistance %d is out of range from 0 to 64", *maxDistance)}
+	}
+	return *maxDistance, nil
+}
+
+// isBetterVariant tells whether the cover is a better copy of the artwork: higher resolution, then larger file,
+// then a file in a directory rather than a picture embedded in audio files
+func isBetterVariant(cover model.Cover, other model.Cover) bool {
+	area, otherArea := cover.WidthPx*cover.HeightPx, other.WidthPx*other.HeightPx
+	if area != otherArea {
+		return area > otherArea
+	}
+	if cover.SizeByte != other.SizeByte {
+		return cover.SizeByte > other.SizeByte
+	}
+	if cover.Source != other.Source {
+		return cover.Source == model.CoverSourceFile
+	}
+	return cover.CoverId < other.CoverId
+}
@@ -0,0 +1,21 @@
+package cover_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// UpdateAnalysis stores the perceptual hash and the palette of a cover whose content did not change
+func (s *Service) UpdateAnalysis(tx *sqlx.Tx, coverId int, cover model.Cover) (err error) {
+	log.Debug().Int("coverId", coverId).Msg("Updating analysis of cover")
+
+	err = s.CoverRepo.UpdateAnalysis(tx, coverId, cover)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Msg("Failed to update analysis of cover")
+		return err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("Analysis of cover updated successfully")
+	return nil
+}
@@ -0,0 +1,33 @@
+package cover_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// UpdateLocation moves a cover whose content did not change to another directory or name
+func (s *Service) UpdateLocation(tx *sqlx.Tx, coverId int, cover model.Cover) (err error) {
+	log.Debug().Int("coverId", coverId).Msg("Updating location of cover")
+
+	exists, err := s.CoverRepo.IsExists(tx, coverId)
+	if err != nil {
+		log.Error().Int("coverId", coverId).Msg("Failed to check cover existence")
+		return err
+	}
+	if !exists {
+		log.Error().Int("coverId", coverId).Msg("Cover not found")
+		return errors.NotFound{Resource: fmt.Sprintf("cover with coverId=%d in database", coverId)}
+	}
+
+	err = s.CoverRepo.UpdateLocation(tx, coverId, cover)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Msg("Failed to update location of cover")
+		return err
+	}
+
+	log.Debug().Int("coverId", coverId).Msg("Location of cover updated successfully")
+	return nil
+}
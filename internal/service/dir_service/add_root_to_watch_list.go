@@ -15,6 +15,12 @@ func (s *Service) AddRootToWatchList(tx *sqlx.Tx, dir model.Directory) (createdD
 	log.Debug().Str("path", dir.Name).Msg("Adding a new directory tracking")
 
 	dir.Name = filepath.Clean(dir.Name)
+	if dir.SymlinkPolicy != nil {
+		err = checkSymlinkPolicy(*dir.SymlinkPolicy)
+		if err != nil {
+			return model.Directory{}, err
+		}
+	}
 
 	directoryExists, err := utils.IsDirectoryExistsOnDisk(dir.Name)
 	if err != nil {
@@ -0,0 +1,74 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+)
+
+// SetCoverOverride pins a cover to the directory, it replaces the best front cover for its audio files
+// and for the directories below that have no covers of their own. Nil clears the pin
+func (s *Service) SetCoverOverride(tx *sqlx.Tx, dirId int, coverId *int) (err error) {
+	log.Debug().Int("dirId", dirId).Msg("Setting cover override of directory")
+
+	exists, err := s.DirRepo.IsExists(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence")
+		return err
+	}
+	if !exists {
+		log.Error().Int("dirId", dirId).Msg("Directory not found")
+		return errors.NotFound{Resource: "directory in database"}
+	}
+	if coverId != nil {
+		_, err = s.CoverService.GetCover(tx, *coverId)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", *coverId).Msg("Failed to get cover")
+			return err
+		}
+	}
+
+	err = s.DirRepo.UpdateCoverOverride(tx, dirId, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to update cover override of directory")
+		return err
+	}
+	err = s.ResolveCovers(tx, dirId, true)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Cover override of directory set successfully")
+	return nil
+}
+
+// SetAudioFileCoverOverride pins a cover to the audio file, it goes before anything found on disk. Nil clears the pin
+func (s *Service) SetAudioFileCoverOverride(tx *sqlx.Tx, audioFileId int, coverId *int) (err error) {
+	log.Debug().Int("audioFileId", audioFileId).Msg("Setting cover override of audio file")
+
+	audioFile, err := s.AudioFileService.GetAudioFile(tx, audioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to get audio file")
+		return err
+	}
+	if coverId != nil {
+		_, err = s.CoverService.GetCover(tx, *coverId)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", *coverId).Msg("Failed to get cover")
+			return err
+		}
+	}
+
+	err = s.AudioFileService.UpdateCoverOverride(tx, audioFileId, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to update cover override of audio file")
+		return err
+	}
+	err = s.ResolveCovers(tx, audioFile.DirId, false)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Int("audioFileId", audioFileId).Msg("Cover override of audio file set successfully")
+	return nil
+}
@@ -0,0 +1,176 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+)
+
+// DeletePending deletes an entity the scan found missing on disk, if it is still stored and still missing.
+// A file moved elsewhere has its new location by now and is kept
+func (s *Service) DeletePending(tx *sqlx.Tx, session *ScanSession, deletion model.ScanPendingDeletion) (err error) {
+	log.Debug().Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Deleting entity missing on disk")
+
+	switch deletion.Entity {
+	case model.ScanReportEntityDirectory:
+		err = s.deletePendingDir(tx, session, deletion.EntityId)
+	case model.ScanReportEntityAudioFile:
+		err = s.deletePendingAudioFile(tx, session, deletion.EntityId)
+	case model.ScanReportEntityCover:
+		err = s.deletePendingCover(tx, session, deletion.EntityId)
+	default:
+		log.Warn().Str("entity", deletion.Entity).Msg("Unknown entity of pending deletion")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Failed to delete entity missing on disk")
+		return err
+	}
+
+	log.Debug().Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Entity missing on disk processed successfully")
+	return nil
+}
+
+func (s *Service) deletePendingDir(tx *sqlx.Tx, session *ScanSession, dirId int) (err error) {
+	exists, err := s.DirRepo.IsExists(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence")
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	absolutePath, err := s.AbsolutePath(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to calculate absolute path to directory")
+		return err
+	}
+	existsOnDisk, err := utils.IsDirectoryExistsOnDisk(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence on disk")
+		return err
+	}
+	if existsOnDisk {
+		return nil
+	}
+
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read directory")
+		return err
+	}
+	err = s.reportDeletedDir(tx, session, dirId, absolutePath)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to report deleted directory")
+		return err
+	}
+	err = s.DeleteDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to delete directory")
+		return err
+	}
+
+	// Audio files of the parent may have used covers of a deleted artwork subfolder
+	if dir.ParentDirId != nil && s.CoverService.IsSubfolder(dir.Name) {
+		err = s.ResolveCovers(tx, *dir.ParentDirId, true)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", *dir.ParentDirId).Msg("Failed to resolve covers after deleting artwork subfolder")
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) deletePendingAudioFile(tx *sqlx.Tx, session *ScanSession, audioFileId int) (err error) {
+	audioFile, err := s.AudioFileService.GetAudioFile(tx, audioFileId)
+	if _, ok := err.(errors.NotFound); ok {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	absolutePath, err := s.AbsolutePath(tx, audioFile.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", audioFile.DirId).Msg("Failed to calculate absolute path to directory")
+		return err
+	}
+	// The file may have been replaced with one of another kind, then it is not ours anymore
+	present, err := s.isPresentAs(filepath.Join(absolutePath, audioFile.Filename), utils.IsMusicFile)
+	if err != nil || present {
+		return err
+	}
+	err = s.AudioFileService.Delete(tx, audioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to delete audio file")
+		return err
+	}
+	session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityAudioFile, audioFileId, filepath.Join(absolutePath, audioFile.Filename))
+	return nil
+}
+
+func (s *Service) deletePendingCover(tx *sqlx.Tx, session *ScanSession, coverId int) (err error) {
+	cover, err := s.CoverService.GetCover(tx, coverId)
+	if _, ok := err.(errors.NotFound); ok {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if cover.DirId == nil {
+		return nil
+	}
+
+	absolutePath, err := s.AbsolutePath(tx, *cover.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", *cover.DirId).Msg("Failed to calculate absolute path to directory")
+		return err
+	}
+	// The file may have been replaced with one of another kind, then it is not ours anymore
+	present, err := s.isPresentAs(filepath.Join(absolutePath, cover.Filename), utils.IsImageFile)
+	if err != nil || present {
+		return err
+	}
+	err = s.CoverService.Delete(tx, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Msg("Failed to delete cover")
+		return err
+	}
+	session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityCover, coverId, filepath.Join(absolutePath, cover.Filename))
+
+	dir, err := s.DirRepo.Read(tx, *cover.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", *cover.DirId).Msg("Failed to read directory of cover")
+		return err
+	}
+	err = s.resolveCoversAfterChange(tx, dir)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", *cover.DirId).Msg("Failed to resolve covers after deleting cover")
+		return err
+	}
+	return nil
+}
+
+// isPresentAs tells whether a file of the kind checked by isKind exists at the path.
+// A file that can't be examined is taken as present, so it is kept like during the scan
+func (s *Service) isPresentAs(absolutePath string, isKind func(absolutePath string) (bool, error)) (present bool, err error) {
+	_, err = os.Lstat(absolutePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to check file on disk, keeping it")
+		return true, nil
+	}
+
+	isOfKind, err := isKind(absolutePath)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to detect file kind, keeping it")
+		return true, nil
+	}
+	return isOfKind, nil
+}
@@ -0,0 +1,47 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/utils"
+	"os"
+)
+
+// readEmbeddedCover extracts the best picture from the tags of an audio file. A picture that can't be read
+// doesn't make the file fail, the file is just left without an embedded cover. Safe to call concurrently
+func (s *Service) readEmbeddedCover(absolutePath string) (embedded *embeddedCover) {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open file to read embedded pictures")
+		return nil
+	}
+	defer file.Close()
+
+	pictures, err := utils.ReadEmbeddedPictures(file)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read embedded pictures")
+		return nil
+	}
+	picture, ok := utils.BestEmbeddedPicture(pictures)
+	if !ok {
+		return nil
+	}
+	cover, err := s.CoverService.PrepareEmbedded(picture)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to prepare embedded picture")
+		return nil
+	}
+	return &embeddedCover{cover: cover, data: picture.Data}
+}
+
+// storeEmbeddedCover saves the picture read from the audio file and returns its cover id, nil if there is none
+func (s *Service) storeEmbeddedCover(tx *sqlx.Tx, session *ScanSession, task audioFileTask) (coverId *int, err error) {
+	if task.embeddedCover == nil {
+		return nil, nil
+	}
+	storedCoverId, err := s.CoverService.StoreEmbedded(tx, task.embeddedCover.cover, task.embeddedCover.data, session.Options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	return &storedCoverId, nil
+}
@@ -0,0 +1,60 @@
+package dir_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"path/filepath"
+	"strings"
+)
+
+// FindByAbsolutePath looks up a tracked directory by its path on disk
+func (s *Service) FindByAbsolutePath(tx *sqlx.Tx, absolutePath string) (dir model.Directory, err error) {
+	log.Debug().Str("absolutePath", absolutePath).Msg("Finding directory by absolute path")
+
+	absolutePath = filepath.Clean(absolutePath)
+	notFound := errors.NotFound{Resource: fmt.Sprintf("directory with path=%s", absolutePath)}
+
+	roots, err := s.DirRepo.ReadRoots(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read root directories")
+		return model.Directory{}, err
+	}
+
+	for _, root := range roots {
+		relativePath, err := filepath.Rel(root.Name, absolutePath)
+		if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		dir = root
+		if relativePath == "." {
+			log.Debug().Str("absolutePath", absolutePath).Int("dirId", dir.DirId).Msg("Directory found by absolute path")
+			return dir, nil
+		}
+		for _, name := range strings.Split(relativePath, string(filepath.Separator)) {
+			exists, err := s.DirRepo.IsExistsByParentAndName(tx, &dir.DirId, name)
+			if err != nil {
+				log.Error().Err(err).Int("parentDirId", dir.DirId).Str("name", name).Msg("Failed to check directory existence")
+				return model.Directory{}, err
+			}
+			if !exists {
+				log.Debug().Str("absolutePath", absolutePath).Msg("Directory not found by absolute path")
+				return model.Directory{}, notFound
+			}
+			dir, err = s.DirRepo.ReadByParentAndName(tx, &dir.DirId, name)
+			if err != nil {
+				log.Error().Err(err).Int("parentDirId", dir.DirId).Str("name", name).Msg("Failed to read directory")
+				return model.Directory{}, err
+			}
+		}
+
+		log.Debug().Str("absolutePath", absolutePath).Int("dirId", dir.DirId).Msg("Directory found by absolute path")
+		return dir, nil
+	}
+
+	log.Debug().Str("absolutePath", absolutePath).Msg("Directory not found by absolute path")
+	return model.Directory{}, notFound
+}
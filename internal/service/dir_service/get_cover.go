@@ -0,0 +1,33 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// GetCover returns the cover of the directory itself: the pinned one or the best front cover of the directory
+// and its artwork subfolders. Covers of ancestors and descendants are not looked at, found is false without one
+func (s *Service) GetCover(tx *sqlx.Tx, dirId int) (cover model.Cover, found bool, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Getting cover of directory")
+
+	dir, err := s.GetDir(tx, dirId)
+	if err != nil {
+		return model.Cover{}, false, err
+	}
+	if dir.CoverOverrideId != nil {
+		cover, err = s.CoverService.GetCover(tx, *dir.CoverOverrideId)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", *dir.CoverOverrideId).Msg("Failed to get pinned cover")
+			return model.Cover{}, false, err
+		}
+		return cover, true, nil
+	}
+	cover, found, err = s.bestFrontCover(tx, dir)
+	if err != nil {
+		return model.Cover{}, false, err
+	}
+
+	log.Debug().Int("dirId", dirId).Bool("found", found).Msg("Cover of directory got successfully")
+	return cover, found, nil
+}
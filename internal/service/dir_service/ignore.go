@@ -0,0 +1,122 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ignoredEntries returns names of the directory's entries excluded by the patterns of its root and by
+// .wakarimiignore files of the directory and its ancestors. Every ignored entry is added to the report
+func (s *Service) ignoredEntries(tx *sqlx.Tx, session *ScanSession, dirId int, entries []os.DirEntry) (ignored map[string]bool, err error) {
+	ignored = make(map[string]bool)
+
+	rules, relativePath, absolutePath, err := s.ignoreRules(tx, dirId)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return ignored, nil
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == utils.IgnoreFilename {
+			continue
+		}
+		if utils.IsIgnored(rules, path.Join(relativePath, entry.Name()), entry.IsDir()) {
+			ignored[entry.Name()] = true
+			entity := model.ScanReportEntityFile
+			if entry.IsDir() {
+				entity = model.ScanReportEntityDirectory
+			}
+			session.Report.addIgnored(entity, filepath.Join(absolutePath, entry.Name()))
+		}
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfIgnoredEntries", len(ignored)).Msg("Ignored entries of directory found")
+	return ignored, nil
+}
+
+// ignoreRules collects the rules that apply inside the directory, from the lowest priority to the highest:
+// patterns of the root, then .wakarimiignore files from the root down to the directory.
+// Also returns the slash-separated path of the directory relative to its root and its absolute path
+func (s *Service) ignoreRules(tx *sqlx.Tx, dirId int) (rules []utils.IgnoreRules, relativePath string, absolutePath string, err error) {
+	var chain []model.Directory
+	currentDir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch directory from database")
+		return nil, "", "", err
+	}
+	chain = append(chain, currentDir)
+	for currentDir.ParentDirId != nil {
+		currentDir, err = s.DirRepo.Read(tx, *currentDir.ParentDirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch parent directory from database")
+			return nil, "", "", err
+		}
+		chain = append(chain, currentDir)
+	}
+
+	root := chain[len(chain)-1]
+	patterns, err := s.IgnoreService.GetPatterns(tx, root.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", root.DirId).Msg("Failed to get ignore patterns of root")
+		return nil, "", "", err
+	}
+	rootRules, err := utils.ParseIgnoreRules("", patterns)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", root.DirId).Msg("Failed to parse ignore patterns of root")
+		return nil, "", "", err
+	}
+	if len(rootRules.Patterns) > 0 {
+		rules = append(rules, rootRules)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if i == len(chain)-1 {
+			absolutePath = root.Name
+		} else {
+			absolutePath = filepath.Join(absolutePath, chain[i].Name)
+			relativePath = path.Join(relativePath, chain[i].Name)
+		}
+
+		fileRules, err := s.readIgnoreFile(filepath.Join(absolutePath, utils.IgnoreFilename), relativePath)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if len(fileRules.Patterns) > 0 {
+			rules = append(rules, fileRules)
+		}
+	}
+
+	return rules, relativePath, absolutePath, nil
+}
+
+// readIgnoreFile parses a .wakarimiignore file. A missing file has no rules
+func (s *Service) readIgnoreFile(absolutePath string, base string) (rules utils.IgnoreRules, err error) {
+	file, err := os.Open(absolutePath)
+	if os.IsNotExist(err) {
+		return utils.IgnoreRules{}, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open ignore file")
+		return utils.IgnoreRules{}, err
+	}
+	defer file.Close()
+
+	lines, err := utils.ReadIgnoreLines(file)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read ignore file")
+		return utils.IgnoreRules{}, err
+	}
+	rules, err = utils.ParseIgnoreRules(base, lines)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to parse ignore file")
+		return utils.IgnoreRules{}, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,87 @@
+package dir_service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/utils"
+	"strings"
+)
+
+// MetadataReader reads technical details and tags of an audio file. Implementations must be safe to call concurrently
+type MetadataReader interface {
+	// Name under which the reader is listed in SCANNER_METADATA_READERS
+	Name() string
+	Read(absolutePath string) (metadata Metadata, err error)
+}
+
+// Metadata is what a MetadataReader finds in an audio file
+type Metadata struct {
+	Properties utils.AudioProperties
+	Tags       utils.Tags
+}
+
+// Readers compiled into the service by name. Optional ones register themselves from files behind build tags
+var metadataReaders = map[string]func() MetadataReader{
+	"native": newNativeMetadataReader,
+}
+
+// metadataReaderChain tries its readers in order until one of them succeeds
+type metadataReaderChain []MetadataReader
+
+// newMetadataReaderChain builds the chain from reader names. Readers that aren't compiled in are skipped,
+// the pure Go one is used if none is left
+func newMetadataReaderChain(names []string) (chain metadataReaderChain) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		newReader, ok := metadataReaders[name]
+		if !ok {
+			log.Info().Str("metadataReader", name).Msg("Metadata reader isn't compiled in, skipping it")
+			continue
+		}
+		chain = append(chain, newReader())
+	}
+	if len(chain) == 0 {
+		log.Warn().Msg("No metadata readers configured, using the native one")
+		chain = append(chain, newNativeMetadataReader())
+	}
+	return chain
+}
+
+func (c metadataReaderChain) Name() string {
+	names := make([]string, len(c))
+	for i, reader := range c {
+		names[i] = reader.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c metadataReaderChain) Read(absolutePath string) (metadata Metadata, err error) {
+	var errs []error
+	for _, reader := range c {
+		metadata, err = reader.Read(absolutePath)
+		if err == nil {
+			return metadata, nil
+		}
+		log.Debug().Err(err).Str("metadataReader", reader.Name()).Str("absolutePath", absolutePath).Msg("Metadata reader failed, trying the next one")
+		errs = append(errs, fmt.Errorf("%s: %w", reader.Name(), err))
+	}
+	return Metadata{}, stderrors.Join(errs...)
+}
+
+// optionalString gives nil for an empty tag
+func optionalString(value string) (optional *string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// optionalNumber gives nil for a missing or zero number, tags use zero for numbers that aren't set
+func optionalNumber(value int, ok bool) (optional *int) {
+	if !ok || value <= 0 {
+		return nil
+	}
+	return &value
+}
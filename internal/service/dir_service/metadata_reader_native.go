@@ -0,0 +1,49 @@
+package dir_service
+
+import (
+	"music-files/internal/utils"
+	"os"
+)
+
+// nativeMetadataReader parses headers and tags in pure Go, so it works in builds without cgo
+type nativeMetadataReader struct {
+}
+
+func newNativeMetadataReader() MetadataReader {
+	return nativeMetadataReader{}
+}
+
+func (r nativeMetadataReader) Name() string {
+	return "native"
+}
+
+func (r nativeMetadataReader) Read(absolutePath string) (metadata Metadata, err error) {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer file.Close()
+
+	format, ok, err := utils.DetectAudioFormat(file, absolutePath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if !ok || !format.Capabilities.NativeMetadata {
+		// Left to the next reader, e.g. taglib for APE and WavPack
+		return Metadata{}, utils.ErrUnknownAudioFormat
+	}
+
+	metadata.Properties, err = utils.ReadAudioProperties(file)
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata.Tags, err = utils.ReadTags(file)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if metadata.Properties.Encoder == "" {
+		// Formats without an encoder in the stream headers may name it in the tags
+		metadata.Properties.Encoder = metadata.Tags.First("ENCODER")
+	}
+	return metadata, nil
+}
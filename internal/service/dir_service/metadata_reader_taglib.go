@@ -0,0 +1,85 @@
+//go:build taglib
+
+package dir_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/wtolson/go-taglib"
+	"music-files/internal/utils"
+	"os"
+	"strconv"
+)
+
+func init() {
+	metadataReaders["taglib"] = newTaglibMetadataReader
+}
+
+// taglibMetadataReader reads files with the taglib C library, which knows formats the native reader doesn't,
+// such as APE, WavPack and WMA. Needs cgo and libtag
+type taglibMetadataReader struct {
+}
+
+func newTaglibMetadataReader() MetadataReader {
+	return taglibMetadataReader{}
+}
+
+func (r taglibMetadataReader) Name() string {
+	return "taglib"
+}
+
+func (r taglibMetadataReader) Read(absolutePath string) (metadata Metadata, err error) {
+	fileDetails, err := taglib.Read(absolutePath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer fileDetails.Close()
+
+	metadata.Properties = utils.AudioProperties{
+		Duration:     fileDetails.Length(),
+		BitrateKbps:  fileDetails.Bitrate(),
+		SampleRateHz: fileDetails.Samplerate(),
+		ChannelsN:    fileDetails.Channels(),
+	}
+
+	// taglib gives only the basic fields and properties, the rest are read directly where the format allows
+	file, err := os.Open(absolutePath)
+	if err == nil {
+		if properties, err := utils.ReadAudioProperties(file); err == nil {
+			metadata.Properties.Codec = properties.Codec
+			metadata.Properties.Container = properties.Container
+			metadata.Properties.BitsPerSample = properties.BitsPerSample
+			metadata.Properties.Lossless = properties.Lossless
+			metadata.Properties.BitrateMode = properties.BitrateMode
+			metadata.Properties.Encoder = properties.Encoder
+			metadata.Properties.SampleCount = properties.SampleCount
+		}
+		metadata.Tags, err = utils.ReadTags(file)
+		file.Close()
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read tags beyond the ones of taglib")
+		metadata.Tags = make(utils.Tags)
+	}
+	basicFields := map[string]string{
+		"TITLE":   fileDetails.Title(),
+		"ARTIST":  fileDetails.Artist(),
+		"ALBUM":   fileDetails.Album(),
+		"GENRE":   fileDetails.Genre(),
+		"COMMENT": fileDetails.Comment(),
+	}
+	if fileDetails.Year() > 0 {
+		basicFields["DATE"] = strconv.Itoa(fileDetails.Year())
+	}
+	if fileDetails.Track() > 0 {
+		basicFields["TRACKNUMBER"] = strconv.Itoa(fileDetails.Track())
+	}
+	for key, value := range basicFields {
+		if value != "" && len(metadata.Tags[key]) == 0 {
+			metadata.Tags[key] = []string{value}
+		}
+	}
+	if metadata.Properties.Encoder == "" {
+		metadata.Properties.Encoder = metadata.Tags.First("ENCODER")
+	}
+	return metadata, nil
+}
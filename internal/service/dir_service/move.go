@@ -0,0 +1,200 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+)
+
+// moveAudioFile checks whether a new audio file is a stored one that is missing at its recorded location, in this
+// or any other root. Exact copies are matched by sha256 and size, retagged ones by the hash of the audio payload.
+// The matched row is moved in place, so its id survives. Returns false if nothing matched
+func (s *Service) moveAudioFile(tx *sqlx.Tx, session *ScanSession, dirId int, task audioFileTask) (moved bool, err error) {
+	candidates, err := s.AudioFileService.SearchBySha256(tx, task.sha256)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", task.absolutePath).Msg("Failed to search audio files by sha256")
+		return false, err
+	}
+	for _, candidate := range candidates {
+		if candidate.SizeByte != task.fileStat.SizeByte {
+			continue
+		}
+		missing, err := s.isMissingOnDisk(tx, session, candidate.DirId, candidate.Filename)
+		if err != nil {
+			return false, err
+		}
+		if !missing {
+			continue
+		}
+
+		audioFile := candidate
+		audioFile.DirId = dirId
+		audioFile.Filename = filepath.Base(task.absolutePath)
+		audioFile.Extension = filepath.Ext(task.absolutePath)
+		audioFile.ModifiedAt = &task.fileStat.ModifiedAt
+		audioFile.Inode = task.fileStat.Inode
+		audioFile.PayloadSha256 = task.payloadSha256
+		audioFile.EmbeddedCoverId = task.prepared.EmbeddedCoverId
+		err = s.AudioFileService.UpdateLocation(tx, candidate.AudioFileId, audioFile)
+		if err != nil {
+			log.Error().Err(err).Int("audioFileId", candidate.AudioFileId).Msg("Failed to move audio file")
+			return false, err
+		}
+		session.Report.add(model.ScanReportActionMoved, model.ScanReportEntityAudioFile, candidate.AudioFileId, task.absolutePath)
+		return true, nil
+	}
+
+	if task.payloadSha256 == nil {
+		return false, nil
+	}
+	candidates, err = s.AudioFileService.SearchByPayloadSha256(tx, *task.payloadSha256)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", task.absolutePath).Msg("Failed to search audio files by payload sha256")
+		return false, err
+	}
+	for _, candidate := range candidates {
+		missing, err := s.isMissingOnDisk(tx, session, candidate.DirId, candidate.Filename)
+		if err != nil {
+			return false, err
+		}
+		if !missing {
+			continue
+		}
+
+		// Tags differ, so the details are taken from the new file
+		audioFile := task.prepared
+		audioFile.DirId = dirId
+		audioFile.Sha256 = task.sha256
+		audioFile.PayloadSha256 = task.payloadSha256
+		_, err = s.AudioFileService.Update(tx, candidate.AudioFileId, audioFile)
+		if err == nil {
+			err = s.AudioFileService.SetTags(tx, candidate.AudioFileId, task.tags)
+		}
+		if err != nil {
+			log.Error().Err(err).Int("audioFileId", candidate.AudioFileId).Msg("Failed to move audio file")
+			return false, err
+		}
+		session.Report.add(model.ScanReportActionMoved, model.ScanReportEntityAudioFile, candidate.AudioFileId, task.absolutePath)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// moveCover checks whether a new cover is a stored one that is missing at its recorded location.
+// Covers are matched by sha256 and size only. Returns false if nothing matched
+func (s *Service) moveCover(tx *sqlx.Tx, session *ScanSession, dirId int, task coverTask) (moved bool, err error) {
+	candidates, err := s.CoverService.SearchBySha256(tx, task.sha256)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", task.absolutePath).Msg("Failed to search covers by sha256")
+		return false, err
+	}
+	for _, candidate := range candidates {
+		if candidate.Source != model.CoverSourceFile || candidate.SizeByte != task.fileStat.SizeByte {
+			continue
+		}
+		missing, err := s.isMissingOnDisk(tx, session, *candidate.DirId, candidate.Filename)
+		if err != nil {
+			return false, err
+		}
+		if !missing {
+			continue
+		}
+
+		cover := candidate
+		cover.DirId = &dirId
+		cover.Filename = filepath.Base(task.absolutePath)
+		cover.Extension = filepath.Ext(task.absolutePath)
+		cover.ModifiedAt = &task.fileStat.ModifiedAt
+		cover.Inode = task.fileStat.Inode
+		cover.Kind = task.prepared.Kind
+		err = s.CoverService.UpdateLocation(tx, candidate.CoverId, cover)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", candidate.CoverId).Msg("Failed to move cover")
+			return false, err
+		}
+		session.Report.add(model.ScanReportActionMoved, model.ScanReportEntityCover, candidate.CoverId, task.absolutePath)
+
+		// Audio files of the old location may still use the cover
+		oldDir, err := s.DirRepo.Read(tx, *candidate.DirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", *candidate.DirId).Msg("Failed to read old directory of cover")
+			return false, err
+		}
+		err = s.resolveCoversAfterChange(tx, oldDir)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", *candidate.DirId).Msg("Failed to resolve covers of old directory")
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// isMissingOnDisk tells whether nothing exists at the location recorded for a file. Files of offline directories
+// are never missing, their content is kept until the directory is back or its grace period is over
+func (s *Service) isMissingOnDisk(tx *sqlx.Tx, session *ScanSession, dirId int, filename string) (missing bool, err error) {
+	location, err := s.locateDir(tx, session, dirId)
+	if err != nil {
+		return false, err
+	}
+	if location.offline {
+		return false, nil
+	}
+
+	absolutePath := filepath.Join(location.absolutePath, filename)
+	_, err = os.Lstat(absolutePath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to check file on disk")
+		return false, err
+	}
+	return false, nil
+}
+
+// locateDir returns the absolute path of the directory and whether it is offline. Results are cached in the session
+func (s *Service) locateDir(tx *sqlx.Tx, session *ScanSession, dirId int) (location dirLocation, err error) {
+	if location, ok := session.dirLocations.get(dirId); ok {
+		return location, nil
+	}
+
+	var names []string
+	currentDir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch directory from database")
+		return dirLocation{}, err
+	}
+	for {
+		names = append([]string{currentDir.Name}, names...)
+		if currentDir.OfflineSince != nil && s.isKeptOffline(*currentDir.OfflineSince) {
+			location.offline = true
+		}
+		if currentDir.ParentDirId == nil {
+			break
+		}
+		currentDir, err = s.DirRepo.Read(tx, *currentDir.ParentDirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch parent directory from database")
+			return dirLocation{}, err
+		}
+	}
+	location.absolutePath = filepath.Join(names...)
+
+	// A root that went missing is offline even before a scan of it has marked it
+	if !location.offline && currentDir.OfflineSince == nil {
+		rootExists, err := utils.IsDirectoryExistsOnDisk(currentDir.Name)
+		if err != nil {
+			return dirLocation{}, err
+		}
+		location.offline = !rootExists
+	}
+
+	session.dirLocations.put(dirId, location)
+	return location, nil
+}
@@ -0,0 +1,114 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"time"
+)
+
+// isOffline tells whether the directory looks unavailable rather than deleted: a root that is missing on disk,
+// or a root or a known mount point that is empty on disk while its content is still stored, like a mount point
+// without its disk. Any other directory found empty was emptied
+func (s *Service) isOffline(tx *sqlx.Tx, dir model.Directory, absolutePath string, existsOnDisk bool) (offline bool, err error) {
+	if !existsOnDisk {
+		return dir.ParentDirId == nil, nil
+	}
+
+	entries, err := os.ReadDir(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read directory from disk")
+		return false, err
+	}
+	if len(entries) > 0 || (dir.ParentDirId != nil && !dir.MountPoint) {
+		return false, nil
+	}
+	return s.hasStoredContent(tx, dir.DirId)
+}
+
+func (s *Service) hasStoredContent(tx *sqlx.Tx, dirId int) (hasContent bool, err error) {
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get subdirectories")
+		return false, err
+	}
+	audioFiles, err := s.AudioFileService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's audio files")
+		return false, err
+	}
+	covers, err := s.CoverService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's covers")
+		return false, err
+	}
+	return len(subDirs) > 0 || len(audioFiles) > 0 || len(covers) > 0, nil
+}
+
+// keepOffline marks the directory offline and leaves its content untouched. Once the grace period is over
+// the content is left for the deletion at the end of the scan, so files found elsewhere still keep their ids
+func (s *Service) keepOffline(tx *sqlx.Tx, session *ScanSession, dir model.Directory, absolutePath string) (err error) {
+	// Locations cached for move detection don't know the directory is offline
+	session.dirLocations.reset()
+	if dir.OfflineSince == nil {
+		log.Warn().Int("dirId", dir.DirId).Str("absolutePath", absolutePath).Msg("Directory is offline, its content is kept")
+		now := time.Now()
+		return s.DirRepo.UpdateOfflineSince(tx, dir.DirId, &now)
+	}
+
+	if s.isKeptOffline(*dir.OfflineSince) {
+		log.Info().Int("dirId", dir.DirId).Time("offlineSince", *dir.OfflineSince).Msg("Directory is still offline")
+		return nil
+	}
+
+	log.Warn().Int("dirId", dir.DirId).Time("offlineSince", *dir.OfflineSince).Msg("Grace period of offline directory is over, purging its content")
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dir.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get subdirectories")
+		return err
+	}
+	for _, subDir := range subDirs {
+		session.PendingDeletions.add(model.ScanReportEntityDirectory, subDir.DirId)
+	}
+	audioFiles, err := s.AudioFileService.GetAllByDir(tx, dir.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get directory's audio files")
+		return err
+	}
+	for _, audioFile := range audioFiles {
+		session.PendingDeletions.add(model.ScanReportEntityAudioFile, audioFile.AudioFileId)
+	}
+	covers, err := s.CoverService.GetAllByDir(tx, dir.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get directory's covers")
+		return err
+	}
+	for _, cover := range covers {
+		session.PendingDeletions.add(model.ScanReportEntityCover, cover.CoverId)
+	}
+	return nil
+}
+
+// isKeptOffline tells whether the content of a directory offline since the time is still kept
+func (s *Service) isKeptOffline(offlineSince time.Time) (kept bool) {
+	return s.offlineGracePeriod <= 0 || time.Since(offlineSince) < s.offlineGracePeriod
+}
+
+// updateMountPoint remembers whether a filesystem is mounted at the directory, so it is known once the filesystem
+// is gone. A directory that can't be examined keeps its state
+func (s *Service) updateMountPoint(tx *sqlx.Tx, dir model.Directory, absolutePath string) (err error) {
+	if dir.ParentDirId == nil {
+		return nil
+	}
+	mountPoint, err := utils.IsMountPoint(absolutePath)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to check whether directory is a mount point")
+		return nil
+	}
+	if mountPoint == dir.MountPoint {
+		return nil
+	}
+	return s.DirRepo.UpdateMountPoint(tx, dir.DirId, mountPoint)
+}
@@ -0,0 +1,51 @@
+package dir_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+)
+
+// PurgeOffline deletes everything stored below an offline directory without waiting for the grace period.
+// The directory itself stays, so a root remains tracked and is scanned again once its disk is back
+func (s *Service) PurgeOffline(tx *sqlx.Tx, dirId int) (err error) {
+	log.Debug().Int("dirId", dirId).Msg("Purging content of offline directory")
+
+	dir, err := s.GetDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory")
+		return err
+	}
+	if dir.OfflineSince == nil {
+		err = errors.Conflict{Message: fmt.Sprintf("directory with id=%d is not offline", dirId)}
+		log.Error().Err(err).Int("dirId", dirId).Msg("Directory can't be purged")
+		return err
+	}
+
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get subdirectories")
+		return err
+	}
+	for _, subDir := range subDirs {
+		err = s.DeleteDir(tx, subDir.DirId)
+		if err != nil {
+			log.Error().Err(err).Int("subDirId", subDir.DirId).Msg("Failed to delete subdirectory")
+			return err
+		}
+	}
+	err = s.deleteContent(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to delete files of directory")
+		return err
+	}
+	err = s.resolveCoversAfterChange(tx, dir)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to resolve covers after purging directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Content of offline directory purged successfully")
+	return nil
+}
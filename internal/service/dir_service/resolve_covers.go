@@ -0,0 +1,234 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveCovers stores the cover of every audio file in the directory, so it is read with a single query.
+// A cover pinned to the audio file or to the directory goes before anything found on disk.
+// Then a track picture like "01 - Title.jpg" next to "01 - Title.flac" goes first, then the best front cover of the
+// directory or of its nearest ancestor that has one (or has a pinned cover), so discs of an album share its cover. The picture embedded
+// in the file goes first or is the last resort, depending on the configuration.
+// With withSubDirs the directories below are resolved too, they fall back to covers of this one
+func (s *Service) ResolveCovers(tx *sqlx.Tx, dirId int, withSubDirs bool) (err error) {
+	log.Debug().Int("dirId", dirId).Bool("withSubDirs", withSubDirs).Msg("Resolving covers of audio files in directory")
+
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read directory")
+		return err
+	}
+	audioFiles, err := s.AudioFileService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's audio files")
+		return err
+	}
+	if len(audioFiles) > 0 {
+		err = s.resolveAudioFileCovers(tx, dirId, audioFiles)
+		if err != nil {
+			return err
+		}
+	}
+	if !dir.CoversResolved {
+		err = s.DirRepo.UpdateCoversResolved(tx, dirId, true)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to mark covers of directory resolved")
+			return err
+		}
+	}
+
+	if withSubDirs {
+		subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get subdirectories")
+			return err
+		}
+		for _, subDir := range subDirs {
+			err = s.ResolveCovers(tx, subDir.DirId, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfAudioFiles", len(audioFiles)).Msg("Covers of audio files in directory resolved successfully")
+	return nil
+}
+
+// resolveCoversAfterChange resolves again the audio files that may have used a changed cover of the directory:
+// the ones in it and below it, or the ones of its parent if it is an artwork subfolder
+func (s *Service) resolveCoversAfterChange(tx *sqlx.Tx, dir model.Directory) (err error) {
+	if dir.ParentDirId != nil && s.CoverService.IsSubfolder(dir.Name) {
+		return s.ResolveCovers(tx, *dir.ParentDirId, true)
+	}
+	return s.ResolveCovers(tx, dir.DirId, true)
+}
+
+func (s *Service) resolveAudioFileCovers(tx *sqlx.Tx, dirId int, audioFiles []model.AudioFile) (err error) {
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read directory")
+		return err
+	}
+	covers, err := s.CoverService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get covers in directory")
+		return err
+	}
+	trackCoverIds := make(map[string]int)
+	for _, cover := range covers {
+		trackCoverIds[trackStem(cover.Filename)] = cover.CoverId
+	}
+
+	dirOverrideId := dir.CoverOverrideId
+	var dirCoverId *int
+	for {
+		if dir.CoverOverrideId != nil {
+			dirCoverId = dir.CoverOverrideId
+			break
+		}
+		cover, found, err := s.bestFrontCover(tx, dir)
+		if err != nil {
+			return err
+		}
+		if found {
+			dirCoverId = &cover.CoverId
+			break
+		}
+		if dir.ParentDirId == nil {
+			break
+		}
+		dir, err = s.DirRepo.Read(tx, *dir.ParentDirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read parent directory")
+			return err
+		}
+	}
+
+	for _, audioFile := range audioFiles {
+		coverId := dirCoverId
+		if audioFile.CoverOverrideId != nil {
+			coverId = audioFile.CoverOverrideId
+		} else if dirOverrideId != nil {
+			coverId = dirOverrideId
+		} else if trackCoverId, ok := trackCoverIds[trackStem(audioFile.Filename)]; ok {
+			coverId = &trackCoverId
+		} else if audioFile.EmbeddedCoverId != nil && (coverId == nil || s.CoverService.PreferEmbedded()) {
+			coverId = audioFile.EmbeddedCoverId
+		}
+		if sameCoverId(coverId, audioFile.CoverId) {
+			continue
+		}
+
+		err = s.AudioFileService.UpdateCover(tx, audioFile.AudioFileId, coverId)
+		if err != nil {
+			log.Error().Err(err).Int("audioFileId", audioFile.AudioFileId).Msg("Failed to store cover of audio file")
+			return err
+		}
+	}
+	return nil
+}
+
+// coverCandidate is a front cover with what it is compared by
+type coverCandidate struct {
+	cover model.Cover
+	// Covers of the directory itself go before the ones of its artwork subfolders
+	inSubfolder bool
+	rank        int
+}
+
+func (c coverCandidate) isBetterThan(other coverCandidate) bool {
+	if c.inSubfolder != other.inSubfolder {
+		return !c.inSubfolder
+	}
+	if c.rank != other.rank {
+		return c.rank < other.rank
+	}
+	area, otherArea := c.cover.WidthPx*c.cover.HeightPx, other.cover.WidthPx*other.cover.HeightPx
+	if area != otherArea {
+		return area > otherArea
+	}
+	return c.cover.Filename < other.cover.Filename
+}
+
+// bestFrontCover looks for front covers in the directory and its artwork subfolders.
+// Track pictures belong to their audio files and are skipped
+func (s *Service) bestFrontCover(tx *sqlx.Tx, dir model.Directory) (cover model.Cover, found bool, err error) {
+	var candidates []coverCandidate
+
+	trackStems, err := s.trackStems(tx, dir.DirId)
+	if err != nil {
+		return model.Cover{}, false, err
+	}
+	covers, err := s.CoverService.GetAllByDir(tx, dir.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get covers in directory")
+		return model.Cover{}, false, err
+	}
+	for _, cover := range covers {
+		if trackStems[trackStem(cover.Filename)] {
+			continue
+		}
+		candidates = append(candidates, coverCandidate{cover: cover, rank: s.CoverService.Rank(filepath.Base(dir.Name), cover)})
+	}
+
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dir.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get subdirectories")
+		return model.Cover{}, false, err
+	}
+	for _, subDir := range subDirs {
+		if !s.CoverService.IsSubfolder(subDir.Name) {
+			continue
+		}
+		covers, err = s.CoverService.GetAllByDir(tx, subDir.DirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", subDir.DirId).Msg("Failed to get covers in artwork subfolder")
+			return model.Cover{}, false, err
+		}
+		for _, cover := range covers {
+			candidates = append(candidates, coverCandidate{cover: cover, inSubfolder: true, rank: s.CoverService.Rank(subDir.Name, cover)})
+		}
+	}
+
+	var best coverCandidate
+	for _, candidate := range candidates {
+		if candidate.cover.Kind != model.CoverKindFront {
+			continue
+		}
+		if !found || candidate.isBetterThan(best) {
+			best, found = candidate, true
+		}
+	}
+	return best.cover, found, nil
+}
+
+// trackStems returns names of the directory's audio files without extensions, see trackStem
+func (s *Service) trackStems(tx *sqlx.Tx, dirId int) (stems map[string]bool, err error) {
+	audioFiles, err := s.AudioFileService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's audio files")
+		return nil, err
+	}
+	stems = make(map[string]bool)
+	for _, audioFile := range audioFiles {
+		stems[trackStem(audioFile.Filename)] = true
+	}
+	return stems, nil
+}
+
+// trackStem is the filename without extension in lower case. A picture with the stem of an audio file is its track picture
+func trackStem(filename string) (stem string) {
+	return strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+}
+
+func sameCoverId(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,62 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"os"
+	"path/filepath"
+)
+
+// ResolvePath returns where a file of the directory really is on disk. Symlinks to directories on the way below
+// the root are allowed as far as the symlink policy of the root allows them, otherwise the file is not found.
+// The file itself may be a symlink, scans index those under every policy
+func (s *Service) ResolvePath(tx *sqlx.Tx, dirId int, filename string) (resolvedPath string, err error) {
+	log.Debug().Int("dirId", dirId).Str("filename", filename).Msg("Resolving path to file")
+
+	absolutePathToDir, err := s.AbsolutePath(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to calculate absolute path to directory")
+		return "", err
+	}
+	root, _, err := s.rootOf(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to find root of directory")
+		return "", err
+	}
+
+	absolutePath := filepath.Join(absolutePathToDir, filename)
+	resolvedPath, err = filepath.EvalSymlinks(absolutePath)
+	if os.IsNotExist(err) {
+		log.Error().Str("absolutePath", absolutePath).Msg("File not found on disk")
+		return "", errors.NotFound{Resource: "file on disk"}
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to resolve symlinks in path")
+		return "", err
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(absolutePathToDir)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePathToDir).Msg("Failed to resolve symlinks in path to directory")
+		return "", err
+	}
+	resolvedRoot := ResolveOrKeep(root.Name)
+	allowed := true
+	switch root.SymlinkPolicyOrDefault() {
+	case model.SymlinkPolicyIgnore:
+		// The scan doesn't follow links to directories, so the directory has to be exactly where its path says
+		relativePath, err := filepath.Rel(root.Name, absolutePathToDir)
+		allowed = err == nil && resolvedDir == filepath.Join(resolvedRoot, relativePath)
+	case model.SymlinkPolicyWithinRoot:
+		allowed = isWithin(resolvedDir, resolvedRoot)
+	}
+	if !allowed {
+		log.Error().Str("absolutePath", absolutePath).Str("resolvedPath", resolvedPath).Msg("Symlink policy of root doesn't allow path")
+		return "", errors.NotFound{Resource: "file on disk"}
+	}
+
+	log.Debug().Int("dirId", dirId).Str("resolvedPath", resolvedPath).Msg("Path to file resolved successfully")
+	return resolvedPath, nil
+}
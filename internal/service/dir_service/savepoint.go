@@ -0,0 +1,35 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// inFileSavepoint runs the database writes of a single file under a savepoint. When do fails, its writes and
+// report entries are rolled back and the transaction stays usable for the other files of the directory.
+// failure is the error of do, err is set only when the savepoint itself fails
+func (s *Service) inFileSavepoint(tx *sqlx.Tx, session *ScanSession, do func() (err error)) (failure error, err error) {
+	_, err = tx.Exec("SAVEPOINT scan_file")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create savepoint")
+		return nil, err
+	}
+	reportMark := session.Report.mark()
+
+	failure = do()
+	if failure != nil {
+		session.Report.rollbackTo(reportMark)
+		_, err = tx.Exec("ROLLBACK TO SAVEPOINT scan_file")
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to roll back to savepoint")
+			return failure, err
+		}
+	}
+
+	_, err = tx.Exec("RELEASE SAVEPOINT scan_file")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to release savepoint")
+		return failure, err
+	}
+	return failure, nil
+}
@@ -1,113 +1,174 @@
 package dir_service
 
 import (
+	"bytes"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
-	"github.com/wtolson/go-taglib"
 	"image"
 	"music-files/internal/errors"
 	"music-files/internal/model"
 	"music-files/internal/utils"
 	"os"
 	"path/filepath"
-	"strings"
-	"time"
-
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
 )
 
-func (s *Service) Scan(tx *sqlx.Tx, dirId int) (err error) {
+// ScanDir brings a single directory in line with the disk without descending into it.
+// Returns subdirectories that have to be scanned next according to the session options
+func (s *Service) ScanDir(tx *sqlx.Tx, session *ScanSession, dirId int) (subDirIds []int, err error) {
 	log.Debug().Int("dirId", dirId).Msg("Scanning directory")
 
+	if err = session.Ctx.Err(); err != nil {
+		log.Debug().Int("dirId", dirId).Err(err).Msg("Scan interrupted")
+		return nil, err
+	}
+
 	existsInDatabase, err := s.DirRepo.IsExists(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to check directory existence")
-		return err
+		return nil, err
 	}
 	if !existsInDatabase {
 		log.Error().Int("dirId", dirId).Msg("Directory not found")
-		return errors.NotFound{Resource: "directory in database"}
+		return nil, errors.NotFound{Resource: "directory in database"}
 	}
 
 	absolutePath, err := s.AbsolutePath(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to calculate absolute path to directory")
-		return err
+		return nil, err
+	}
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read directory")
+		return nil, err
 	}
 	existsOnDisk, err := utils.IsDirectoryExistsOnDisk(absolutePath)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to check directory existence on disk")
-		return err
+		return nil, err
+	}
+	// A missing root or an empty directory that used to have content is most likely an unmounted disk
+	offline, err := s.isOffline(tx, dir, absolutePath, existsOnDisk)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to check whether directory is offline")
+		return nil, err
+	}
+	if offline {
+		err = s.keepOffline(tx, session, dir, absolutePath)
+		if err != nil {
+			log.Error().Int("dirId", dirId).Err(err).Msg("Failed to handle offline directory")
+			return nil, err
+		}
+		return nil, nil
 	}
 	if !existsOnDisk {
-		err = s.DeleteDir(tx, dirId)
+		session.PendingDeletions.add(model.ScanReportEntityDirectory, dirId)
+		return nil, nil
+	}
+	if dir.OfflineSince != nil {
+		log.Info().Int("dirId", dirId).Str("absolutePath", absolutePath).Msg("Directory is back online")
+		session.dirLocations.reset()
+		err = s.DirRepo.UpdateOfflineSince(tx, dirId, nil)
 		if err != nil {
-			log.Error().Int("dirId", dirId).Err(err).Msg("Failed to delete directory")
-			return err
+			log.Error().Int("dirId", dirId).Err(err).Msg("Failed to mark directory online")
+			return nil, err
 		}
-		return nil
 	}
-
-	err = s.actualizeSubDirs(tx, dirId)
+	err = s.updateMountPoint(tx, dir, absolutePath)
 	if err != nil {
-		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to actualize subdirectories")
-		return err
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to update mount point state of directory")
+		return nil, err
 	}
+	session.Progress.dirVisited(absolutePath)
 
-	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
+	// Errors found by this scan replace the previous ones, so fixed files disappear from the registry
+	err = s.ScanErrorService.ClearDir(tx, dirId)
 	if err != nil {
-		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read subdirectories")
-		return err
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to clear scan errors")
+		return nil, err
 	}
 
-	for _, subDir := range subDirs {
-		err = s.Scan(tx, subDir.DirId)
-		if err != nil {
-			log.Error().Int("subDirId", subDir.DirId).Err(err).Msg("Failed to scan subdirectory")
-			return err
-		}
+	entries, err := os.ReadDir(absolutePath)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read directory from disk")
+		return nil, err
 	}
-	err = s.scanContent(tx, dirId)
+	ignored, err := s.ignoredEntries(tx, session, dirId, entries)
 	if err != nil {
-		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to scan directory's content")
-		return err
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to apply ignore rules")
+		return nil, err
+	}
+	linkedDirs, err := s.traversedEntries(tx, session, dirId, absolutePath, entries, ignored)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to apply traversal rules")
+		return nil, err
 	}
 
-	log.Debug().Int("dirId", dirId).Msg("Directory scanned successfully")
-	return nil
-}
+	createdSubDirIds, missingSubDirIds, err := s.actualizeSubDirs(tx, session, dirId, absolutePath, entries, ignored, linkedDirs)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to actualize subdirectories")
+		return nil, err
+	}
 
-func (s *Service) actualizeSubDirs(tx *sqlx.Tx, dirId int) (err error) {
-	absolutePath, err := s.AbsolutePath(tx, dirId)
+	coversChanged, err := s.scanContent(tx, session, dirId, ignored)
 	if err != nil {
-		log.Error().Int("dirId", dirId).Msg("failed to actualize subdirectories")
-		return err
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to scan directory's content")
+		return nil, err
+	}
+	if coversChanged {
+		err = s.resolveCoversAfterChange(tx, dir)
+	} else {
+		err = s.ResolveCovers(tx, dirId, false)
+	}
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to resolve covers of audio files")
+		return nil, err
 	}
 
-	entries, err := os.ReadDir(absolutePath)
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
 	if err != nil {
-		log.Error().Str("absolutePath", absolutePath).Msg("Failed to read directory from disk")
-		return err
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read subdirectories")
+		return nil, err
 	}
+	for _, subDir := range subDirs {
+		if missingSubDirIds[subDir.DirId] {
+			continue
+		}
+		if session.Options.Recursive || createdSubDirIds[subDir.DirId] {
+			subDirIds = append(subDirIds, subDir.DirId)
+		}
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfSubDirsToScan", len(subDirIds)).Msg("Directory scanned successfully")
+	return subDirIds, nil
+}
+
+// actualizeSubDirs brings subdirectories in the database in line with the disk. Returns ids of created ones
+// and ids of the ones missing on disk, which are left for the deletion at the end of the scan.
+// Ignored subdirectories are not created, stored ones are deleted right away. Symlinks in linkedDirs count as subdirectories.
+// entries are the listing that ignored and linkedDirs were computed from, so all three agree
+func (s *Service) actualizeSubDirs(tx *sqlx.Tx, session *ScanSession, dirId int, absolutePath string, entries []os.DirEntry, ignored map[string]bool, linkedDirs map[string]bool) (createdSubDirIds map[int]bool, missingSubDirIds map[int]bool, err error) {
+	createdSubDirIds = make(map[int]bool)
+	missingSubDirIds = make(map[int]bool)
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if (entry.IsDir() || linkedDirs[entry.Name()]) && !ignored[entry.Name()] {
 			alreadyInDatabase, err := s.DirRepo.IsExistsByParentAndName(tx, &dirId, entry.Name())
 			if err != nil {
 				log.Error().Int("dirId", dirId).Msg("Failed to check directory existence")
-				return err
+				return nil, nil, err
 			}
 			if !alreadyInDatabase {
-				_, err = s.DirRepo.Create(tx, model.Directory{
+				createdSubDirId, err := s.DirRepo.Create(tx, model.Directory{
 					ParentDirId: &dirId,
 					Name:        entry.Name(),
 				})
 				if err != nil {
 					log.Error().Int("dirId", dirId).Msg("Failed to create directory")
-					return err
+					return nil, nil, err
 				}
+				createdSubDirIds[createdSubDirId] = true
+				session.Report.add(model.ScanReportActionCreated, model.ScanReportEntityDirectory, createdSubDirId, filepath.Join(absolutePath, entry.Name()))
 			}
 		}
 	}
@@ -115,48 +176,58 @@ func (s *Service) actualizeSubDirs(tx *sqlx.Tx, dirId int) (err error) {
 	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to read subdirectories")
-		return err
+		return nil, nil, err
 	}
 	for _, subDir := range subDirs {
 		foundDirOnDisk := false
 
 		for _, entry := range entries {
-			if entry.IsDir() {
+			if entry.IsDir() || linkedDirs[entry.Name()] {
 				if subDir.Name == entry.Name() {
 					foundDirOnDisk = true
 				}
 			}
 		}
 
-		if !foundDirOnDisk {
+		if ignored[subDir.Name] {
+			err = s.reportDeletedDir(tx, session, subDir.DirId, filepath.Join(absolutePath, subDir.Name))
+			if err != nil {
+				log.Error().Int("dirId", dirId).Msg("Failed to report deleted directory")
+				return nil, nil, err
+			}
 			err = s.DeleteDir(tx, subDir.DirId)
 			if err != nil {
-				log.Error().Int("dirId", dirId).Msg("Failed to delete directory from disk")
-				return err
+				log.Error().Int("dirId", dirId).Msg("Failed to delete ignored directory")
+				return nil, nil, err
 			}
+		} else if !foundDirOnDisk {
+			missingSubDirIds[subDir.DirId] = true
+			session.PendingDeletions.add(model.ScanReportEntityDirectory, subDir.DirId)
 		}
 	}
 
-	return nil
+	return createdSubDirIds, missingSubDirIds, nil
 }
 
-func (s *Service) scanContent(tx *sqlx.Tx, dirId int) (err error) {
-	err = s.actualizeAudioFiles(tx, dirId)
+// scanContent actualizes audio files and covers of the directory. coversChanged tells whether
+// covers were created, updated, moved in, reclassified or deleted
+func (s *Service) scanContent(tx *sqlx.Tx, session *ScanSession, dirId int, ignored map[string]bool) (coversChanged bool, err error) {
+	err = s.actualizeAudioFiles(tx, session, dirId, ignored)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to actualize audio files")
-		return err
+		return false, err
 	}
 
-	err = s.actualizeCovers(tx, dirId)
+	coversChanged, err = s.actualizeCovers(tx, session, dirId, ignored)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to actualize covers")
-		return err
+		return false, err
 	}
 
-	return nil
+	return coversChanged, nil
 }
 
-func (s *Service) actualizeAudioFiles(tx *sqlx.Tx, dirId int) (err error) {
+func (s *Service) actualizeAudioFiles(tx *sqlx.Tx, session *ScanSession, dirId int, ignored map[string]bool) (err error) {
 	absolutePath, err := s.AbsolutePath(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to calculate absolute path to directory")
@@ -169,67 +240,96 @@ func (s *Service) actualizeAudioFiles(tx *sqlx.Tx, dirId int) (err error) {
 		return err
 	}
 
+	// Files that can't be examined are kept in the database as they are
+	presentOnDisk := make(map[string]bool)
+	var tasks []audioFileTask
 	for _, entry := range entries {
+		if err = session.Ctx.Err(); err != nil {
+			return err
+		}
+		if ignored[entry.Name()] {
+			continue
+		}
+
 		fileAbsolutePath := filepath.Join(absolutePath, entry.Name())
 		isMusicFile, err := utils.IsMusicFile(fileAbsolutePath)
 		if err != nil {
-			log.Error().Int("dirId", dirId).Msg("Failed to check entry's type")
-			return err
+			presentOnDisk[entry.Name()] = true
+			err = s.recordFileError(tx, dirId, fileAbsolutePath, model.ScanErrorStageDetect, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !isMusicFile {
+			continue
 		}
-		if isMusicFile {
-			sha256OnDisk, err := utils.CalculateSha256(fileAbsolutePath)
+		presentOnDisk[entry.Name()] = true
+
+		fileStat, err := utils.StatFile(fileAbsolutePath)
+		if err != nil {
+			err = s.recordFileError(tx, dirId, fileAbsolutePath, model.ScanErrorStageStat, err)
 			if err != nil {
-				log.Error().Int("dirId", dirId).Msg("Failed to calculate sha256")
 				return err
 			}
+			continue
+		}
+		task := audioFileTask{
+			absolutePath: fileAbsolutePath,
+			fileStat:     fileStat,
+		}
 
-			alreadyInDatabase, err := s.AudioFileService.IsExistsByDirAndName(tx, dirId, entry.Name())
+		task.exists, err = s.AudioFileService.IsExistsByDirAndName(tx, dirId, entry.Name())
+		if err != nil {
+			log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to check music file existence")
+			return err
+		}
+		if task.exists {
+			task.existing, err = s.AudioFileService.GetByDirAndName(tx, dirId, entry.Name())
 			if err != nil {
 				log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to check music file existence")
 				return err
 			}
+			if !session.Options.DeepVerify && fileStat.Matches(task.existing.SizeByte, task.existing.ModifiedAt, task.existing.Inode) {
+				continue
+			}
+		}
+		tasks = append(tasks, task)
+	}
 
-			if alreadyInDatabase {
-				audioFile, err := s.AudioFileService.GetByDirAndName(tx, dirId, entry.Name())
-				if err != nil {
-					log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to check music file existence")
-					return err
-				}
-				sha256InDatabase := audioFile.Sha256
-
-				if sha256OnDisk == sha256InDatabase {
-					continue
-				}
-
-				audioFileToUpdate, err := s.prepareAudioFileByAbsolutePath(fileAbsolutePath)
-				if err != nil {
-					log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to prepare audio file")
-					return err
-				}
-				audioFileToUpdate.DirId = dirId
-				audioFileToUpdate.Sha256 = sha256OnDisk
-
-				_, err = s.AudioFileService.Update(tx, audioFile.AudioFileId, audioFileToUpdate)
-				if err != nil {
-					log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to update audio file")
-					return err
-				}
-			} else {
-				audioFileToCreate, err := s.prepareAudioFileByAbsolutePath(fileAbsolutePath)
-				if err != nil {
-					log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to prepare audio file")
-					return err
-				}
-				audioFileToCreate.DirId = dirId
-				audioFileToCreate.Sha256 = sha256OnDisk
+	// Files are read on the worker pool, the database is written sequentially within the transaction
+	err = s.pool.run(session.Ctx, len(tasks), func(i int) (err error) {
+		s.readAudioFileTask(session, &tasks[i])
+		return nil
+	})
+	if err == nil {
+		err = session.Ctx.Err()
+	}
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read audio files")
+		return err
+	}
 
-				_, err = s.AudioFileService.Create(tx, audioFileToCreate)
-				if err != nil {
-					log.Error().Int("dirId", dirId).Str("entryName", entry.Name()).Msg("Failed to create audio file")
-					return err
-				}
+	for _, task := range tasks {
+		if task.failure != nil {
+			err = s.recordFileError(tx, dirId, task.absolutePath, task.failedStage, task.failure)
+			if err != nil {
+				return err
 			}
+			continue
+		}
 
+		failure, err := s.inFileSavepoint(tx, session, func() (err error) {
+			return s.storeAudioFileTask(tx, session, dirId, task)
+		})
+		if err != nil {
+			return err
+		}
+		if failure != nil {
+			err = s.recordFileError(tx, dirId, task.absolutePath, model.ScanErrorStageStore, failure)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -240,211 +340,573 @@ func (s *Service) actualizeAudioFiles(tx *sqlx.Tx, dirId int) (err error) {
 	}
 
 	for _, audioFile := range audioFiles {
-		foundOnDisk := false
-
-		for _, entry := range entries {
-			fileAbsolutePath := filepath.Join(absolutePath, entry.Name())
-			isMusicFile, err := utils.IsMusicFile(fileAbsolutePath)
+		if ignored[audioFile.Filename] {
+			err = s.AudioFileService.Delete(tx, audioFile.AudioFileId)
 			if err != nil {
-				log.Error().Str("fileAbsolutePath", fileAbsolutePath).Msg("Failed to check entry's type")
+				log.Error().Int("dirId", dirId).Msg("Failed to delete ignored audio file")
 				return err
 			}
-
-			if isMusicFile {
-				if audioFile.Filename == entry.Name() {
-					foundOnDisk = true
-				}
-			}
+			session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityAudioFile, audioFile.AudioFileId, filepath.Join(absolutePath, audioFile.Filename))
+		} else if !presentOnDisk[audioFile.Filename] {
+			session.PendingDeletions.add(model.ScanReportEntityAudioFile, audioFile.AudioFileId)
 		}
+	}
 
-		if !foundOnDisk {
-			err = s.AudioFileService.Delete(tx, audioFile.AudioFileId)
+	return nil
+}
+
+// storeAudioFileTask writes an audio file read from disk to the database
+func (s *Service) storeAudioFileTask(tx *sqlx.Tx, session *ScanSession, dirId int, task audioFileTask) (err error) {
+	embeddedCoverId, err := s.storeEmbeddedCover(tx, session, task)
+	if err != nil {
+		log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to store embedded cover")
+		return err
+	}
+
+	if !task.contentChanged {
+		audioFile := task.existing
+		audioFile.SizeByte = task.fileStat.SizeByte
+		audioFile.ModifiedAt = &task.fileStat.ModifiedAt
+		audioFile.Inode = task.fileStat.Inode
+		audioFile.PayloadSha256 = task.payloadSha256
+		audioFile.EmbeddedCoverId = embeddedCoverId
+		err = s.AudioFileService.UpdateFileStat(tx, audioFile.AudioFileId, audioFile)
+		if err != nil {
+			log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to update file stat of audio file")
+			return err
+		}
+		if task.metadataRefreshed {
+			err = s.AudioFileService.UpdateTags(tx, audioFile.AudioFileId, task.prepared)
+			if err == nil {
+				err = s.AudioFileService.SetTags(tx, audioFile.AudioFileId, task.tags)
+			}
+			if err == nil {
+				err = s.AudioFileService.UpdateProperties(tx, audioFile.AudioFileId, task.prepared)
+			}
 			if err != nil {
-				log.Error().Int("dirId", dirId).Msg("Failed to delete audio file")
+				log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to update metadata of audio file")
 				return err
 			}
 		}
+		return nil
 	}
 
+	task.prepared.EmbeddedCoverId = embeddedCoverId
+	if !task.exists {
+		moved, err := s.moveAudioFile(tx, session, dirId, task)
+		if err != nil {
+			log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to look for moved audio file")
+			return err
+		}
+		if moved {
+			return nil
+		}
+	}
+
+	audioFile := task.prepared
+	audioFile.DirId = dirId
+	audioFile.Sha256 = task.sha256
+	audioFile.PayloadSha256 = task.payloadSha256
+	if task.exists {
+		_, err = s.AudioFileService.Update(tx, task.existing.AudioFileId, audioFile)
+		if err == nil {
+			err = s.AudioFileService.SetTags(tx, task.existing.AudioFileId, task.tags)
+		}
+		if err != nil {
+			log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to update audio file")
+			return err
+		}
+		session.Report.add(model.ScanReportActionUpdated, model.ScanReportEntityAudioFile, task.existing.AudioFileId, task.absolutePath)
+	} else {
+		createdAudioFile, err := s.AudioFileService.Create(tx, audioFile)
+		if err == nil {
+			err = s.AudioFileService.SetTags(tx, createdAudioFile.AudioFileId, task.tags)
+		}
+		if err != nil {
+			log.Error().Int("dirId", dirId).Str("absolutePath", task.absolutePath).Msg("Failed to create audio file")
+			return err
+		}
+		session.Report.add(model.ScanReportActionCreated, model.ScanReportEntityAudioFile, createdAudioFile.AudioFileId, task.absolutePath)
+	}
 	return nil
 }
 
-func (s *Service) prepareAudioFileByAbsolutePath(absolutePath string) (audioFile model.AudioFile, err error) {
-	fileInfo, err := os.Stat(absolutePath)
+// audioFileTask is an audio file that has to be read from disk during a scan
+type audioFileTask struct {
+	absolutePath string
+	fileStat     utils.FileStat
+	// Set when the file is already in the database, existing then holds its row
+	exists   bool
+	existing model.AudioFile
+
+	// Filled by readAudioFileTask
+	sha256 string
+	// Hash of the audio data without tags, nil for formats whose tags are not recognized
+	payloadSha256  *string
+	contentChanged bool
+	prepared       model.AudioFile
+	// All tag fields of the file, stored along with prepared
+	tags utils.Tags
+	// Set when the content didn't change but a deep verification read the tags and properties again into prepared
+	metadataRefreshed bool
+	// Best picture from the file's tags, nil if it has none
+	embeddedCover *embeddedCover
+	// Set when the file can't be read, failedStage tells at which step
+	failure     error
+	failedStage string
+}
+
+// embeddedCover is a picture extracted from an audio file, ready to be stored
+type embeddedCover struct {
+	cover model.Cover
+	data  []byte
+}
+
+// readAudioFileTask hashes the file and reads its details if the content changed. Safe to call concurrently
+func (s *Service) readAudioFileTask(session *ScanSession, task *audioFileTask) {
+	var err error
+	task.sha256, task.payloadSha256, err = s.calculateSha256(session, task.absolutePath, task.fileStat, true)
 	if err != nil {
-		log.Error().Str("absolutePath", absolutePath).Msg("Failed to get file info")
-		return model.AudioFile{}, err
+		task.failure, task.failedStage = err, model.ScanErrorStageHash
+		return
+	}
+	// Read whenever the file is hashed, so a deep verification finds pictures of files scanned before
+	task.embeddedCover = s.readEmbeddedCover(task.absolutePath)
+	if task.exists && task.sha256 == task.existing.Sha256 {
+		if session.Options.DeepVerify {
+			// Fills tags and properties of files scanned before they were stored
+			task.prepared, task.tags, err = s.prepareAudioFileByAbsolutePath(task.absolutePath, task.fileStat)
+			if err != nil {
+				task.failure, task.failedStage = err, model.ScanErrorStageTags
+				return
+			}
+			task.metadataRefreshed = true
+		}
+		return
 	}
 
-	fileDetails, err := taglib.Read(absolutePath)
+	task.contentChanged = true
+	task.prepared, task.tags, err = s.prepareAudioFileByAbsolutePath(task.absolutePath, task.fileStat)
 	if err != nil {
-		log.Error().Str("absolutePath", absolutePath).Msg("Failed to read file details")
-		return model.AudioFile{}, err
+		task.failure, task.failedStage = err, model.ScanErrorStageTags
 	}
+}
 
-	durationMs := int64(fileDetails.Length() / time.Millisecond)
-
-	audioFile = model.AudioFile{
-		Filename:     fileInfo.Name(),
-		Extension:    filepath.Ext(absolutePath),
-		SizeByte:     fileInfo.Size(),
-		DurationMs:   durationMs,
-		BitrateKbps:  fileDetails.Bitrate(),
-		SampleRateHz: fileDetails.Samplerate(),
-		ChannelsN:    fileDetails.Channels(),
+func (s *Service) prepareAudioFileByAbsolutePath(absolutePath string, fileStat utils.FileStat) (audioFile model.AudioFile, tags utils.Tags, err error) {
+	metadata, err := s.metadataReader.Read(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read file details")
+		return model.AudioFile{}, nil, err
 	}
+	tags = metadata.Tags
 
-	return audioFile, nil
+	audioFile = model.AudioFile{
+		Filename:      filepath.Base(absolutePath),
+		Extension:     filepath.Ext(absolutePath),
+		SizeByte:      fileStat.SizeByte,
+		DurationMs:    metadata.Properties.Duration.Milliseconds(),
+		BitrateKbps:   metadata.Properties.BitrateKbps,
+		SampleRateHz:  metadata.Properties.SampleRateHz,
+		ChannelsN:     metadata.Properties.ChannelsN,
+		ModifiedAt:    &fileStat.ModifiedAt,
+		Inode:         fileStat.Inode,
+		Title:         optionalString(tags.First("TITLE")),
+		Artist:        optionalString(tags.First("ARTIST")),
+		Album:         optionalString(tags.First("ALBUM")),
+		TrackNumber:   optionalNumber(tags.Number("TRACKNUMBER")),
+		DiscNumber:    optionalNumber(tags.Number("DISCNUMBER")),
+		Year:          optionalNumber(tags.Number("DATE")),
+		Genre:         optionalString(tags.First("GENRE")),
+		Comment:       optionalString(tags.First("COMMENT")),
+		Codec:         optionalString(metadata.Properties.Codec),
+		Container:     optionalString(metadata.Properties.Container),
+		BitsPerSample: optionalNumber(metadata.Properties.BitsPerSample, true),
+		BitrateMode:   optionalString(metadata.Properties.BitrateMode),
+		Encoder:       optionalString(metadata.Properties.Encoder),
+	}
+	// Without the codec it isn't known whether the stream is lossless
+	if audioFile.Codec != nil {
+		audioFile.Lossless = &metadata.Properties.Lossless
+	}
+	if metadata.Properties.SampleCount > 0 {
+		audioFile.SampleCount = &metadata.Properties.SampleCount
+	}
+
+	return audioFile, tags, nil
 }
 
-func (s *Service) actualizeCovers(tx *sqlx.Tx, dirId int) (err error) {
+func (s *Service) actualizeCovers(tx *sqlx.Tx, session *ScanSession, dirId int, ignored map[string]bool) (changed bool, err error) {
 	absolutePath, err := s.AbsolutePath(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to calculate absolute path to directory")
-		return err
+		return false, err
 	}
 
 	entries, err := os.ReadDir(absolutePath)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to read directory on disk")
-		return err
+		return false, err
+	}
+	dirName := filepath.Base(absolutePath)
+	// Pictures named after audio files are covers of their tracks whatever the rules say
+	trackStems, err := s.trackStems(tx, dirId)
+	if err != nil {
+		return false, err
 	}
 
+	// Files that can't be examined are kept in the database as they are
+	presentOnDisk := make(map[string]bool)
+	// Images that don't pass the cover rules, stored ones are deleted right away
+	notCovers := make(map[string]bool)
+	var tasks []coverTask
 	for _, entry := range entries {
-		if !strings.Contains(strings.ToLower(entry.Name()), "cover") {
+		if err = session.Ctx.Err(); err != nil {
+			return false, err
+		}
+		if ignored[entry.Name()] || !(s.CoverService.MayBeCover(dirName, entry.Name()) || trackStems[trackStem(entry.Name())]) {
 			continue
 		}
 
 		fileAbsolutePath := filepath.Join(absolutePath, entry.Name())
 		isImageFile, err := utils.IsImageFile(fileAbsolutePath)
 		if err != nil {
-			log.Error().Err(err).Str("absolutePath", fileAbsolutePath).Msg("Failed to check on image")
-			return err
-		}
-		if isImageFile {
-			sha256OnDisk, err := utils.CalculateSha256(fileAbsolutePath)
+			presentOnDisk[entry.Name()] = true
+			err = s.recordFileError(tx, dirId, fileAbsolutePath, model.ScanErrorStageDetect, err)
 			if err != nil {
-				log.Error().Str("entryName", entry.Name()).Msg("Failed to calculate sha256")
-				return err
+				return false, err
 			}
+			continue
+		}
+		if !isImageFile {
+			continue
+		}
+		presentOnDisk[entry.Name()] = true
 
-			alreadyInDatabase, err := s.CoverService.IsExistsByDirAndName(tx, dirId, entry.Name())
+		fileStat, err := utils.StatFile(fileAbsolutePath)
+		if err != nil {
+			err = s.recordFileError(tx, dirId, fileAbsolutePath, model.ScanErrorStageStat, err)
 			if err != nil {
-				log.Error().Str("entryName", entry.Name()).Msg("Failed to check directory existence")
-				return err
+				return false, err
 			}
+			continue
+		}
+		task := coverTask{
+			absolutePath: fileAbsolutePath,
+			fileStat:     fileStat,
+		}
 
-			if alreadyInDatabase {
-				cover, err := s.CoverService.GetByDirAndName(tx, dirId, entry.Name())
-				if err != nil {
-					log.Error().Str("entryName", entry.Name()).Msg("Failed to check directory existence")
-					return err
-				}
-				sha256InDatabase := cover.Sha256
-
-				if sha256OnDisk == sha256InDatabase {
-					continue
-				}
-
-				coverToUpdate, err := s.prepareCoverByAbsolutePath(absolutePath)
+		task.exists, err = s.CoverService.IsExistsByDirAndName(tx, dirId, entry.Name())
+		if err != nil {
+			log.Error().Str("entryName", entry.Name()).Msg("Failed to check cover existence")
+			return false, err
+		}
+		if task.exists {
+			task.existing, err = s.CoverService.GetByDirAndName(tx, dirId, entry.Name())
+			if err != nil {
+				log.Error().Str("entryName", entry.Name()).Msg("Failed to get cover")
+				return false, err
+			}
+			if !session.Options.DeepVerify && fileStat.Matches(task.existing.SizeByte, task.existing.ModifiedAt, task.existing.Inode) {
+				isCover, reclassified, err := s.reclassifyCover(tx, dirName, trackStems, task.existing)
 				if err != nil {
-					log.Error().Str("entryName", entry.Name()).Msg("Failed to prepare cover")
-					return err
+					log.Error().Str("entryName", entry.Name()).Msg("Failed to reclassify cover")
+					return false, err
 				}
-				coverToUpdate.DirId = dirId
-				coverToUpdate.Sha256 = sha256OnDisk
+				notCovers[entry.Name()] = !isCover
+				changed = changed || reclassified
+				continue
+			}
+		}
+		tasks = append(tasks, task)
+	}
 
-				_, err = s.CoverService.Update(tx, cover.CoverId, coverToUpdate)
-				if err != nil {
-					log.Error().Str("entryName", entry.Name()).Msg("Failed to update cover")
-					return err
-				}
-			} else {
-				coverToCreate, err := s.prepareCoverByAbsolutePath(fileAbsolutePath)
-				if err != nil {
-					log.Error().Str("entryName", entry.Name()).Msg("Failed to prepare cover")
-					return err
-				}
-				coverToCreate.DirId = dirId
-				coverToCreate.Sha256 = sha256OnDisk
+	err = s.pool.run(session.Ctx, len(tasks), func(i int) (err error) {
+		s.readCoverTask(session, &tasks[i])
+		return nil
+	})
+	if err == nil {
+		err = session.Ctx.Err()
+	}
+	if err != nil {
+		log.Error().Int("dirId", dirId).Err(err).Msg("Failed to read covers")
+		return false, err
+	}
 
-				_, err = s.CoverService.Create(tx, coverToCreate)
-				if err != nil {
-					log.Error().Str("entryName", entry.Name()).Msg("Failed to create cover")
-					return err
-				}
+	for _, task := range tasks {
+		if task.failure != nil {
+			err = s.recordFileError(tx, dirId, task.absolutePath, task.failedStage, task.failure)
+			if err != nil {
+				return false, err
 			}
+			continue
+		}
 
+		isCover, taskChanged := true, false
+		failure, err := s.inFileSavepoint(tx, session, func() (err error) {
+			isCover, taskChanged, err = s.storeCoverTask(tx, session, dirId, dirName, trackStems, task)
+			return err
+		})
+		if err != nil {
+			return false, err
 		}
+		if failure != nil {
+			err = s.recordFileError(tx, dirId, task.absolutePath, model.ScanErrorStageStore, failure)
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+		if !isCover {
+			notCovers[filepath.Base(task.absolutePath)] = true
+		}
+		changed = changed || taskChanged
 	}
 
 	covers, err := s.CoverService.GetAllByDir(tx, dirId)
 	if err != nil {
 		log.Error().Int("dirId", dirId).Msg("Failed to get covers")
-		return err
+		return false, err
 	}
 
 	for _, cover := range covers {
-		foundOnDisk := false
-
-		for _, entry := range entries {
-			if !strings.Contains(strings.ToLower(entry.Name()), "cover") {
-				continue
+		if ignored[cover.Filename] || notCovers[cover.Filename] {
+			err = s.CoverService.Delete(tx, cover.CoverId)
+			if err != nil {
+				log.Error().Err(err).Int("coverId", cover.CoverId).Msg("Failed to delete ignored cover")
+				return false, err
 			}
+			session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityCover, cover.CoverId, filepath.Join(absolutePath, cover.Filename))
+			changed = true
+		} else if !presentOnDisk[cover.Filename] {
+			session.PendingDeletions.add(model.ScanReportEntityCover, cover.CoverId)
+		}
+	}
+
+	return changed, nil
+}
 
-			fileAbsolutePath := filepath.Join(absolutePath, entry.Name())
-			isImageFile, err := utils.IsImageFile(fileAbsolutePath)
+// storeCoverTask writes a cover read from disk to the database. isCover is false if the image doesn't pass
+// the cover rules and has to be deleted, changed is true if the covers of the directory changed
+func (s *Service) storeCoverTask(tx *sqlx.Tx, session *ScanSession, dirId int, dirName string, trackStems map[string]bool, task coverTask) (isCover bool, changed bool, err error) {
+	if !task.contentChanged {
+		cover := task.existing
+		cover.SizeByte = task.fileStat.SizeByte
+		cover.ModifiedAt = &task.fileStat.ModifiedAt
+		cover.Inode = task.fileStat.Inode
+		kind, isCover := s.classifyCover(dirName, trackStems, cover)
+		if !isCover {
+			return false, false, nil
+		}
+		changed = kind != cover.Kind
+		cover.Kind = kind
+		if task.analyzed != nil {
+			err = s.CoverService.UpdateAnalysis(tx, cover.CoverId, *task.analyzed)
 			if err != nil {
-				log.Error().Str("fileAbsolutePath", fileAbsolutePath).Msg("Failed to check file type")
-				return err
+				return false, false, err
 			}
+		}
+		err = s.CoverService.UpdateFileStat(tx, cover.CoverId, cover)
+		if err != nil {
+			log.Error().Str("absolutePath", task.absolutePath).Msg("Failed to update file stat of cover")
+			return false, false, err
+		}
+		return true, changed, nil
+	}
 
-			if isImageFile {
-				if cover.Filename == entry.Name() {
-					foundOnDisk = true
-				}
-			}
+	kind, isCover := s.classifyCover(dirName, trackStems, task.prepared)
+	if !isCover {
+		return false, false, nil
+	}
+	task.prepared.Kind = kind
+
+	if !task.exists {
+		moved, err := s.moveCover(tx, session, dirId, task)
+		if err != nil {
+			log.Error().Str("absolutePath", task.absolutePath).Msg("Failed to look for moved cover")
+			return false, false, err
 		}
+		if moved {
+			return true, true, nil
+		}
+	}
 
-		if !foundOnDisk {
-			err = s.CoverService.Delete(tx, cover.CoverId)
-			if err != nil {
-				log.Error().Err(err).Int("coverId", cover.CoverId).Msg("Failed to delete cover")
-				return err
-			}
+	cover := task.prepared
+	cover.DirId = &dirId
+	cover.Source = model.CoverSourceFile
+	cover.Sha256 = task.sha256
+	if task.exists {
+		_, err = s.CoverService.Update(tx, task.existing.CoverId, cover)
+		if err != nil {
+			log.Error().Str("absolutePath", task.absolutePath).Msg("Failed to update cover")
+			return false, false, err
 		}
+		session.Report.add(model.ScanReportActionUpdated, model.ScanReportEntityCover, task.existing.CoverId, task.absolutePath)
+	} else {
+		createdCover, err := s.CoverService.Create(tx, cover)
+		if err != nil {
+			log.Error().Str("absolutePath", task.absolutePath).Msg("Failed to create cover")
+			return false, false, err
+		}
+		session.Report.add(model.ScanReportActionCreated, model.ScanReportEntityCover, createdCover.CoverId, task.absolutePath)
 	}
+	return true, true, nil
+}
 
-	return nil
+// coverTask is a cover that has to be read from disk during a scan
+type coverTask struct {
+	absolutePath string
+	fileStat     utils.FileStat
+	// Set when the file is already in the database, existing then holds its row
+	exists   bool
+	existing model.Cover
+
+	// Filled by readCoverTask
+	sha256         string
+	contentChanged bool
+	prepared       model.Cover
+	// Set for an unchanged cover stored before the analysis was introduced, see cover_service.Analyze
+	analyzed *model.Cover
+	// Set when the file can't be read, failedStage tells at which step
+	failure     error
+	failedStage string
 }
 
-func (s *Service) prepareCoverByAbsolutePath(absolutePath string) (audioFile model.Cover, err error) {
-	fileInfo, err := os.Stat(absolutePath)
+// reclassifyCover applies the current cover rules to a stored cover whose file did not change.
+// isCover is false if the image is not a cover anymore, reclassified is true if its kind was updated
+func (s *Service) reclassifyCover(tx *sqlx.Tx, dirName string, trackStems map[string]bool, cover model.Cover) (isCover bool, reclassified bool, err error) {
+	kind, isCover := s.classifyCover(dirName, trackStems, cover)
+	if !isCover || kind == cover.Kind {
+		return isCover, false, nil
+	}
+	cover.Kind = kind
+	err = s.CoverService.UpdateFileStat(tx, cover.CoverId, cover)
 	if err != nil {
-		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to get file info")
-		return model.Cover{}, err
+		log.Error().Err(err).Int("coverId", cover.CoverId).Msg("Failed to update kind of cover")
+		return false, false, err
 	}
+	return true, true, nil
+}
 
-	f, err := os.Open(absolutePath)
+// classifyCover applies the cover rules, a picture that doesn't pass them is still a front cover of its track
+func (s *Service) classifyCover(dirName string, trackStems map[string]bool, cover model.Cover) (kind string, isCover bool) {
+	kind, isCover = s.CoverService.Classify(dirName, cover)
+	if !isCover && trackStems[trackStem(cover.Filename)] {
+		return model.CoverKindFront, true
+	}
+	return kind, isCover
+}
+
+// readCoverTask hashes the file and decodes its dimensions if the content changed. Safe to call concurrently
+func (s *Service) readCoverTask(session *ScanSession, task *coverTask) {
+	var err error
+	task.sha256, _, err = s.calculateSha256(session, task.absolutePath, task.fileStat, false)
 	if err != nil {
-		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open file")
+		task.failure, task.failedStage = err, model.ScanErrorStageHash
+		return
+	}
+	if task.exists && task.sha256 == task.existing.Sha256 {
+		if !s.CoverService.IsAnalyzed(task.existing) {
+			task.analyzed = s.analyzeCoverFile(task.absolutePath, task.existing)
+		}
+		return
+	}
+
+	task.contentChanged = true
+	task.prepared, err = s.prepareCoverByAbsolutePath(task.absolutePath, task.fileStat)
+	if err != nil {
+		task.failure, task.failedStage = err, model.ScanErrorStageCover
+	}
+}
+
+// recordFileError stores a problem with a single file. The file is skipped instead of failing the whole directory
+func (s *Service) recordFileError(tx *sqlx.Tx, dirId int, absolutePath string, stage string, reason error) (err error) {
+	log.Warn().Err(reason).Str("absolutePath", absolutePath).Str("stage", stage).Msg("Skipping file that can't be scanned")
+
+	err = s.ScanErrorService.Record(tx, dirId, filepath.Base(absolutePath), stage, reason)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to record scan error")
+		return err
+	}
+	return nil
+}
+
+func (s *Service) prepareCoverByAbsolutePath(absolutePath string, fileStat utils.FileStat) (audioFile model.Cover, err error) {
+	data, err := os.ReadFile(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read file")
 		return model.Cover{}, err
 	}
-	defer f.Close()
 
-	img, _, err := image.DecodeConfig(f)
+	img, _, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
 		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to decode config")
 		return model.Cover{}, err
 	}
 
 	audioFile = model.Cover{
-		Filename:  fileInfo.Name(),
-		Extension: filepath.Ext(absolutePath),
-		SizeByte:  fileInfo.Size(),
-		WidthPx:   img.Width,
-		HeightPx:  img.Height,
+		Filename:   filepath.Base(absolutePath),
+		Extension:  filepath.Ext(absolutePath),
+		SizeByte:   fileStat.SizeByte,
+		WidthPx:    img.Width,
+		HeightPx:   img.Height,
+		ModifiedAt: &fileStat.ModifiedAt,
+		Inode:      fileStat.Inode,
+	}
+
+	return s.CoverService.Analyze(audioFile, data), nil
+}
+
+// analyzeCoverFile reads the picture of an unchanged cover and analyzes it, nil if it can't be read or decoded
+func (s *Service) analyzeCoverFile(absolutePath string, cover model.Cover) (analyzed *model.Cover) {
+	data, err := os.ReadFile(absolutePath)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read file for analysis")
+		return nil
+	}
+	cover = s.CoverService.Analyze(cover, data)
+	if !s.CoverService.IsAnalyzed(cover) {
+		return nil
+	}
+	return &cover
+}
+
+// calculateSha256 streams the file through the pool's read throttle. With withPayload the audio payload
+// is hashed in the same pass, payloadSha256 stays nil if the format's tags are not recognized
+func (s *Service) calculateSha256(session *ScanSession, absolutePath string, fileStat utils.FileStat, withPayload bool) (sha256 string, payloadSha256 *string, err error) {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open file")
+		return "", nil, err
+	}
+	defer file.Close()
+
+	var payload utils.AudioPayload
+	hasPayload := false
+	if withPayload {
+		payload, hasPayload, err = utils.FindAudioPayload(file)
+		if err != nil {
+			log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to find audio payload")
+			return "", nil, err
+		}
+	}
+
+	reader := utils.ThrottledReader{
+		Ctx:      session.Ctx,
+		Reader:   file,
+		Throttle: s.pool.throttle,
+	}
+	if hasPayload {
+		var payloadHash string
+		sha256, payloadHash, err = utils.CalculateSha256WithPayload(reader, payload)
+		payloadSha256 = &payloadHash
+	} else {
+		sha256, err = utils.CalculateSha256FromReader(reader)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to calculate sha256")
+		return "", nil, err
 	}
+	session.Progress.fileHashed(absolutePath, fileStat.SizeByte)
 
-	return audioFile, nil
+	return sha256, payloadSha256, nil
 }
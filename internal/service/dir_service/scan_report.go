@@ -0,0 +1,44 @@
+package dir_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"path/filepath"
+)
+
+// reportDeletedDir adds the directory and everything stored below it to the report before it is deleted
+func (s *Service) reportDeletedDir(tx *sqlx.Tx, session *ScanSession, dirId int, absolutePath string) (err error) {
+	subDirs, err := s.DirRepo.ReadSubDirs(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get subdirectories")
+		return err
+	}
+	for _, subDir := range subDirs {
+		err = s.reportDeletedDir(tx, session, subDir.DirId, filepath.Join(absolutePath, subDir.Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	audioFiles, err := s.AudioFileService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's audio files")
+		return err
+	}
+	for _, audioFile := range audioFiles {
+		session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityAudioFile, audioFile.AudioFileId, filepath.Join(absolutePath, audioFile.Filename))
+	}
+
+	covers, err := s.CoverService.GetAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory's covers")
+		return err
+	}
+	for _, cover := range covers {
+		session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityCover, cover.CoverId, filepath.Join(absolutePath, cover.Filename))
+	}
+
+	session.Report.add(model.ScanReportActionDeleted, model.ScanReportEntityDirectory, dirId, absolutePath)
+	return nil
+}
@@ -0,0 +1,211 @@
+package dir_service
+
+import (
+	"context"
+	"music-files/internal/model"
+	"sync"
+	"sync/atomic"
+)
+
+// ScanSession carries the state of a single scan run through the directory tree
+type ScanSession struct {
+	Ctx      context.Context
+	Options  ScanOptions
+	Progress *ScanProgress
+	Report   *ScanReport
+	// Entities missing on disk. They are deleted after all directories are scanned,
+	// so a file moved to a directory scanned later keeps its id
+	PendingDeletions *PendingDeletions
+
+	dirLocations *dirLocations
+}
+
+// ScanOptions controls how deep and how thoroughly a scan goes
+type ScanOptions struct {
+	// Scan existing subdirectories too. Newly found subdirectories are always scanned
+	Recursive bool
+	// Hash every file even if its size, modification time and inode did not change
+	DeepVerify bool
+	// Database changes are rolled back by the caller, nothing is written to disk
+	DryRun bool
+}
+
+func NewScanSession(ctx context.Context, options ScanOptions) (session *ScanSession) {
+	return &ScanSession{
+		Ctx:              ctx,
+		Options:          options,
+		Progress:         &ScanProgress{},
+		Report:           &ScanReport{},
+		PendingDeletions: &PendingDeletions{},
+		dirLocations:     &dirLocations{},
+	}
+}
+
+// ScanProgress holds live counters of a running scan. It is safe to read while the scan is writing
+type ScanProgress struct {
+	dirsVisited    atomic.Int64
+	dirsFailed     atomic.Int64
+	filesHashed    atomic.Int64
+	bytesProcessed atomic.Int64
+	currentPath    atomic.Value
+}
+
+// ScanProgressSnapshot is a point-in-time copy of ScanProgress
+type ScanProgressSnapshot struct {
+	DirsVisited    int64
+	DirsFailed     int64
+	FilesHashed    int64
+	BytesProcessed int64
+	CurrentPath    string
+}
+
+func (p *ScanProgress) dirVisited(absolutePath string) {
+	p.dirsVisited.Add(1)
+	p.currentPath.Store(absolutePath)
+}
+
+// DirFailed counts a directory whose scan was rolled back and skipped
+func (p *ScanProgress) DirFailed() {
+	p.dirsFailed.Add(1)
+}
+
+func (p *ScanProgress) fileHashed(absolutePath string, sizeByte int64) {
+	p.filesHashed.Add(1)
+	p.bytesProcessed.Add(sizeByte)
+	p.currentPath.Store(absolutePath)
+}
+
+func (p *ScanProgress) Snapshot() (snapshot ScanProgressSnapshot) {
+	snapshot = ScanProgressSnapshot{
+		DirsVisited:    p.dirsVisited.Load(),
+		DirsFailed:     p.dirsFailed.Load(),
+		FilesHashed:    p.filesHashed.Load(),
+		BytesProcessed: p.bytesProcessed.Load(),
+	}
+	if currentPath, ok := p.currentPath.Load().(string); ok {
+		snapshot.CurrentPath = currentPath
+	}
+	return snapshot
+}
+
+// Restore continues counting from the snapshot, e.g. when an interrupted scan is resumed
+func (p *ScanProgress) Restore(snapshot ScanProgressSnapshot) {
+	p.dirsVisited.Store(snapshot.DirsVisited)
+	p.dirsFailed.Store(snapshot.DirsFailed)
+	p.filesHashed.Store(snapshot.FilesHashed)
+	p.bytesProcessed.Store(snapshot.BytesProcessed)
+	if snapshot.CurrentPath != "" {
+		p.currentPath.Store(snapshot.CurrentPath)
+	}
+}
+
+// ScanReport collects changes made by the scan until they are taken with Drain
+type ScanReport struct {
+	mutex   sync.Mutex
+	entries []model.ScanReportEntry
+}
+
+func (r *ScanReport) add(action string, entity string, entityId int, path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = append(r.entries, model.ScanReportEntry{
+		Action:   action,
+		Entity:   entity,
+		EntityId: &entityId,
+		Path:     path,
+	})
+}
+
+func (r *ScanReport) addIgnored(entity string, path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = append(r.entries, model.ScanReportEntry{
+		Action: model.ScanReportActionIgnored,
+		Entity: entity,
+		Path:   path,
+	})
+}
+
+// mark returns the position rollbackTo goes back to
+func (r *ScanReport) mark() (position int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.entries)
+}
+
+// rollbackTo forgets the changes collected after mark was called, e.g. when their writes were rolled back
+func (r *ScanReport) rollbackTo(position int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if position < len(r.entries) {
+		r.entries = r.entries[:position]
+	}
+}
+
+// Drain returns the changes collected since the previous call and forgets them
+func (r *ScanReport) Drain() (entries []model.ScanReportEntry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	entries = r.entries
+	r.entries = nil
+	return entries
+}
+
+// PendingDeletions collects entities missing on disk until they are taken with Drain
+type PendingDeletions struct {
+	mutex     sync.Mutex
+	deletions []model.ScanPendingDeletion
+}
+
+func (d *PendingDeletions) add(entity string, entityId int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.deletions = append(d.deletions, model.ScanPendingDeletion{
+		Entity:   entity,
+		EntityId: entityId,
+	})
+}
+
+// Drain returns the deletions collected since the previous call and forgets them
+func (d *PendingDeletions) Drain() (deletions []model.ScanPendingDeletion) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	deletions = d.deletions
+	d.deletions = nil
+	return deletions
+}
+
+// dirLocations caches where directories holding candidates of move detection are and whether they are offline
+type dirLocations struct {
+	mutex     sync.Mutex
+	locations map[int]dirLocation
+}
+
+type dirLocation struct {
+	absolutePath string
+	// The directory or one of its ancestors is offline, its content must be kept
+	offline bool
+}
+
+func (l *dirLocations) get(dirId int) (location dirLocation, ok bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	location, ok = l.locations[dirId]
+	return location, ok
+}
+
+func (l *dirLocations) put(dirId int, location dirLocation) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.locations == nil {
+		l.locations = make(map[int]dirLocation)
+	}
+	l.locations[dirId] = location
+}
+
+// reset forgets all locations, e.g. when a directory goes offline or comes back
+func (l *dirLocations) reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.locations = nil
+}
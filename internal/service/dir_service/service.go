@@ -1,9 +1,13 @@
 package dir_service
 
 import (
+	"music-files/internal/config"
 	"music-files/internal/database/repository/dir_repo"
 	"music-files/internal/service/audio_file_service"
 	"music-files/internal/service/cover_service"
+	"music-files/internal/service/ignore_service"
+	"music-files/internal/service/scan_error_service"
+	"time"
 )
 
 type Service struct {
@@ -11,16 +15,36 @@ type Service struct {
 
 	CoverService     cover_service.Service
 	AudioFileService audio_file_service.Service
+	ScanErrorService scan_error_service.Service
+	IgnoreService    ignore_service.Service
+
+	// Shared by all scans so that concurrency and read speed are bounded for the whole service
+	pool *workerPool
+	// How long content of an offline directory is kept, zero keeps it until purged explicitly
+	offlineGracePeriod time.Duration
+	// Deepest directory level below a root that is scanned, zero means no limit
+	maxDepth int
+	// Reads technical details and tags of audio files
+	metadataReader MetadataReader
 }
 
 func NewService(dirRepo dir_repo.Repo,
 	coverService cover_service.Service,
-	audioFileService audio_file_service.Service) (s *Service) {
+	audioFileService audio_file_service.Service,
+	scanErrorService scan_error_service.Service,
+	ignoreService ignore_service.Service,
+	scannerConfig config.Scanner) (s *Service) {
 
 	s = &Service{
-		DirRepo:          dirRepo,
-		CoverService:     coverService,
-		AudioFileService: audioFileService,
+		DirRepo:            dirRepo,
+		CoverService:       coverService,
+		AudioFileService:   audioFileService,
+		ScanErrorService:   scanErrorService,
+		IgnoreService:      ignoreService,
+		pool:               newWorkerPool(scannerConfig),
+		offlineGracePeriod: scannerConfig.OfflineGracePeriod,
+		maxDepth:           scannerConfig.MaxDepth,
+		metadataReader:     newMetadataReaderChain(scannerConfig.MetadataReaders),
 	}
 
 	return s
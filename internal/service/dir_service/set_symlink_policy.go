@@ -0,0 +1,58 @@
+package dir_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// SetSymlinkPolicy changes how scans of the root treat symlinks to directories. It takes effect on the next scan,
+// stored entities reached through links the new policy doesn't follow are deleted by it
+func (s *Service) SetSymlinkPolicy(tx *sqlx.Tx, rootDirId int, symlinkPolicy string) (err error) {
+	log.Debug().Int("rootDirId", rootDirId).Str("symlinkPolicy", symlinkPolicy).Msg("Setting symlink policy of root")
+
+	err = checkSymlinkPolicy(symlinkPolicy)
+	if err != nil {
+		return err
+	}
+	exists, err := s.DirRepo.IsExists(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to check directory existence")
+		return err
+	}
+	if !exists {
+		log.Error().Int("rootDirId", rootDirId).Msg("Directory not found")
+		return errors.NotFound{Resource: "directory in database"}
+	}
+	dir, err := s.DirRepo.Read(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to read directory")
+		return err
+	}
+	if dir.ParentDirId != nil {
+		log.Error().Int("rootDirId", rootDirId).Msg("Directory is not root")
+		return errors.BadRequest{Message: fmt.Sprintf("directory with id=%d is not root", rootDirId)}
+	}
+
+	err = s.DirRepo.UpdateSymlinkPolicy(tx, rootDirId, symlinkPolicy)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to update symlink policy")
+		return err
+	}
+
+	log.Debug().Int("rootDirId", rootDirId).Msg("Symlink policy of root set successfully")
+	return nil
+}
+
+func checkSymlinkPolicy(symlinkPolicy string) (err error) {
+	switch symlinkPolicy {
+	case model.SymlinkPolicyIgnore, model.SymlinkPolicyWithinRoot, model.SymlinkPolicyAnywhere:
+		return nil
+	default:
+		log.Error().Str("symlinkPolicy", symlinkPolicy).Msg("Unknown symlink policy")
+		return errors.BadRequest{Message: fmt.Sprintf("unknown symlink policy %q, expected one of %s, %s, %s",
+			symlinkPolicy, model.SymlinkPolicyIgnore, model.SymlinkPolicyWithinRoot, model.SymlinkPolicyAnywhere)}
+	}
+}
@@ -0,0 +1,147 @@
+package dir_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// traversedEntries applies the symlink policy of the root, the depth limit and loop protection to the entries
+// of the directory. Excluded entries are added to ignored, symlinks followed as directories are returned.
+// The policy only applies to symlinks to directories, symlinks to files are scanned like the files themselves
+func (s *Service) traversedEntries(tx *sqlx.Tx, session *ScanSession, dirId int, absolutePath string, entries []os.DirEntry, ignored map[string]bool) (linkedDirs map[string]bool, err error) {
+	linkedDirs = make(map[string]bool)
+
+	root, depth, err := s.rootOf(tx, dirId)
+	if err != nil {
+		return nil, err
+	}
+	policy := root.SymlinkPolicyOrDefault()
+	resolvedRoot := ResolveOrKeep(root.Name)
+	// Every directory from this one up to the root, a subdirectory leading to any of them is a loop
+	ancestors, err := ancestorsOnDisk(absolutePath, depth)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to examine ancestors of directory")
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if ignored[entry.Name()] {
+			continue
+		}
+		entryPath := filepath.Join(absolutePath, entry.Name())
+		isDir := entry.IsDir()
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			fileInfo, statErr := os.Stat(entryPath)
+			if statErr != nil || !fileInfo.IsDir() {
+				// Only directories lead to loops or out of the root. A dangling link is left to the content
+				// scan, which records the error
+				continue
+			}
+			if !IsSymlinkFollowed(policy, entryPath, resolvedRoot) {
+				ignored[entry.Name()] = true
+				session.Report.addIgnored(model.ScanReportEntityDirectory, entryPath)
+				continue
+			}
+			linkedDirs[entry.Name()] = true
+			isDir = true
+		}
+		if !isDir {
+			continue
+		}
+
+		if s.maxDepth > 0 && depth+1 > s.maxDepth {
+			log.Warn().Str("absolutePath", entryPath).Int("maxDepth", s.maxDepth).Msg("Directory is deeper than allowed, skipping it")
+			ignored[entry.Name()] = true
+			session.Report.addIgnored(model.ScanReportEntityDirectory, entryPath)
+			continue
+		}
+		fileInfo, err := os.Stat(entryPath)
+		if err != nil {
+			continue
+		}
+		for _, ancestor := range ancestors {
+			if os.SameFile(fileInfo, ancestor.fileInfo) {
+				ignored[entry.Name()] = true
+				err = s.recordFileError(tx, dirId, entryPath, model.ScanErrorStageLoop, fmt.Errorf("directory leads back to %s", ancestor.absolutePath))
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	return linkedDirs, nil
+}
+
+// IsSymlinkFollowed tells whether a symlink is followed under the symlink policy of a root whose path resolves to resolvedRoot
+func IsSymlinkFollowed(policy string, absolutePath string, resolvedRoot string) (followed bool) {
+	switch policy {
+	case model.SymlinkPolicyAnywhere:
+		return true
+	case model.SymlinkPolicyWithinRoot:
+		resolvedPath, err := filepath.EvalSymlinks(absolutePath)
+		if err != nil {
+			return false
+		}
+		return isWithin(resolvedPath, resolvedRoot)
+	default:
+		return false
+	}
+}
+
+type ancestorOnDisk struct {
+	absolutePath string
+	fileInfo     os.FileInfo
+}
+
+// ancestorsOnDisk stats the directory and its count parents. os.SameFile compares device and inode of the results
+func ancestorsOnDisk(absolutePath string, count int) (ancestors []ancestorOnDisk, err error) {
+	currentPath := absolutePath
+	for i := 0; i <= count; i++ {
+		fileInfo, err := os.Stat(currentPath)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, ancestorOnDisk{absolutePath: currentPath, fileInfo: fileInfo})
+		currentPath = filepath.Dir(currentPath)
+	}
+	return ancestors, nil
+}
+
+// rootOf returns the root of the directory and how many levels below the root the directory is
+func (s *Service) rootOf(tx *sqlx.Tx, dirId int) (root model.Directory, depth int, err error) {
+	root, err = s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch directory from database")
+		return model.Directory{}, 0, err
+	}
+	for root.ParentDirId != nil {
+		root, err = s.DirRepo.Read(tx, *root.ParentDirId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to fetch parent directory from database")
+			return model.Directory{}, 0, err
+		}
+		depth++
+	}
+	return root, depth, nil
+}
+
+// ResolveOrKeep resolves symlinks in the path, a path that can't be resolved is taken as it is
+func ResolveOrKeep(absolutePath string) (resolvedPath string) {
+	resolvedPath, err := filepath.EvalSymlinks(absolutePath)
+	if err != nil {
+		return filepath.Clean(absolutePath)
+	}
+	return resolvedPath
+}
+
+func isWithin(absolutePath string, base string) (within bool) {
+	return absolutePath == base || strings.HasPrefix(absolutePath, strings.TrimSuffix(base, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,85 @@
+package dir_service
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"music-files/internal/database/repository/dir_repo"
+	"music-files/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeDirRepo serves directories from memory, methods the tests don't use are left unimplemented
+type fakeDirRepo struct {
+	dir_repo.Repo
+	dirs map[int]model.Directory
+}
+
+func (r *fakeDirRepo) Read(tx *sqlx.Tx, dirId int) (dir model.Directory, err error) {
+	dir, ok := r.dirs[dirId]
+	if !ok {
+		return model.Directory{}, os.ErrNotExist
+	}
+	return dir, nil
+}
+
+func TestScanKeepsFileSymlinksUnderDefaultPolicy(t *testing.T) {
+	outside := t.TempDir()
+	err := os.WriteFile(filepath.Join(outside, "track.mp3"), []byte("audio"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Mkdir(filepath.Join(outside, "album"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPath := t.TempDir()
+	err = os.Symlink(filepath.Join(outside, "track.mp3"), filepath.Join(rootPath, "linked.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(filepath.Join(outside, "album"), filepath.Join(rootPath, "linked-album"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A root added before symlink policies existed has none, the default applies
+	s := &Service{DirRepo: &fakeDirRepo{dirs: map[int]model.Directory{1: {DirId: 1, Name: rootPath}}}}
+	if policy := (model.Directory{}).SymlinkPolicyOrDefault(); policy != model.SymlinkPolicyIgnore {
+		t.Fatalf("default symlink policy = %s, want %s", policy, model.SymlinkPolicyIgnore)
+	}
+
+	entries, err := os.ReadDir(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignored := make(map[string]bool)
+	session := NewScanSession(context.Background(), ScanOptions{})
+	linkedDirs, err := s.traversedEntries(nil, session, 1, rootPath, entries, ignored)
+	if err != nil {
+		t.Fatalf("traversedEntries() error = %v", err)
+	}
+
+	if ignored["linked.mp3"] {
+		t.Error("symlink to a file is ignored, its audio file would be deleted by the scan")
+	}
+	if !ignored["linked-album"] {
+		t.Error("symlink to a directory is not ignored under the default policy")
+	}
+	if len(linkedDirs) != 0 {
+		t.Errorf("linkedDirs = %v, want none", linkedDirs)
+	}
+	report := session.Report.Drain()
+	if len(report) != 1 || report[0].Entity != model.ScanReportEntityDirectory || report[0].Action != model.ScanReportActionIgnored {
+		t.Errorf("report = %+v, want the ignored directory only", report)
+	}
+
+	resolvedPath, err := s.ResolvePath(nil, 1, "linked.mp3")
+	if err != nil {
+		t.Fatalf("ResolvePath() error = %v", err)
+	}
+	if want := ResolveOrKeep(filepath.Join(outside, "track.mp3")); resolvedPath != want {
+		t.Errorf("ResolvePath() = %s, want %s", resolvedPath, want)
+	}
+}
@@ -0,0 +1,240 @@
+package dir_service
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"image"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+)
+
+// CoverUpload is an uploaded cover written under a temporary name next to its target, with what RevertUpload
+// needs to undo its database changes
+type CoverUpload struct {
+	tempPath     string
+	absolutePath string
+	overwrite    bool
+
+	dirId   int
+	coverId int
+	// The cover stored under the name before the upload, nil if the upload created it
+	previous *model.Cover
+	pinned   bool
+	// Cover pinned to the directory before the upload
+	previousOverrideId *int
+}
+
+// UploadCover writes the image next to the configured upload name and registers it as a cover. The file is moved in
+// place by FinishUpload once the transaction is committed. An existing file with that name is a conflict unless
+// overwrite is set. With pin the cover is also pinned to the directory
+func (s *Service) UploadCover(tx *sqlx.Tx, dirId int, data []byte, overwrite bool, pin bool) (cover model.Cover, upload CoverUpload, err error) {
+	log.Debug().Int("dirId", dirId).Int("sizeByte", len(data)).Bool("overwrite", overwrite).Bool("pin", pin).Msg("Uploading cover into directory")
+
+	exists, err := s.DirRepo.IsExists(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence")
+		return model.Cover{}, CoverUpload{}, err
+	}
+	if !exists {
+		log.Error().Int("dirId", dirId).Msg("Directory not found")
+		return model.Cover{}, CoverUpload{}, errors.NotFound{Resource: "directory in database"}
+	}
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to read directory")
+		return model.Cover{}, CoverUpload{}, err
+	}
+
+	imageConfig, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to decode uploaded image")
+		return model.Cover{}, CoverUpload{}, errors.BadRequest{Message: fmt.Sprintf("uploaded file is not a supported image: %s", err)}
+	}
+	filename := s.CoverService.UploadFilename(format)
+	trackStems, err := s.trackStems(tx, dirId)
+	if err != nil {
+		return model.Cover{}, CoverUpload{}, err
+	}
+	kind, isCover := s.classifyCover(filepath.Base(dir.Name), trackStems, model.Cover{Filename: filename, Extension: filepath.Ext(filename)})
+	if !isCover {
+		log.Error().Int("dirId", dirId).Str("filename", filename).Msg("Uploaded image is not a cover by the cover rules")
+		return model.Cover{}, CoverUpload{}, errors.BadRequest{Message: fmt.Sprintf("%s is not a cover by the cover rules, check COVERS_UPLOAD_NAME", filename)}
+	}
+
+	dirPath, err := s.AbsolutePath(tx, dirId)
+	if err != nil {
+		return model.Cover{}, CoverUpload{}, err
+	}
+	absolutePath := filepath.Join(dirPath, filename)
+	_, err = os.Lstat(absolutePath)
+	if err == nil && !overwrite {
+		log.Error().Str("absolutePath", absolutePath).Msg("Uploaded cover would replace an existing file")
+		return model.Cover{}, CoverUpload{}, errors.Conflict{Message: fmt.Sprintf("%s already exists in the directory, set overwrite to replace it", filename)}
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to stat existing file")
+		return model.Cover{}, CoverUpload{}, err
+	}
+
+	// The temporary file keeps its inode and modification time once it is moved in place
+	tempPath, err := utils.WriteTempFile(absolutePath, data)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to write uploaded cover")
+		return model.Cover{}, CoverUpload{}, err
+	}
+	upload = CoverUpload{tempPath: tempPath, absolutePath: absolutePath, overwrite: overwrite}
+	defer func() {
+		if err != nil {
+			os.Remove(tempPath)
+		}
+	}()
+	fileStat, err := utils.StatFile(tempPath)
+	if err != nil {
+		log.Error().Err(err).Str("tempPath", tempPath).Msg("Failed to stat uploaded cover")
+		return model.Cover{}, CoverUpload{}, err
+	}
+	cover = s.CoverService.Analyze(model.Cover{
+		Filename:   filename,
+		Extension:  filepath.Ext(filename),
+		SizeByte:   fileStat.SizeByte,
+		WidthPx:    imageConfig.Width,
+		HeightPx:   imageConfig.Height,
+		ModifiedAt: &fileStat.ModifiedAt,
+		Inode:      fileStat.Inode,
+	}, data)
+	cover.Sha256, err = utils.CalculateSha256FromReader(bytes.NewReader(data))
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to calculate sha256")
+		return model.Cover{}, CoverUpload{}, err
+	}
+	cover.Kind = kind
+	cover.DirId = &dirId
+	cover.Source = model.CoverSourceFile
+
+	exists, err = s.CoverService.IsExistsByDirAndName(tx, dirId, filename)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("filename", filename).Msg("Failed to check cover existence")
+		return model.Cover{}, CoverUpload{}, err
+	}
+	if exists {
+		existing, err := s.CoverService.GetByDirAndName(tx, dirId, filename)
+		if err != nil {
+			return model.Cover{}, CoverUpload{}, err
+		}
+		upload.previous = &existing
+		cover, err = s.CoverService.Update(tx, existing.CoverId, cover)
+		if err != nil {
+			log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to update cover")
+			return model.Cover{}, CoverUpload{}, err
+		}
+	} else {
+		cover, err = s.CoverService.Create(tx, cover)
+		if err != nil {
+			log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to create cover")
+			return model.Cover{}, CoverUpload{}, err
+		}
+	}
+
+	upload.dirId = dirId
+	upload.coverId = cover.CoverId
+
+	if pin {
+		err = s.DirRepo.UpdateCoverOverride(tx, dirId, &cover.CoverId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", dirId).Msg("Failed to update cover override of directory")
+			return model.Cover{}, CoverUpload{}, err
+		}
+		upload.pinned = true
+		upload.previousOverrideId = dir.CoverOverrideId
+		dir.CoverOverrideId = &cover.CoverId
+	}
+	err = s.resolveCoversAfterChange(tx, dir)
+	if err != nil {
+		return model.Cover{}, CoverUpload{}, err
+	}
+
+	log.Debug().Int("coverId", cover.CoverId).Str("absolutePath", absolutePath).Msg("Cover uploaded successfully")
+	return cover, upload, nil
+}
+
+// FinishUpload moves the uploaded file in place after its cover is committed. Without overwrite a file created with
+// that name in the meantime is kept and errors.Conflict is returned. When the file isn't moved, the committed changes
+// have to be undone with RevertUpload
+func (s *Service) FinishUpload(upload CoverUpload) (err error) {
+	if upload.overwrite {
+		err = os.Rename(upload.tempPath, upload.absolutePath)
+	} else {
+		// Unlike rename, link fails when the target exists
+		err = os.Link(upload.tempPath, upload.absolutePath)
+		if err == nil {
+			os.Remove(upload.tempPath)
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", upload.absolutePath).Msg("Failed to move uploaded cover in place")
+		s.DiscardUpload(upload)
+		if os.IsExist(err) {
+			return errors.Conflict{Message: fmt.Sprintf("%s was created in the directory during the upload", filepath.Base(upload.absolutePath))}
+		}
+		return err
+	}
+
+	log.Debug().Str("absolutePath", upload.absolutePath).Msg("Uploaded cover moved in place successfully")
+	return nil
+}
+
+// RevertUpload undoes the database changes of an upload whose file couldn't be moved in place: the created cover is
+// deleted or the previous one restored, and the previous pin of the directory is restored
+func (s *Service) RevertUpload(tx *sqlx.Tx, upload CoverUpload) (err error) {
+	log.Debug().Int("coverId", upload.coverId).Str("absolutePath", upload.absolutePath).Msg("Reverting cover upload")
+
+	if upload.previous != nil {
+		_, err = s.CoverService.Update(tx, upload.coverId, *upload.previous)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", upload.coverId).Msg("Failed to restore cover replaced by upload")
+			return err
+		}
+	} else {
+		err = s.CoverService.Delete(tx, upload.coverId)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", upload.coverId).Msg("Failed to delete uploaded cover")
+			return err
+		}
+	}
+	if upload.pinned {
+		err = s.DirRepo.UpdateCoverOverride(tx, upload.dirId, upload.previousOverrideId)
+		if err != nil {
+			log.Error().Err(err).Int("dirId", upload.dirId).Msg("Failed to restore cover override of directory")
+			return err
+		}
+	}
+
+	dir, err := s.DirRepo.Read(tx, upload.dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", upload.dirId).Msg("Failed to read directory")
+		return err
+	}
+	err = s.resolveCoversAfterChange(tx, dir)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Int("coverId", upload.coverId).Msg("Cover upload reverted successfully")
+	return nil
+}
+
+// DiscardUpload removes the uploaded file, e.g. when the transaction was rolled back
+func (s *Service) DiscardUpload(upload CoverUpload) {
+	if upload.tempPath == "" {
+		return
+	}
+	err := os.Remove(upload.tempPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Str("tempPath", upload.tempPath).Msg("Failed to remove uploaded cover")
+	}
+}
@@ -0,0 +1,57 @@
+package dir_service
+
+import (
+	"context"
+	"music-files/internal/config"
+	"music-files/internal/utils"
+	"sync"
+)
+
+// workerPool bounds the number of files read in parallel during scans
+type workerPool struct {
+	slots    chan struct{}
+	throttle *utils.ReadThrottle
+}
+
+func newWorkerPool(scannerConfig config.Scanner) (pool *workerPool) {
+	workers := scannerConfig.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &workerPool{
+		slots:    make(chan struct{}, workers),
+		throttle: utils.NewReadThrottle(scannerConfig.ReadBytesPerSecond),
+	}
+}
+
+// run calls process for every index in 0..n-1 on the pool and waits for all calls to finish.
+// The first error in index order is returned
+func (p *workerPool) run(ctx context.Context, n int, process func(i int) error) (err error) {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-p.slots }()
+			errs[i] = process(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err = range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
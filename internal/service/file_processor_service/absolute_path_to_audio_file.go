@@ -3,7 +3,6 @@ package file_processor_service
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
-	"path/filepath"
 )
 
 func (s *Service) AbsolutePathToAudioFile(tx *sqlx.Tx, audioFileId int) (absolutePath string, err error) {
@@ -15,14 +14,13 @@ func (s *Service) AbsolutePathToAudioFile(tx *sqlx.Tx, audioFileId int) (absolut
 		return "", err
 	}
 
-	absolutePathToDir, err := s.DirService.AbsolutePath(tx, audioFile.DirId)
+	// Symlinks are resolved the way the scan followed them
+	absolutePath, err = s.DirService.ResolvePath(tx, audioFile.DirId, audioFile.Filename)
 	if err != nil {
-		log.Debug().Int("dirId", audioFile.DirId).Msg("Failed to calculate absolute path to directory")
+		log.Debug().Int("dirId", audioFile.DirId).Msg("Failed to resolve path to file")
 		return "", err
 	}
 
-	absolutePath = filepath.Join(absolutePathToDir, audioFile.Filename)
-
 	log.Debug().Int("audioFileId", audioFileId).Str("absolutePath", absolutePath).Msg("Calculating absolute path to audio file")
 	return absolutePath, nil
 }
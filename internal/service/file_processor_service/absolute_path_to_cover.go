@@ -3,7 +3,9 @@ package file_processor_service
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
-	"path/filepath"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"os"
 )
 
 func (s *Service) AbsolutePathToCover(tx *sqlx.Tx, coverId int) (absolutePath string, err error) {
@@ -15,14 +17,23 @@ func (s *Service) AbsolutePathToCover(tx *sqlx.Tx, coverId int) (absolutePath st
 		return "", err
 	}
 
-	absolutePathToDir, err := s.DirService.AbsolutePath(tx, cover.DirId)
+	if cover.Source == model.CoverSourceEmbedded {
+		absolutePath = s.CoverService.EmbeddedPath(cover)
+		if _, err = os.Stat(absolutePath); os.IsNotExist(err) {
+			log.Debug().Int("coverId", coverId).Str("absolutePath", absolutePath).Msg("Picture of embedded cover not found")
+			return "", errors.NotFound{Resource: "picture of embedded cover"}
+		}
+		log.Debug().Int("coverId", coverId).Str("absolutePath", absolutePath).Msg("Calculating absolute path to cover")
+		return absolutePath, nil
+	}
+
+	// Symlinks are resolved the way the scan followed them
+	absolutePath, err = s.DirService.ResolvePath(tx, *cover.DirId, cover.Filename)
 	if err != nil {
-		log.Debug().Int("dirId", cover.DirId).Msg("Failed to calculate absolute path to directory")
+		log.Debug().Int("dirId", *cover.DirId).Msg("Failed to resolve path to file")
 		return "", err
 	}
 
-	absolutePath = filepath.Join(absolutePathToDir, cover.Filename)
-
 	log.Debug().Int("coverId", coverId).Str("absolutePath", absolutePath).Msg("Calculating absolute path to cover")
 	return absolutePath, nil
 }
@@ -7,17 +7,25 @@ import (
 	"sort"
 )
 
+// CalcBestCovers returns ids of covers resolved for the audio files, the most frequent first
 func (s *Service) CalcBestCovers(tx *sqlx.Tx, audioFileIds []int) (bestCoverIds []int, err error) {
 	coverIds := make([]int, 0)
+	resolvedDirIds := make(map[int]bool)
 	for _, audioFileId := range audioFileIds {
-		cover, err := s.GetCoverForAudioFile(tx, audioFileId)
+		audioFile, err := s.AudioFileService.GetAudioFile(tx, audioFileId)
 		if _, ok := err.(errors.NotFound); ok {
 			continue
 		} else if err != nil {
-			log.Error().Err(err).Msg("Failed to get cover")
+			log.Error().Err(err).Msg("Failed to get audio file")
 			return make([]int, 0), err
 		}
-		coverIds = append(coverIds, cover.CoverId)
+		coverId, err := s.resolvedCoverId(tx, audioFile, resolvedDirIds)
+		if err != nil {
+			return make([]int, 0), err
+		}
+		if coverId != nil {
+			coverIds = append(coverIds, *coverId)
+		}
 	}
 
 	bestCoverIds = uniqueSortedByFrequency(coverIds)
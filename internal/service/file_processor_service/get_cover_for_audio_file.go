@@ -8,6 +8,8 @@ import (
 	"music-files/internal/model"
 )
 
+// GetCoverForAudioFile returns the cover resolved for the audio file by the scan, see dir_service.ResolveCovers.
+// A file stored before scans started resolving covers gets its cover resolved on the first request
 func (s *Service) GetCoverForAudioFile(tx *sqlx.Tx, audioFileId int) (cover model.Cover, err error) {
 	log.Debug().Int("audioFileId", audioFileId).Msg("Getting cover for audioFile")
 
@@ -16,38 +18,21 @@ func (s *Service) GetCoverForAudioFile(tx *sqlx.Tx, audioFileId int) (cover mode
 		log.Error().Err(err).Int("audioFileId", audioFileId).Msg("Failed to get audio file")
 		return model.Cover{}, err
 	}
-
-	dir, err := s.DirService.GetDir(tx, audioFile.DirId)
+	coverId, err := s.resolvedCoverId(tx, audioFile, nil)
 	if err != nil {
-		log.Error().Err(err).Int("dirId", audioFile.DirId).Msg("Failed to get audio file's directory")
 		return model.Cover{}, err
 	}
+	if coverId == nil {
+		log.Debug().Int("audioFileId", audioFileId).Msg("Cover for audio file not found")
+		return model.Cover{}, errors.NotFound{Resource: fmt.Sprintf("cover for audio_file with id=%d", audioFileId)}
+	}
 
-	covers, err := s.CoverService.GetAllByDir(tx, dir.DirId)
+	cover, err = s.CoverService.GetCover(tx, *coverId)
 	if err != nil {
-		log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get covers in directory")
+		log.Error().Err(err).Int("coverId", *coverId).Msg("Failed to get cover")
 		return model.Cover{}, err
 	}
 
-	for (len(covers) == 0) && (dir.ParentDirId != nil) {
-		dir, err = s.DirService.GetDir(tx, *dir.ParentDirId)
-		if err != nil {
-			log.Error().Err(err).Int("dirId", audioFile.DirId).Msg("Failed to get subdirectories")
-			return model.Cover{}, err
-		}
-
-		covers, err = s.CoverService.GetAllByDir(tx, dir.DirId)
-		if err != nil {
-			log.Error().Err(err).Int("dirId", dir.DirId).Msg("Failed to get covers in directory")
-			return model.Cover{}, err
-		}
-	}
-
-	if len(covers) == 0 {
-		log.Debug().Int("audioFileId", audioFileId).Msg("Cover for audio file not found")
-		return model.Cover{}, errors.NotFound{Resource: fmt.Sprintf("cover for audio_file with id=%d", audioFileId)}
-	}
-
-	log.Debug().Int("coverId", covers[0].CoverId).Msg("Cover for audioFile got successfully")
-	return covers[0], nil
+	log.Debug().Int("coverId", cover.CoverId).Msg("Cover for audioFile got successfully")
+	return cover, nil
 }
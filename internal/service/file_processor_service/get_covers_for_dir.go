@@ -0,0 +1,56 @@
+package file_processor_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// Number of covers of a mosaic, the larger grid is taken when there are enough different covers
+var mosaicSizes = []int{9, 4}
+
+// GetCoversForDir returns what the directory is shown with: its own cover, see dir_service.GetCover.
+// A directory without one, like an artist or a compilation, gets covers of audio files below it for a mosaic,
+// 9 or 4 of the most used ones that can be decoded. With fewer different covers the most used one is returned alone
+func (s *Service) GetCoversForDir(tx *sqlx.Tx, dirId int) (covers []model.Cover, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Getting covers for directory")
+
+	cover, found, err := s.DirService.GetCover(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get cover of directory")
+		return nil, err
+	}
+	if found {
+		log.Debug().Int("dirId", dirId).Int("coverId", cover.CoverId).Msg("Own cover of directory got successfully")
+		return []model.Cover{cover}, nil
+	}
+
+	used, err := s.CoverService.GetAllUsedInTree(tx, dirId, mosaicSizes[0])
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get covers used below directory")
+		return nil, err
+	}
+	if len(used) == 0 {
+		log.Debug().Int("dirId", dirId).Msg("Covers for directory not found")
+		return nil, errors.NotFound{Resource: fmt.Sprintf("cover for directory with id=%d", dirId)}
+	}
+	var tiles []model.Cover
+	for _, cover := range used {
+		if utils.IsDecodableImage(cover.Filename) {
+			tiles = append(tiles, cover)
+		}
+	}
+	covers = used[:1]
+	for _, size := range mosaicSizes {
+		if len(tiles) >= size {
+			covers = tiles[:size]
+			break
+		}
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfCovers", len(covers)).Msg("Covers for directory got successfully")
+	return covers, nil
+}
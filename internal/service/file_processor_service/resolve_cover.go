@@ -0,0 +1,44 @@
+package file_processor_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// resolvedCoverId returns the cover stored for the audio file. Directories scanned before scans started resolving
+// covers aren't marked resolved, their covers are resolved on the fly once and stored.
+// resolvedDirIds remembers directories checked by the caller already, nil checks every time
+func (s *Service) resolvedCoverId(tx *sqlx.Tx, audioFile model.AudioFile, resolvedDirIds map[int]bool) (coverId *int, err error) {
+	if audioFile.CoverId != nil || resolvedDirIds[audioFile.DirId] {
+		return audioFile.CoverId, nil
+	}
+	dir, err := s.DirService.GetDir(tx, audioFile.DirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", audioFile.DirId).Msg("Failed to get directory of audio file")
+		return nil, err
+	}
+	if dir.CoversResolved {
+		// The audio file has no cover
+		if resolvedDirIds != nil {
+			resolvedDirIds[audioFile.DirId] = true
+		}
+		return nil, nil
+	}
+
+	err = s.DirService.ResolveCovers(tx, audioFile.DirId, false)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", audioFile.DirId).Msg("Failed to resolve covers of directory")
+		return nil, err
+	}
+	if resolvedDirIds != nil {
+		resolvedDirIds[audioFile.DirId] = true
+	}
+
+	audioFile, err = s.AudioFileService.GetAudioFile(tx, audioFile.AudioFileId)
+	if err != nil {
+		log.Error().Err(err).Int("audioFileId", audioFile.AudioFileId).Msg("Failed to get audio file")
+		return nil, err
+	}
+	return audioFile.CoverId, nil
+}
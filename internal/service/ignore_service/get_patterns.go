@@ -0,0 +1,57 @@
+package ignore_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+)
+
+// GetPatterns returns ignore patterns configured for the root in the order they apply
+func (s *Service) GetPatterns(tx *sqlx.Tx, rootDirId int) (patterns []string, err error) {
+	log.Debug().Int("rootDirId", rootDirId).Msg("Getting ignore patterns of root")
+
+	err = s.checkRoot(tx, rootDirId)
+	if err != nil {
+		return make([]string, 0), err
+	}
+
+	ignorePatterns, err := s.IgnorePatternRepo.ReadAllByDir(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to get ignore patterns")
+		return make([]string, 0), err
+	}
+	patterns = make([]string, len(ignorePatterns))
+	for i, ignorePattern := range ignorePatterns {
+		patterns[i] = ignorePattern.Pattern
+	}
+
+	log.Debug().Int("rootDirId", rootDirId).Int("countOfPatterns", len(patterns)).Msg("Ignore patterns of root got successfully")
+	return patterns, nil
+}
+
+// checkRoot makes sure the directory exists and is a root
+func (s *Service) checkRoot(tx *sqlx.Tx, dirId int) (err error) {
+	exists, err := s.DirRepo.IsExists(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence")
+		return err
+	}
+	if !exists {
+		err = errors.NotFound{Resource: fmt.Sprintf("directory with id=%d", dirId)}
+		log.Error().Err(err).Int("dirId", dirId).Msg("Directory not found")
+		return err
+	}
+
+	dir, err := s.DirRepo.Read(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory")
+		return err
+	}
+	if dir.ParentDirId != nil {
+		err = errors.BadRequest{Message: fmt.Sprintf("directory with id=%d is not root", dirId)}
+		log.Error().Err(err).Int("dirId", dirId).Msg("Directory is not root")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package ignore_service
+
+import (
+	"music-files/internal/database/repository/dir_repo"
+	"music-files/internal/database/repository/ignore_pattern_repo"
+)
+
+type Service struct {
+	IgnorePatternRepo ignore_pattern_repo.Repo
+	DirRepo           dir_repo.Repo
+}
+
+func NewService(ignorePatternRepo ignore_pattern_repo.Repo, dirRepo dir_repo.Repo) (s *Service) {
+
+	s = &Service{
+		IgnorePatternRepo: ignorePatternRepo,
+		DirRepo:           dirRepo,
+	}
+
+	return s
+}
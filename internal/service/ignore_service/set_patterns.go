@@ -0,0 +1,46 @@
+package ignore_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// SetPatterns replaces ignore patterns of the root. They take effect on the next scan
+func (s *Service) SetPatterns(tx *sqlx.Tx, rootDirId int, patterns []string) (err error) {
+	log.Debug().Int("rootDirId", rootDirId).Int("countOfPatterns", len(patterns)).Msg("Setting ignore patterns of root")
+
+	err = s.checkRoot(tx, rootDirId)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range patterns {
+		_, _, err = utils.ParseIgnorePattern(pattern)
+		if err != nil {
+			log.Error().Err(err).Str("pattern", pattern).Msg("Invalid ignore pattern")
+			return errors.BadRequest{Message: err.Error()}
+		}
+	}
+
+	err = s.IgnorePatternRepo.DeleteAllByDir(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to delete ignore patterns")
+		return err
+	}
+	for i, pattern := range patterns {
+		err = s.IgnorePatternRepo.Create(tx, model.IgnorePattern{
+			DirId:    rootDirId,
+			Position: i,
+			Pattern:  pattern,
+		})
+		if err != nil {
+			log.Error().Err(err).Int("rootDirId", rootDirId).Str("pattern", pattern).Msg("Failed to create ignore pattern")
+			return err
+		}
+	}
+
+	log.Debug().Int("rootDirId", rootDirId).Msg("Ignore patterns of root set successfully")
+	return nil
+}
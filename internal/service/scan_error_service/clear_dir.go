@@ -0,0 +1,20 @@
+package scan_error_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// ClearDir forgets errors of the directory and its files before it is scanned again
+func (s *Service) ClearDir(tx *sqlx.Tx, dirId int) (err error) {
+	log.Debug().Int("dirId", dirId).Msg("Clearing scan errors of directory")
+
+	err = s.ScanErrorRepo.DeleteAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to clear scan errors of directory")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Msg("Scan errors of directory cleared successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package scan_error_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (s *Service) GetAll(tx *sqlx.Tx) (scanErrors []model.ScanError, err error) {
+	log.Debug().Msg("Getting all scan errors")
+
+	scanErrors, err = s.ScanErrorRepo.ReadAll(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get all scan errors")
+		return make([]model.ScanError, 0), err
+	}
+
+	log.Debug().Int("countOfScanErrors", len(scanErrors)).Msg("All scan errors got successfully")
+	return scanErrors, nil
+}
@@ -0,0 +1,32 @@
+package scan_error_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+func (s *Service) GetAllByDir(tx *sqlx.Tx, dirId int) (scanErrors []model.ScanError, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Getting scan errors of directory")
+
+	exists, err := s.DirRepo.IsExists(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to check directory existence")
+		return make([]model.ScanError, 0), err
+	}
+	if !exists {
+		log.Error().Int("dirId", dirId).Msg("Directory not found")
+		return make([]model.ScanError, 0), errors.NotFound{Resource: fmt.Sprintf("directory with id=%d", dirId)}
+	}
+
+	scanErrors, err = s.ScanErrorRepo.ReadAllByDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get scan errors of directory")
+		return make([]model.ScanError, 0), err
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors of directory got successfully")
+	return scanErrors, nil
+}
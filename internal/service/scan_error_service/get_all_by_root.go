@@ -0,0 +1,33 @@
+package scan_error_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// GetAllByRoot returns errors of the directory and everything below it
+func (s *Service) GetAllByRoot(tx *sqlx.Tx, rootDirId int) (scanErrors []model.ScanError, err error) {
+	log.Debug().Int("rootDirId", rootDirId).Msg("Getting scan errors of directory tree")
+
+	exists, err := s.DirRepo.IsExists(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to check directory existence")
+		return make([]model.ScanError, 0), err
+	}
+	if !exists {
+		log.Error().Int("rootDirId", rootDirId).Msg("Directory not found")
+		return make([]model.ScanError, 0), errors.NotFound{Resource: fmt.Sprintf("directory with id=%d", rootDirId)}
+	}
+
+	scanErrors, err = s.ScanErrorRepo.ReadAllByRoot(tx, rootDirId)
+	if err != nil {
+		log.Error().Err(err).Int("rootDirId", rootDirId).Msg("Failed to get scan errors of directory tree")
+		return make([]model.ScanError, 0), err
+	}
+
+	log.Debug().Int("rootDirId", rootDirId).Int("countOfScanErrors", len(scanErrors)).Msg("Scan errors of directory tree got successfully")
+	return scanErrors, nil
+}
@@ -0,0 +1,26 @@
+package scan_error_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// Record stores why the file could not be scanned. Empty filename means the directory itself
+func (s *Service) Record(tx *sqlx.Tx, dirId int, filename string, stage string, reason error) (err error) {
+	log.Debug().Int("dirId", dirId).Str("filename", filename).Str("stage", stage).Msg("Recording scan error")
+
+	err = s.ScanErrorRepo.Create(tx, model.ScanError{
+		DirId:    dirId,
+		Filename: filename,
+		Stage:    stage,
+		Reason:   reason.Error(),
+	})
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Str("filename", filename).Msg("Failed to record scan error")
+		return err
+	}
+
+	log.Debug().Int("dirId", dirId).Str("filename", filename).Msg("Scan error recorded successfully")
+	return nil
+}
@@ -0,0 +1,21 @@
+package scan_error_service
+
+import (
+	"music-files/internal/database/repository/dir_repo"
+	"music-files/internal/database/repository/scan_error_repo"
+)
+
+type Service struct {
+	ScanErrorRepo scan_error_repo.Repo
+	DirRepo       dir_repo.Repo
+}
+
+func NewService(scanErrorRepo scan_error_repo.Repo, dirRepo dir_repo.Repo) (s *Service) {
+
+	s = &Service{
+		ScanErrorRepo: scanErrorRepo,
+		DirRepo:       dirRepo,
+	}
+
+	return s
+}
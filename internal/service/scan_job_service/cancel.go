@@ -0,0 +1,53 @@
+package scan_job_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"time"
+)
+
+// Cancel stops a running job or removes a queued one from execution.
+// A running job is marked as cancelled by the worker once the scan has stopped
+func (s *Service) Cancel(tx *sqlx.Tx, scanJobId int) (scanJob model.ScanJob, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Cancelling scan job")
+
+	scanJob, err = s.GetJob(tx, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get scan job")
+		return model.ScanJob{}, err
+	}
+	if scanJob.IsFinished() {
+		err = errors.Conflict{Message: fmt.Sprintf("scan job with id=%d is already %s", scanJobId, scanJob.Status)}
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Scan job already finished")
+		return model.ScanJob{}, err
+	}
+
+	// A queued job is cancelled only while it is still queued, so the worker cannot start it at the same time
+	now := time.Now()
+	cancelled := scanJob
+	cancelled.Status = model.ScanJobStatusCancelled
+	cancelled.FinishedAt = &now
+	updated, err := s.ScanJobRepo.UpdateIfStatus(tx, scanJobId, model.ScanJobStatusQueued, cancelled)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to update scan job")
+		return model.ScanJob{}, err
+	}
+	if updated {
+		log.Debug().Int("scanJobId", scanJobId).Msg("Queued scan job cancelled")
+		return cancelled, nil
+	}
+
+	// The worker has already taken the job. It is registered before it is started, so it can be stopped through the registry
+	if running, ok := s.jobs.get(scanJobId); ok {
+		running.cancel()
+		log.Debug().Int("scanJobId", scanJobId).Msg("Running scan job cancelled")
+		return s.withLiveProgress(scanJob), nil
+	}
+
+	err = errors.Conflict{Message: fmt.Sprintf("scan job with id=%d has already finished", scanJobId)}
+	log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Scan job finished while being cancelled")
+	return model.ScanJob{}, err
+}
@@ -0,0 +1,208 @@
+package scan_job_service
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+)
+
+// dryRun walks the same directories as a real scan, each directory in its own short transaction that is
+// rolled back, so real scans and other writers are not blocked for long. Only the report is stored and nothing
+// is written to disk. Directories found by the dry run don't exist outside its transaction, so they are scanned
+// together with the directory they were found in. Entities found missing are deleted at the end, the same way
+// a real scan does it, except those reported as moved
+func (s *Service) dryRun(scanJob model.ScanJob, session *dir_service.ScanSession) (err error) {
+	var dirIds []int
+	err = s.TransactionManager.WithRolledBackTransaction(func(tx *sqlx.Tx) (err error) {
+		dirIds, err = s.targetDirIds(tx, scanJob)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	stack := make([]int, 0, len(dirIds))
+	for i := len(dirIds) - 1; i >= 0; i-- {
+		stack = append(stack, dirIds[i])
+	}
+
+	var entries []model.ScanReportEntry
+	var deletions []model.ScanPendingDeletion
+	for len(stack) > 0 {
+		if err = session.Ctx.Err(); err != nil {
+			return err
+		}
+		dirId := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var subDirIds []int
+		var dirEntries []model.ScanReportEntry
+		var dirDeletions []model.ScanPendingDeletion
+		err = s.TransactionManager.WithRolledBackTransaction(func(tx *sqlx.Tx) (err error) {
+			subDirIds, dirEntries, dirDeletions, err = s.dryRunDir(tx, scanJob, session, dirId)
+			return err
+		})
+		if _, ok := err.(errors.NotFound); ok {
+			log.Info().Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirId).Msg("Directory of scan job no longer exists")
+			continue
+		} else if stderrors.Is(err, context.Canceled) {
+			return err
+		} else if err != nil {
+			log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirId).Msg("Failed to scan directory, skipping it")
+			session.Progress.DirFailed()
+			continue
+		}
+
+		entries = append(entries, dirEntries...)
+		deletions = append(deletions, dirDeletions...)
+		for i := len(subDirIds) - 1; i >= 0; i-- {
+			stack = append(stack, subDirIds[i])
+		}
+	}
+
+	for _, deletion := range deletions {
+		if err = session.Ctx.Err(); err != nil {
+			return err
+		}
+
+		err = s.TransactionManager.WithRolledBackTransaction(func(tx *sqlx.Tx) (err error) {
+			session.Report.Drain()
+			return s.DirService.DeletePending(tx, session, deletion)
+		})
+		if err != nil {
+			log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Failed to delete entity missing on disk, skipping it")
+			continue
+		}
+		entries = append(entries, session.Report.Drain()...)
+	}
+
+	return s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return s.saveReportEntries(tx, scanJob.ScanJobId, withoutMovedDeletions(entries), true)
+	})
+}
+
+// dryRunDir scans the directory and the directories found in it. Returns subdirectories that already existed,
+// they are scanned in transactions of their own
+func (s *Service) dryRunDir(tx *sqlx.Tx, scanJob model.ScanJob, session *dir_service.ScanSession, dirId int) (existingSubDirIds []int, entries []model.ScanReportEntry, deletions []model.ScanPendingDeletion, err error) {
+	session.Report.Drain()
+	session.PendingDeletions.Drain()
+	subDirIds, err := s.DirService.ScanDir(tx, session, dirId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	entries = session.Report.Drain()
+	deletions = session.PendingDeletions.Drain()
+
+	stack := make([]int, 0)
+	appendSubDirs := func(subDirIds []int, entries []model.ScanReportEntry) {
+		created := createdDirIds(entries)
+		for i := len(subDirIds) - 1; i >= 0; i-- {
+			if created[subDirIds[i]] {
+				stack = append(stack, subDirIds[i])
+			} else {
+				existingSubDirIds = append(existingSubDirIds, subDirIds[i])
+			}
+		}
+	}
+	appendSubDirs(subDirIds, entries)
+
+	for len(stack) > 0 {
+		if err = session.Ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		subDirId := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// A failing directory found by the dry run is skipped without losing the rest of the transaction
+		_, err = tx.Exec("SAVEPOINT dry_run_dir")
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to create savepoint")
+			return nil, nil, nil, err
+		}
+		subDirIds, err = s.DirService.ScanDir(tx, session, subDirId)
+		if stderrors.Is(err, context.Canceled) {
+			return nil, nil, nil, err
+		} else if err != nil {
+			log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Int("dirId", subDirId).Msg("Failed to scan directory, skipping it")
+			session.Progress.DirFailed()
+			session.Report.Drain()
+			session.PendingDeletions.Drain()
+			_, err = tx.Exec("ROLLBACK TO SAVEPOINT dry_run_dir")
+			if err != nil {
+				log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to roll back to savepoint")
+				return nil, nil, nil, err
+			}
+			continue
+		}
+		_, err = tx.Exec("RELEASE SAVEPOINT dry_run_dir")
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to release savepoint")
+			return nil, nil, nil, err
+		}
+
+		subDirEntries := session.Report.Drain()
+		entries = append(entries, subDirEntries...)
+		deletions = append(deletions, session.PendingDeletions.Drain()...)
+		appendSubDirs(subDirIds, subDirEntries)
+	}
+
+	// Existing subdirectories are popped from the end of the stack of the caller
+	for i, j := 0, len(existingSubDirIds)-1; i < j; i, j = i+1, j-1 {
+		existingSubDirIds[i], existingSubDirIds[j] = existingSubDirIds[j], existingSubDirIds[i]
+	}
+	return existingSubDirIds, entries, deletions, nil
+}
+
+// createdDirIds returns ids of the directories the entries report as created
+func createdDirIds(entries []model.ScanReportEntry) (dirIds map[int]bool) {
+	dirIds = make(map[int]bool)
+	for _, entry := range entries {
+		if entry.Action == model.ScanReportActionCreated && entry.Entity == model.ScanReportEntityDirectory && entry.EntityId != nil {
+			dirIds[*entry.EntityId] = true
+		}
+	}
+	return dirIds
+}
+
+// withoutMovedDeletions drops deletions of entities reported as moved. Every directory of a dry run is rolled
+// back, so a moved entity is still found at its old location when missing entities are deleted
+func withoutMovedDeletions(entries []model.ScanReportEntry) (filtered []model.ScanReportEntry) {
+	type entityKey struct {
+		entity   string
+		entityId int
+	}
+	moved := make(map[entityKey]bool)
+	for _, entry := range entries {
+		if entry.Action == model.ScanReportActionMoved && entry.EntityId != nil {
+			moved[entityKey{entry.Entity, *entry.EntityId}] = true
+		}
+	}
+
+	filtered = make([]model.ScanReportEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Action == model.ScanReportActionDeleted && entry.EntityId != nil && moved[entityKey{entry.Entity, *entry.EntityId}] {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// saveReportEntries stores changes of the job. Ids of entities created by a dry run are dropped, they were rolled back
+func (s *Service) saveReportEntries(tx *sqlx.Tx, scanJobId int, entries []model.ScanReportEntry, dryRun bool) (err error) {
+	for _, entry := range entries {
+		entry.ScanJobId = scanJobId
+		if dryRun && entry.Action == model.ScanReportActionCreated {
+			entry.EntityId = nil
+		}
+		err = s.ScanReportEntryRepo.Create(tx, entry)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to save scan report entry")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package scan_job_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+)
+
+func (s *Service) GetJob(tx *sqlx.Tx, scanJobId int) (scanJob model.ScanJob, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Getting scan job")
+
+	exists, err := s.ScanJobRepo.IsExists(tx, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to check scan job existence")
+		return model.ScanJob{}, err
+	}
+	if !exists {
+		log.Error().Int("scanJobId", scanJobId).Msg("Scan job not found")
+		return model.ScanJob{}, errors.NotFound{Resource: fmt.Sprintf("scan job with id=%d", scanJobId)}
+	}
+
+	scanJob, err = s.readJob(tx, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to read scan job")
+		return model.ScanJob{}, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Msg("Scan job got successfully")
+	return s.withLiveProgress(scanJob), nil
+}
+
+// readJob reads the job together with its target directories
+func (s *Service) readJob(tx *sqlx.Tx, scanJobId int) (scanJob model.ScanJob, err error) {
+	scanJob, err = s.ScanJobRepo.Read(tx, scanJobId)
+	if err != nil {
+		return model.ScanJob{}, err
+	}
+
+	scanJob.DirIds, err = s.ScanJobDirRepo.ReadAllByScanJob(tx, scanJobId)
+	if err != nil {
+		return model.ScanJob{}, err
+	}
+
+	return scanJob, nil
+}
+
+// withLiveProgress replaces the stored counters of a running job with the current ones
+func (s *Service) withLiveProgress(scanJob model.ScanJob) model.ScanJob {
+	running, ok := s.jobs.get(scanJob.ScanJobId)
+	if !ok {
+		return scanJob
+	}
+	session := running.getSession()
+	if session == nil {
+		return scanJob
+	}
+
+	return withProgress(scanJob, session.Progress.Snapshot())
+}
+
+func withProgress(scanJob model.ScanJob, progress dir_service.ScanProgressSnapshot) model.ScanJob {
+	scanJob.DirsVisited = progress.DirsVisited
+	scanJob.DirsFailed = progress.DirsFailed
+	scanJob.FilesHashed = progress.FilesHashed
+	scanJob.BytesProcessed = progress.BytesProcessed
+	if progress.CurrentPath != "" {
+		scanJob.CurrentPath = &progress.CurrentPath
+	}
+	return scanJob
+}
+
+// progressOf returns the counters stored in the job
+func progressOf(scanJob model.ScanJob) (progress dir_service.ScanProgressSnapshot) {
+	progress = dir_service.ScanProgressSnapshot{
+		DirsVisited:    scanJob.DirsVisited,
+		DirsFailed:     scanJob.DirsFailed,
+		FilesHashed:    scanJob.FilesHashed,
+		BytesProcessed: scanJob.BytesProcessed,
+	}
+	if scanJob.CurrentPath != nil {
+		progress.CurrentPath = *scanJob.CurrentPath
+	}
+	return progress
+}
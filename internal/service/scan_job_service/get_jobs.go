@@ -0,0 +1,29 @@
+package scan_job_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+func (s *Service) GetJobs(tx *sqlx.Tx) (scanJobs []model.ScanJob, err error) {
+	log.Debug().Msg("Getting scan jobs")
+
+	scanJobs, err = s.ScanJobRepo.ReadAll(tx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read scan jobs")
+		return make([]model.ScanJob, 0), err
+	}
+
+	for i := range scanJobs {
+		scanJobs[i].DirIds, err = s.ScanJobDirRepo.ReadAllByScanJob(tx, scanJobs[i].ScanJobId)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobs[i].ScanJobId).Msg("Failed to read directories of scan job")
+			return make([]model.ScanJob, 0), err
+		}
+		scanJobs[i] = s.withLiveProgress(scanJobs[i])
+	}
+
+	log.Debug().Int("countOfScanJobs", len(scanJobs)).Msg("Scan jobs got successfully")
+	return scanJobs, nil
+}
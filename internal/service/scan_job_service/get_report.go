@@ -0,0 +1,30 @@
+package scan_job_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// GetReport returns the job with all changes it has made so far
+func (s *Service) GetReport(tx *sqlx.Tx, scanJobId int) (report model.ScanReport, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Getting scan report")
+
+	scanJob, err := s.GetJob(tx, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get scan job")
+		return model.ScanReport{}, err
+	}
+
+	entries, err := s.ScanReportEntryRepo.ReadAllByScanJob(tx, scanJobId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to read scan report entries")
+		return model.ScanReport{}, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Int("countOfEntries", len(entries)).Msg("Scan report got successfully")
+	return model.ScanReport{
+		ScanJob: scanJob,
+		Entries: entries,
+	}, nil
+}
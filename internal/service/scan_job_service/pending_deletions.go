@@ -0,0 +1,94 @@
+package scan_job_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+)
+
+// savePendingDeletions stores entities found missing by a directory scan, they are deleted when the job ends
+func (s *Service) savePendingDeletions(tx *sqlx.Tx, scanJobId int, deletions []model.ScanPendingDeletion) (err error) {
+	for _, deletion := range deletions {
+		deletion.ScanJobId = scanJobId
+		err = s.ScanJobPendingDeletionRepo.Create(tx, deletion)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to save pending deletion")
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPendingDeletions deletes entities found missing once all directories are scanned, so moved files have been
+// claimed by their new location. Every deletion is committed in its own transaction, a failing one is skipped
+func (s *Service) applyPendingDeletions(scanJob model.ScanJob, session *dir_service.ScanSession) (err error) {
+	for {
+		if err = session.Ctx.Err(); err != nil {
+			return err
+		}
+
+		done := false
+		var deletion model.ScanPendingDeletion
+		var deleteErr error
+		err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+			exists, err := s.ScanJobPendingDeletionRepo.IsExistsByScanJob(tx, scanJob.ScanJobId)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				done = true
+				return nil
+			}
+			deletion, err = s.ScanJobPendingDeletionRepo.ReadFirstByScanJob(tx, scanJob.ScanJobId)
+			if err != nil {
+				return err
+			}
+
+			session.Report.Drain()
+			err = s.DirService.DeletePending(tx, session, deletion)
+			if err != nil {
+				deleteErr = err
+				return err
+			}
+			err = s.saveReportEntries(tx, scanJob.ScanJobId, session.Report.Drain(), false)
+			if err != nil {
+				return err
+			}
+			return s.ScanJobPendingDeletionRepo.Delete(tx, deletion.PendingDeletionId)
+		})
+		if err == nil {
+			if done {
+				return nil
+			}
+			continue
+		}
+		if deleteErr == nil {
+			return err
+		}
+
+		log.Warn().Err(deleteErr).Int("scanJobId", scanJob.ScanJobId).Str("entity", deletion.Entity).Int("entityId", deletion.EntityId).Msg("Failed to delete entity missing on disk, skipping it")
+		err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+			return s.ScanJobPendingDeletionRepo.Delete(tx, deletion.PendingDeletionId)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to skip pending deletion with id=%d: %w", deletion.PendingDeletionId, err)
+		}
+	}
+}
+
+// deleteUnusedEmbeddedCovers drops pictures left behind by audio files deleted or retagged during the job.
+// A failure doesn't fail the job, the next one cleans them up
+func (s *Service) deleteUnusedEmbeddedCovers(scanJob model.ScanJob) {
+	if scanJob.DryRun {
+		return
+	}
+	err := s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		_, err = s.DirService.CoverService.DeleteUnusedEmbedded(tx)
+		return err
+	})
+	if err != nil {
+		log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to delete unused embedded covers")
+	}
+}
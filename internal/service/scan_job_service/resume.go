@@ -0,0 +1,69 @@
+package scan_job_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// Resume puts a failed or cancelled job back in the queue. It continues from its checkpoint
+func (s *Service) Resume(scanJobId int) (resumedJob model.ScanJob, err error) {
+	log.Debug().Int("scanJobId", scanJobId).Msg("Resuming scan job")
+
+	err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		scanJob, err := s.GetJob(tx, scanJobId)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to get scan job")
+			return err
+		}
+		if scanJob.Status != model.ScanJobStatusFailed && scanJob.Status != model.ScanJobStatusCancelled {
+			err = errors.Conflict{Message: fmt.Sprintf("scan job with id=%d is %s, only failed or cancelled jobs can be resumed", scanJobId, scanJob.Status)}
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Scan job can't be resumed")
+			return err
+		}
+		// A dry run is not checkpointed and starts over
+		if scanJob.StartedAt != nil && !scanJob.DryRun {
+			pendingDirs, err := s.ScanJobPendingDirRepo.IsExistsByScanJob(tx, scanJobId)
+			if err != nil {
+				log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to check pending directories")
+				return err
+			}
+			pendingDeletions, err := s.ScanJobPendingDeletionRepo.IsExistsByScanJob(tx, scanJobId)
+			if err != nil {
+				log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to check pending deletions")
+				return err
+			}
+			if !pendingDirs && !pendingDeletions {
+				err = errors.Conflict{Message: fmt.Sprintf("scan job with id=%d has nothing left to scan", scanJobId)}
+				log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Scan job can't be resumed")
+				return err
+			}
+		}
+
+		scanJob.Status = model.ScanJobStatusQueued
+		scanJob.Error = nil
+		scanJob.FinishedAt = nil
+		err = s.ScanJobRepo.Update(tx, scanJobId, scanJob)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to update scan job")
+			return err
+		}
+		resumedJob = scanJob
+		return nil
+	})
+	if err != nil {
+		return model.ScanJob{}, err
+	}
+
+	if !s.jobs.enqueue(scanJobId) {
+		err = errors.Conflict{Message: fmt.Sprintf("scan queue is full, job with id=%d rejected", scanJobId)}
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to enqueue scan job")
+		s.finish(resumedJob, nil, err)
+		return model.ScanJob{}, err
+	}
+
+	log.Debug().Int("scanJobId", scanJobId).Msg("Scan job resumed successfully")
+	return resumedJob, nil
+}
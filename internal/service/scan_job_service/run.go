@@ -0,0 +1,312 @@
+package scan_job_service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+	"time"
+)
+
+// Start puts jobs interrupted by the previous run back in the queue and starts the worker executing queued jobs.
+// Interrupted jobs continue from their checkpoint
+func (s *Service) Start() (err error) {
+	log.Debug().Msg("Starting scan job worker")
+
+	err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		for _, status := range []string{model.ScanJobStatusRunning, model.ScanJobStatusQueued} {
+			staleJobs, err := s.ScanJobRepo.ReadAllByStatus(tx, status)
+			if err != nil {
+				log.Error().Err(err).Str("status", status).Msg("Failed to read stale scan jobs")
+				return err
+			}
+			for _, staleJob := range staleJobs {
+				if s.jobs.enqueue(staleJob.ScanJobId) {
+					staleJob.Status = model.ScanJobStatusQueued
+					log.Info().Int("scanJobId", staleJob.ScanJobId).Msg("Scan job interrupted by service restart is queued to resume")
+				} else {
+					message := "interrupted by service restart, scan queue is full"
+					now := time.Now()
+					staleJob.Status = model.ScanJobStatusFailed
+					staleJob.Error = &message
+					staleJob.FinishedAt = &now
+					log.Warn().Int("scanJobId", staleJob.ScanJobId).Msg("Scan job interrupted by service restart could not be queued")
+				}
+				err = s.ScanJobRepo.Update(tx, staleJob.ScanJobId, staleJob)
+				if err != nil {
+					log.Error().Err(err).Int("scanJobId", staleJob.ScanJobId).Msg("Failed to update stale scan job")
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to recover stale scan jobs")
+		return err
+	}
+
+	go s.work()
+
+	log.Debug().Msg("Scan job worker started")
+	return nil
+}
+
+// errJobNotQueued rolls back the start of a job whose status was changed while it was being started
+var errJobNotQueued = stderrors.New("scan job is no longer queued")
+
+func (s *Service) work() {
+	for scanJobId := range s.jobs.queue {
+		s.run(scanJobId)
+	}
+}
+
+func (s *Service) run(scanJobId int) {
+	log.Info().Int("scanJobId", scanJobId).Msg("Running scan job")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	running := &runningJob{
+		cancel: cancel,
+	}
+	s.jobs.add(scanJobId, running)
+	defer s.jobs.remove(scanJobId)
+
+	var scanJob model.ScanJob
+	err := s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		scanJob, err = s.readJob(tx, scanJobId)
+		if err != nil {
+			return err
+		}
+		if scanJob.Status != model.ScanJobStatusQueued {
+			return nil
+		}
+
+		if scanJob.DryRun {
+			err = s.ScanReportEntryRepo.DeleteAllByScanJob(tx, scanJobId)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			scanJob.StartedAt = &now
+		} else if scanJob.StartedAt == nil {
+			err = s.seedPendingDirs(tx, scanJob)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			scanJob.StartedAt = &now
+		}
+		scanJob.Status = model.ScanJobStatusRunning
+		scanJob.Error = nil
+		scanJob.FinishedAt = nil
+		updated, err := s.ScanJobRepo.UpdateIfStatus(tx, scanJobId, model.ScanJobStatusQueued, scanJob)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			// Cancelled after it was read, the prepared checkpoint is rolled back
+			return errJobNotQueued
+		}
+		return nil
+	})
+	if stderrors.Is(err, errJobNotQueued) {
+		log.Info().Int("scanJobId", scanJobId).Msg("Scan job cancelled before start")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to start scan job")
+		return
+	}
+	if scanJob.Status != model.ScanJobStatusRunning {
+		log.Info().Int("scanJobId", scanJobId).Str("status", scanJob.Status).Msg("Scan job skipped")
+		return
+	}
+
+	session := dir_service.NewScanSession(ctx, dir_service.ScanOptions{
+		Recursive:  scanJob.Recursive,
+		DeepVerify: scanJob.DeepVerify,
+		DryRun:     scanJob.DryRun,
+	})
+	session.Progress.Restore(progressOf(scanJob))
+	running.setSession(session)
+
+	if scanJob.DryRun {
+		err = s.dryRun(scanJob, session)
+	} else {
+		err = s.scanPendingDirs(scanJob, session)
+		if err == nil {
+			err = s.applyPendingDeletions(scanJob, session)
+		}
+		if err == nil {
+			s.deleteUnusedEmbeddedCovers(scanJob)
+			s.pregenerateThumbnails(scanJob, session)
+		}
+	}
+	s.finish(scanJob, session, err)
+}
+
+// targetDirIds returns directories the job starts from
+func (s *Service) targetDirIds(tx *sqlx.Tx, scanJob model.ScanJob) (dirIds []int, err error) {
+	if len(scanJob.DirIds) > 0 {
+		return scanJob.DirIds, nil
+	}
+
+	roots, err := s.DirService.GetRoots(tx)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to get root directories")
+		return nil, err
+	}
+	for _, root := range roots {
+		dirIds = append(dirIds, root.DirId)
+	}
+	return dirIds, nil
+}
+
+// seedPendingDirs fills the checkpoint of a job that has never been started with its target directories
+func (s *Service) seedPendingDirs(tx *sqlx.Tx, scanJob model.ScanJob) (err error) {
+	dirIds, err := s.targetDirIds(tx, scanJob)
+	if err != nil {
+		return err
+	}
+
+	// Pending directories are taken from the end, so they are added in reverse to keep the requested order
+	for i := len(dirIds) - 1; i >= 0; i-- {
+		err = s.ScanJobPendingDirRepo.Create(tx, scanJob.ScanJobId, dirIds[i])
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirIds[i]).Msg("Failed to add pending directory")
+			return err
+		}
+	}
+	return nil
+}
+
+// scanPendingDirs scans directories of the checkpoint one by one until none are left.
+// Every directory is committed in its own transaction together with the updated checkpoint,
+// so a failure or an interruption loses at most the directory being scanned
+func (s *Service) scanPendingDirs(scanJob model.ScanJob, session *dir_service.ScanSession) (err error) {
+	for {
+		if err = session.Ctx.Err(); err != nil {
+			return err
+		}
+
+		done := false
+		dirId := 0
+		var scanErr error
+		err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+			exists, err := s.ScanJobPendingDirRepo.IsExistsByScanJob(tx, scanJob.ScanJobId)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				done = true
+				return nil
+			}
+			dirId, err = s.ScanJobPendingDirRepo.ReadLastByScanJob(tx, scanJob.ScanJobId)
+			if err != nil {
+				return err
+			}
+
+			// Changes of a rolled back attempt are not part of the report
+			session.Report.Drain()
+			session.PendingDeletions.Drain()
+			subDirIds, err := s.DirService.ScanDir(tx, session, dirId)
+			if _, ok := err.(errors.NotFound); ok {
+				log.Info().Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirId).Msg("Directory of scan job no longer exists")
+			} else if err != nil {
+				scanErr = err
+				return err
+			}
+
+			err = s.saveReportEntries(tx, scanJob.ScanJobId, session.Report.Drain(), false)
+			if err != nil {
+				return err
+			}
+			err = s.savePendingDeletions(tx, scanJob.ScanJobId, session.PendingDeletions.Drain())
+			if err != nil {
+				return err
+			}
+
+			for i := len(subDirIds) - 1; i >= 0; i-- {
+				err = s.ScanJobPendingDirRepo.Create(tx, scanJob.ScanJobId, subDirIds[i])
+				if err != nil {
+					return err
+				}
+			}
+			return s.checkpoint(tx, scanJob, session, dirId)
+		})
+		if err == nil {
+			if done {
+				return nil
+			}
+			continue
+		}
+		if scanErr == nil || stderrors.Is(scanErr, context.Canceled) {
+			return err
+		}
+
+		log.Warn().Err(scanErr).Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirId).Msg("Failed to scan directory, skipping it")
+		session.Progress.DirFailed()
+		err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+			err = s.ScanErrorService.Record(tx, dirId, "", model.ScanErrorStageDirectory, scanErr)
+			if err != nil {
+				return err
+			}
+			return s.checkpoint(tx, scanJob, session, dirId)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to skip directory with id=%d: %w", dirId, err)
+		}
+	}
+}
+
+// checkpoint removes the processed directory from the pending ones and stores the current counters
+func (s *Service) checkpoint(tx *sqlx.Tx, scanJob model.ScanJob, session *dir_service.ScanSession, dirId int) (err error) {
+	err = s.ScanJobPendingDirRepo.Delete(tx, scanJob.ScanJobId, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Int("dirId", dirId).Msg("Failed to delete pending directory")
+		return err
+	}
+
+	scanJob = withProgress(scanJob, session.Progress.Snapshot())
+	err = s.ScanJobRepo.Update(tx, scanJob.ScanJobId, scanJob)
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to store scan job progress")
+		return err
+	}
+	return nil
+}
+
+// finish stores the outcome and the final counters of the job
+func (s *Service) finish(scanJob model.ScanJob, session *dir_service.ScanSession, scanErr error) {
+	now := time.Now()
+	scanJob.FinishedAt = &now
+	switch {
+	case scanErr == nil:
+		scanJob.Status = model.ScanJobStatusCompleted
+	case stderrors.Is(scanErr, context.Canceled):
+		scanJob.Status = model.ScanJobStatusCancelled
+	default:
+		message := scanErr.Error()
+		scanJob.Status = model.ScanJobStatusFailed
+		scanJob.Error = &message
+	}
+
+	if session != nil {
+		scanJob = withProgress(scanJob, session.Progress.Snapshot())
+	}
+
+	err := s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		return s.ScanJobRepo.Update(tx, scanJob.ScanJobId, scanJob)
+	})
+	if err != nil {
+		log.Error().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to save scan job result")
+		return
+	}
+
+	log.Info().Int("scanJobId", scanJob.ScanJobId).Str("status", scanJob.Status).Msg("Scan job finished")
+}
@@ -0,0 +1,119 @@
+package scan_job_service
+
+import (
+	"context"
+	"music-files/internal/database/repository/scan_job_dir_repo"
+	"music-files/internal/database/repository/scan_job_pending_deletion_repo"
+	"music-files/internal/database/repository/scan_job_pending_dir_repo"
+	"music-files/internal/database/repository/scan_job_repo"
+	"music-files/internal/database/repository/scan_report_entry_repo"
+	"music-files/internal/service"
+	"music-files/internal/service/dir_service"
+	"music-files/internal/service/scan_error_service"
+	"music-files/internal/service/thumbnail_service"
+	"sync"
+)
+
+const queueCapacity = 1024
+
+type Service struct {
+	ScanJobRepo    scan_job_repo.Repo
+	ScanJobDirRepo scan_job_dir_repo.Repo
+	// Checkpoint of a job: directories that are still to be scanned
+	ScanJobPendingDirRepo scan_job_pending_dir_repo.Repo
+	// Entities found missing on disk, deleted once all directories are scanned
+	ScanJobPendingDeletionRepo scan_job_pending_deletion_repo.Repo
+	ScanReportEntryRepo        scan_report_entry_repo.Repo
+
+	DirService         dir_service.Service
+	ScanErrorService   scan_error_service.Service
+	ThumbnailService   thumbnail_service.Service
+	TransactionManager service.TransactionManager
+
+	jobs *registry
+}
+
+func NewService(scanJobRepo scan_job_repo.Repo,
+	scanJobDirRepo scan_job_dir_repo.Repo,
+	scanJobPendingDirRepo scan_job_pending_dir_repo.Repo,
+	scanJobPendingDeletionRepo scan_job_pending_deletion_repo.Repo,
+	scanReportEntryRepo scan_report_entry_repo.Repo,
+	dirService dir_service.Service,
+	scanErrorService scan_error_service.Service,
+	thumbnailService thumbnail_service.Service,
+	transactionManager service.TransactionManager) (s *Service) {
+
+	s = &Service{
+		ScanJobRepo:                scanJobRepo,
+		ScanJobDirRepo:             scanJobDirRepo,
+		ScanJobPendingDirRepo:      scanJobPendingDirRepo,
+		ScanJobPendingDeletionRepo: scanJobPendingDeletionRepo,
+		ScanReportEntryRepo:        scanReportEntryRepo,
+		DirService:                 dirService,
+		ScanErrorService:           scanErrorService,
+		ThumbnailService:           thumbnailService,
+		TransactionManager:         transactionManager,
+		jobs: &registry{
+			queue:   make(chan int, queueCapacity),
+			running: make(map[int]*runningJob),
+		},
+	}
+
+	return s
+}
+
+// registry keeps the queue of submitted jobs and the jobs currently being executed.
+// It is shared by all copies of the Service
+type registry struct {
+	mu      sync.Mutex
+	queue   chan int
+	running map[int]*runningJob
+}
+
+type runningJob struct {
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	session *dir_service.ScanSession
+}
+
+func (j *runningJob) setSession(session *dir_service.ScanSession) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.session = session
+}
+
+func (j *runningJob) getSession() (session *dir_service.ScanSession) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.session
+}
+
+// enqueue puts the job in the queue without blocking. Returns false when the queue is full
+func (r *registry) enqueue(scanJobId int) (ok bool) {
+	select {
+	case r.queue <- scanJobId:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *registry) add(scanJobId int, job *runningJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running[scanJobId] = job
+}
+
+func (r *registry) remove(scanJobId int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, scanJobId)
+}
+
+func (r *registry) get(scanJobId int) (job *runningJob, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok = r.running[scanJobId]
+	return job, ok
+}
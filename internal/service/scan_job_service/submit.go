@@ -0,0 +1,59 @@
+package scan_job_service
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+)
+
+// Submit registers a new scan job with the options of scanJob and puts it in the queue.
+// Empty dirIds means scanning of all roots
+func (s *Service) Submit(scanJob model.ScanJob, dirIds []int) (submittedJob model.ScanJob, err error) {
+	log.Debug().Ints("dirIds", dirIds).Str("triggeredBy", scanJob.TriggeredBy).Msg("Submitting scan job")
+
+	err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		for _, dirId := range dirIds {
+			_, err = s.DirService.GetDir(tx, dirId)
+			if err != nil {
+				log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get directory to scan")
+				return err
+			}
+		}
+
+		scanJob.Status = model.ScanJobStatusQueued
+		scanJobId, err := s.ScanJobRepo.Create(tx, scanJob)
+		if err != nil {
+			log.Error().Err(err).Ints("dirIds", dirIds).Msg("Failed to create scan job")
+			return err
+		}
+		for _, dirId := range dirIds {
+			err = s.ScanJobDirRepo.Create(tx, scanJobId, dirId)
+			if err != nil {
+				log.Error().Err(err).Int("scanJobId", scanJobId).Int("dirId", dirId).Msg("Failed to add directory to scan job")
+				return err
+			}
+		}
+
+		submittedJob, err = s.readJob(tx, scanJobId)
+		if err != nil {
+			log.Error().Err(err).Int("scanJobId", scanJobId).Msg("Failed to read created scan job")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return model.ScanJob{}, err
+	}
+
+	if !s.jobs.enqueue(submittedJob.ScanJobId) {
+		err = errors.Conflict{Message: fmt.Sprintf("scan queue is full, job with id=%d rejected", submittedJob.ScanJobId)}
+		log.Error().Err(err).Int("scanJobId", submittedJob.ScanJobId).Msg("Failed to enqueue scan job")
+		s.finish(submittedJob, nil, err)
+		return model.ScanJob{}, err
+	}
+
+	log.Debug().Int("scanJobId", submittedJob.ScanJobId).Msg("Scan job submitted successfully")
+	return submittedJob, nil
+}
@@ -0,0 +1,49 @@
+package scan_job_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+)
+
+// pregenerateThumbnails renders configured variants of covers the job created or updated.
+// A failure doesn't fail the job, the variant is rendered on the first request instead
+func (s *Service) pregenerateThumbnails(scanJob model.ScanJob, session *dir_service.ScanSession) {
+	if scanJob.DryRun || !s.ThumbnailService.Pregenerates() {
+		return
+	}
+
+	var entries []model.ScanReportEntry
+	err := s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		entries, err = s.ScanReportEntryRepo.ReadAllByScanJob(tx, scanJob.ScanJobId)
+		return err
+	})
+	if err != nil {
+		log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Msg("Failed to get covers to pregenerate thumbnails for")
+		return
+	}
+
+	for _, entry := range entries {
+		if session.Ctx.Err() != nil {
+			return
+		}
+		if entry.Entity != model.ScanReportEntityCover || entry.EntityId == nil ||
+			(entry.Action != model.ScanReportActionCreated && entry.Action != model.ScanReportActionUpdated) {
+			continue
+		}
+
+		var cover model.Cover
+		var absolutePath string
+		err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+			cover, absolutePath, err = s.ThumbnailService.Locate(tx, *entry.EntityId)
+			return err
+		})
+		if err == nil {
+			err = s.ThumbnailService.Pregenerate(cover, absolutePath)
+		}
+		if err != nil {
+			log.Warn().Err(err).Int("scanJobId", scanJob.ScanJobId).Int("coverId", *entry.EntityId).Msg("Failed to pregenerate thumbnails of cover")
+		}
+	}
+}
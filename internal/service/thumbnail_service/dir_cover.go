@@ -0,0 +1,107 @@
+package thumbnail_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirCover is what a directory is shown with: a single cover or several covers for a mosaic
+type DirCover struct {
+	Covers        []model.Cover
+	AbsolutePaths []string
+}
+
+// IsMosaic tells whether the covers are arranged into a grid rather than shown as they are
+func (d DirCover) IsMosaic() bool {
+	return len(d.Covers) > 1
+}
+
+// LocateDir returns the covers the directory is shown with and paths to their pictures,
+// see file_processor_service.GetCoversForDir
+func (s *Service) LocateDir(tx *sqlx.Tx, dirId int) (dirCover DirCover, err error) {
+	log.Debug().Int("dirId", dirId).Msg("Locating cover of directory")
+
+	covers, err := s.FileProcessorService.GetCoversForDir(tx, dirId)
+	if err != nil {
+		log.Error().Err(err).Int("dirId", dirId).Msg("Failed to get covers for directory")
+		return DirCover{}, err
+	}
+	for _, cover := range covers {
+		absolutePath, err := s.FileProcessorService.AbsolutePathToCover(tx, cover.CoverId)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", cover.CoverId).Msg("Failed to calculate absolute path to cover")
+			return DirCover{}, err
+		}
+		dirCover.Covers = append(dirCover.Covers, cover)
+		dirCover.AbsolutePaths = append(dirCover.AbsolutePaths, absolutePath)
+	}
+
+	log.Debug().Int("dirId", dirId).Int("countOfCovers", len(covers)).Msg("Cover of directory located successfully")
+	return dirCover, nil
+}
+
+// GetMosaic opens the mosaic of the covers, rendering it on the first request. The caller closes the file. Sides
+// the spec leaves at zero take the configured mosaic size. Mosaics are keyed by sha256 of their covers, so once covers
+// below the directory change a new mosaic is rendered and the old one is evicted from the cache as unused
+func (s *Service) GetMosaic(dirCover DirCover, spec utils.ThumbnailSpec) (mosaic *os.File, validSpec utils.ThumbnailSpec, err error) {
+	log.Debug().Int("countOfCovers", len(dirCover.Covers)).Interface("spec", spec).Msg("Getting mosaic of covers")
+
+	if spec.Width == 0 {
+		spec.Width = s.mosaicSize
+	}
+	if spec.Height == 0 {
+		spec.Height = s.mosaicSize
+	}
+	spec.Mode = utils.ThumbnailModeFill
+	spec, err = spec.Validate(s.maxSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid mosaic spec")
+		return nil, utils.ThumbnailSpec{}, errors.BadRequest{Message: err.Error()}
+	}
+
+	checksums := make([]string, 0, len(dirCover.Covers))
+	for _, cover := range dirCover.Covers {
+		checksums = append(checksums, cover.Sha256)
+	}
+	hash := sha256.Sum256([]byte(strings.Join(checksums, ",")))
+	checksum := hex.EncodeToString(hash[:])
+	key := filepath.Join("mosaics", checksum[:2], checksum+"_"+spec.Key())
+
+	mosaic, found, err := s.cache.get(key)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to read thumbnail cache")
+		return nil, utils.ThumbnailSpec{}, err
+	}
+	if !found {
+		pictures := make([][]byte, 0, len(dirCover.AbsolutePaths))
+		for _, absolutePath := range dirCover.AbsolutePaths {
+			data, err := os.ReadFile(absolutePath)
+			if err != nil {
+				log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read picture of cover")
+				return nil, utils.ThumbnailSpec{}, err
+			}
+			pictures = append(pictures, data)
+		}
+		rendered, err := utils.RenderMosaic(pictures, spec)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to render mosaic")
+			return nil, utils.ThumbnailSpec{}, err
+		}
+		mosaic, err = s.cache.put(key, rendered)
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to store mosaic")
+			return nil, utils.ThumbnailSpec{}, err
+		}
+	}
+
+	log.Debug().Str("mosaicPath", mosaic.Name()).Msg("Mosaic of covers got successfully")
+	return mosaic, spec, nil
+}
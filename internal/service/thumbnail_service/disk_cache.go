@@ -0,0 +1,197 @@
+package thumbnail_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"io"
+	"io/fs"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskCache keeps rendered variants as files and evicts the least recently used ones once the total size
+// is over the limit. Modification time of a file is the time it was last used, so the order survives restarts
+type diskCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+
+	// Filled from the directory on first use, keyed by path relative to dir
+	entries    map[string]*cacheEntry
+	totalBytes int64
+}
+
+type cacheEntry struct {
+	sizeByte int64
+	usedAt   time.Time
+}
+
+func newDiskCache(dir string, maxBytes int64) (cache *diskCache) {
+	return &diskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+// get opens a cached file and marks it as used. The file is opened under the lock, so a concurrent eviction
+// can't remove it before it is read. The caller closes the file
+func (c *diskCache) get(key string) (file *os.File, found bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err = c.load(); err != nil {
+		return nil, false, err
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	absolutePath := filepath.Join(c.dir, key)
+	file, err = os.Open(absolutePath)
+	if os.IsNotExist(err) {
+		c.forget(key)
+		return nil, false, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open cached thumbnail")
+		return nil, false, err
+	}
+	now := time.Now()
+	err = os.Chtimes(absolutePath, now, now)
+	if err != nil {
+		log.Warn().Err(err).Str("absolutePath", absolutePath).Msg("Failed to mark cached thumbnail as used")
+	}
+	entry.usedAt = now
+	return file, true, nil
+}
+
+// put stores a file under the key, opens it and evicts the least recently used files if the cache is over the limit.
+// The caller closes the file
+func (c *diskCache) put(key string, data []byte) (file *os.File, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err = c.load(); err != nil {
+		return nil, err
+	}
+	absolutePath := filepath.Join(c.dir, key)
+	err = utils.WriteFileAtomically(absolutePath, data)
+	if err != nil {
+		return nil, err
+	}
+	file, err = os.Open(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to open stored thumbnail")
+		return nil, err
+	}
+
+	c.forget(key)
+	c.entries[key] = &cacheEntry{sizeByte: int64(len(data)), usedAt: time.Now()}
+	c.totalBytes += int64(len(data))
+	c.evict(key)
+	return file, nil
+}
+
+// uncached writes data to a file that isn't part of the cache. Its name is removed right away, the open file stays
+// readable until it is closed. The caller closes the file
+func (c *diskCache) uncached(data []byte) (file *os.File, err error) {
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	file, err = os.CreateTemp(c.dir, ".uncached.*")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// load indexes files already in the directory. Leftover temporary files are removed
+func (c *diskCache) load() (err error) {
+	if c.entries != nil {
+		return nil
+	}
+
+	entries := make(map[string]*cacheEntry)
+	var totalBytes int64
+	err = filepath.WalkDir(c.dir, func(absolutePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			os.Remove(absolutePath)
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		key, err := filepath.Rel(c.dir, absolutePath)
+		if err != nil {
+			return err
+		}
+		entries[key] = &cacheEntry{sizeByte: info.Size(), usedAt: info.ModTime()}
+		totalBytes += info.Size()
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Err(err).Str("dir", c.dir).Msg("Failed to index thumbnail cache")
+		return err
+	}
+
+	c.entries, c.totalBytes = entries, totalBytes
+	log.Info().Str("dir", c.dir).Int("countOfThumbnails", len(entries)).Int64("totalBytes", totalBytes).Msg("Thumbnail cache indexed")
+	return nil
+}
+
+// evict removes the least recently used files until the cache fits the limit. The file under keep stays
+func (c *diskCache) evict(keep string) {
+	if c.maxBytes <= 0 || c.totalBytes <= c.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].usedAt.Before(c.entries[keys[j]].usedAt)
+	})
+	for _, key := range keys {
+		if c.totalBytes <= c.maxBytes {
+			break
+		}
+		err := os.Remove(filepath.Join(c.dir, key))
+		if err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("key", key).Msg("Failed to evict thumbnail")
+			continue
+		}
+		c.forget(key)
+	}
+}
+
+func (c *diskCache) forget(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.totalBytes -= entry.sizeByte
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,78 @@
+package thumbnail_service
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+	"os"
+	"path/filepath"
+)
+
+// GetThumbnail opens a variant of the cover, rendering it on the first request. The caller closes the file.
+// Variants are keyed by the cover's sha256, so a changed picture never gets a stale variant
+func (s *Service) GetThumbnail(cover model.Cover, absolutePath string, spec utils.ThumbnailSpec) (thumbnail *os.File, validSpec utils.ThumbnailSpec, err error) {
+	log.Debug().Int("coverId", cover.CoverId).Interface("spec", spec).Msg("Getting thumbnail of cover")
+
+	spec, err = spec.Validate(s.maxSize)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", cover.CoverId).Msg("Invalid thumbnail spec")
+		return nil, utils.ThumbnailSpec{}, errors.BadRequest{Message: err.Error()}
+	}
+	if !utils.IsDecodableImage(cover.Filename) {
+		log.Error().Int("coverId", cover.CoverId).Str("filename", cover.Filename).Msg("Picture of cover can't be decoded")
+		return nil, utils.ThumbnailSpec{}, errors.BadRequest{Message: fmt.Sprintf("%s can't be resized or converted, request the original picture without parameters", cover.Filename)}
+	}
+
+	thumbnail, err = s.thumbnail(cover, absolutePath, spec)
+	if err != nil {
+		return nil, utils.ThumbnailSpec{}, err
+	}
+
+	log.Debug().Int("coverId", cover.CoverId).Str("thumbnailPath", thumbnail.Name()).Msg("Thumbnail of cover got successfully")
+	return thumbnail, spec, nil
+}
+
+func (s *Service) thumbnail(cover model.Cover, absolutePath string, spec utils.ThumbnailSpec) (thumbnail *os.File, err error) {
+	var key string
+	if isCacheable(cover) {
+		key = filepath.Join(cover.Sha256[:2], cover.Sha256+"_"+spec.Key())
+		thumbnail, found, err := s.cache.get(key)
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to read thumbnail cache")
+			return nil, err
+		}
+		if found {
+			return thumbnail, nil
+		}
+	}
+
+	data, err := os.ReadFile(absolutePath)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to read picture of cover")
+		return nil, err
+	}
+	rendered, err := utils.RenderThumbnail(data, spec)
+	if err != nil {
+		log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to render thumbnail")
+		return nil, err
+	}
+	if key == "" {
+		log.Warn().Int("coverId", cover.CoverId).Msg("Cover has no sha256, its thumbnail is not cached")
+		thumbnail, err = s.cache.uncached(rendered)
+	} else {
+		thumbnail, err = s.cache.put(key, rendered)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to store thumbnail")
+		return nil, err
+	}
+	return thumbnail, nil
+}
+
+// isCacheable tells whether variants of the cover can be keyed by its sha256, which is missing e.g. for a row
+// whose hash was never filled in
+func isCacheable(cover model.Cover) bool {
+	return len(cover.Sha256) >= 2
+}
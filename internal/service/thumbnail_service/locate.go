@@ -0,0 +1,26 @@
+package thumbnail_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+)
+
+// Locate returns the cover and the path to its picture, which variants are rendered from
+func (s *Service) Locate(tx *sqlx.Tx, coverId int) (cover model.Cover, absolutePath string, err error) {
+	log.Debug().Int("coverId", coverId).Msg("Locating picture of cover")
+
+	cover, err = s.CoverService.GetCover(tx, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Msg("Failed to get cover")
+		return model.Cover{}, "", err
+	}
+	absolutePath, err = s.FileProcessorService.AbsolutePathToCover(tx, coverId)
+	if err != nil {
+		log.Error().Err(err).Int("coverId", coverId).Msg("Failed to calculate absolute path to cover")
+		return model.Cover{}, "", err
+	}
+
+	log.Debug().Int("coverId", coverId).Str("absolutePath", absolutePath).Msg("Picture of cover located successfully")
+	return cover, absolutePath, nil
+}
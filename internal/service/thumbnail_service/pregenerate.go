@@ -0,0 +1,38 @@
+package thumbnail_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/utils"
+)
+
+// Pregenerates tells whether variants are rendered for covers found by scans
+func (s *Service) Pregenerates() (pregenerates bool) {
+	return len(s.pregenerate) > 0
+}
+
+// Pregenerate renders the configured variants of the cover that are not cached yet
+func (s *Service) Pregenerate(cover model.Cover, absolutePath string) (err error) {
+	log.Debug().Int("coverId", cover.CoverId).Int("countOfVariants", len(s.pregenerate)).Msg("Pregenerating thumbnails of cover")
+
+	if !utils.IsDecodableImage(cover.Filename) {
+		log.Debug().Int("coverId", cover.CoverId).Msg("Picture of cover can't be decoded, nothing to pregenerate")
+		return nil
+	}
+	if !isCacheable(cover) {
+		log.Debug().Int("coverId", cover.CoverId).Msg("Cover has no sha256, its thumbnails can't be cached")
+		return nil
+	}
+
+	for _, spec := range s.pregenerate {
+		thumbnail, err := s.thumbnail(cover, absolutePath, spec)
+		if err != nil {
+			log.Error().Err(err).Int("coverId", cover.CoverId).Str("variant", spec.Key()).Msg("Failed to pregenerate thumbnail")
+			return err
+		}
+		thumbnail.Close()
+	}
+
+	log.Debug().Int("coverId", cover.CoverId).Msg("Thumbnails of cover pregenerated successfully")
+	return nil
+}
@@ -0,0 +1,38 @@
+package thumbnail_service
+
+import (
+	"music-files/internal/config"
+	"music-files/internal/service/cover_service"
+	"music-files/internal/service/file_processor_service"
+	"music-files/internal/utils"
+)
+
+type Service struct {
+	CoverService         cover_service.Service
+	FileProcessorService file_processor_service.Service
+
+	// Shared by all copies of the Service, so the size limit holds for the whole cache
+	cache *diskCache
+	// Largest width or height that can be requested
+	maxSize int
+	// Variants rendered for covers found by scans
+	pregenerate []utils.ThumbnailSpec
+	// Width and height of mosaics of directories when the request doesn't set them
+	mosaicSize int
+}
+
+func NewService(coverService cover_service.Service,
+	fileProcessorService file_processor_service.Service,
+	thumbnailsConfig config.Thumbnails) (s *Service) {
+
+	s = &Service{
+		CoverService:         coverService,
+		FileProcessorService: fileProcessorService,
+		cache:                newDiskCache(thumbnailsConfig.CacheDir, thumbnailsConfig.CacheMaxBytes),
+		maxSize:              thumbnailsConfig.MaxSize,
+		pregenerate:          thumbnailsConfig.Pregenerate,
+		mosaicSize:           thumbnailsConfig.MosaicSize,
+	}
+
+	return s
+}
@@ -45,3 +45,20 @@ func (tm *TransactionManager) WithTransaction(do func(tx *sqlx.Tx) (err error))
 	err = do(tx)
 	return err
 }
+
+// WithRolledBackTransaction runs do in a transaction that is always rolled back, so nothing it writes is kept
+func (tm *TransactionManager) WithRolledBackTransaction(do func(tx *sqlx.Tx) (err error)) (err error) {
+	tx, err := tm.begin()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start a transaction")
+		return err
+	}
+
+	defer func() {
+		log.Debug().Msg("Rolling back transaction")
+		tx.Rollback()
+	}()
+
+	err = do(tx)
+	return err
+}
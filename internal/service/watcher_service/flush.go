@@ -0,0 +1,111 @@
+package watcher_service
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/errors"
+	"music-files/internal/model"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Changes are applied at the latest after this many debounce periods, even if events keep coming
+const maxDebounceFactor = 10
+
+// markDirty remembers a changed directory and postpones applying of changes. Must be called with the lock held
+func (s *Service) markDirty(absolutePath string) {
+	now := time.Now()
+	if len(s.state.dirty) == 0 {
+		s.state.firstDirty = now
+	}
+	s.state.dirty[absolutePath] = true
+
+	if s.state.flushTimer != nil {
+		if now.Sub(s.state.firstDirty) >= maxDebounceFactor*s.Config.Debounce {
+			return
+		}
+		s.state.flushTimer.Stop()
+	}
+	s.state.flushTimer = time.AfterFunc(s.Config.Debounce, s.flush)
+}
+
+// flush submits a non-recursive scan of the changed directories
+func (s *Service) flush() {
+	s.state.mu.Lock()
+	dirtyPaths := make([]string, 0, len(s.state.dirty))
+	for path := range s.state.dirty {
+		dirtyPaths = append(dirtyPaths, path)
+	}
+	s.state.dirty = make(map[string]bool)
+	s.state.flushTimer = nil
+	s.state.mu.Unlock()
+
+	if len(dirtyPaths) == 0 {
+		return
+	}
+	log.Debug().Strs("dirtyPaths", dirtyPaths).Msg("Applying filesystem changes")
+
+	dirIds := make([]int, 0)
+	err := s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		seen := make(map[int]bool)
+		for _, path := range dirtyPaths {
+			dir, found, err := s.nearestTrackedDir(tx, path)
+			if err != nil {
+				return err
+			}
+			if found && !seen[dir.DirId] {
+				seen[dir.DirId] = true
+				dirIds = append(dirIds, dir.DirId)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to resolve changed directories")
+		return
+	}
+	if len(dirIds) == 0 {
+		log.Debug().Msg("Changes are outside of tracked directories")
+		return
+	}
+
+	scanJob, err := s.ScanJobService.Submit(model.ScanJob{
+		TriggeredBy: model.ScanJobTriggeredByWatcher,
+		Recursive:   false,
+	}, dirIds)
+	if err != nil {
+		log.Error().Err(err).Ints("dirIds", dirIds).Msg("Failed to submit scan of changed directories")
+		return
+	}
+
+	log.Info().Int("scanJobId", scanJob.ScanJobId).Ints("dirIds", dirIds).Msg("Scan of changed directories submitted")
+}
+
+// nearestTrackedDir finds the directory itself or its closest ancestor known to the database
+func (s *Service) nearestTrackedDir(tx *sqlx.Tx, absolutePath string) (dir model.Directory, found bool, err error) {
+	for {
+		dir, err = s.DirService.FindByAbsolutePath(tx, absolutePath)
+		if err == nil {
+			return dir, true, nil
+		}
+		if _, ok := err.(errors.NotFound); !ok {
+			log.Error().Err(err).Str("absolutePath", absolutePath).Msg("Failed to find directory")
+			return model.Directory{}, false, err
+		}
+
+		parentPath := filepath.Dir(absolutePath)
+		if parentPath == absolutePath {
+			return model.Directory{}, false, nil
+		}
+		absolutePath = parentPath
+	}
+}
+
+func isWithin(path string, dirPath string) bool {
+	relativePath, err := filepath.Rel(dirPath, path)
+	if err != nil {
+		return false
+	}
+	return relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,115 @@
+package watcher_service
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+)
+
+func (s *Service) listen() {
+	for {
+		select {
+		case event, ok := <-s.state.notify.Events:
+			if !ok {
+				return
+			}
+			s.handleEvent(event)
+		case err, ok := <-s.state.notify.Errors:
+			if !ok {
+				return
+			}
+			log.Warn().Err(err).Msg("Filesystem watcher error")
+		}
+	}
+}
+
+func (s *Service) handleEvent(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+	log.Trace().Str("path", event.Name).Str("op", event.Op.String()).Msg("Filesystem event received")
+
+	// A created directory is walked without the lock, like a new root
+	var createdPaths []string
+	if event.Has(fsnotify.Create) {
+		if fileInfo, err := os.Stat(event.Name); err == nil && fileInfo.IsDir() {
+			s.state.mu.Lock()
+			filter, ok := s.filterOf(event.Name)
+			s.state.mu.Unlock()
+			if ok {
+				createdPaths, err = s.subscribe(filter, event.Name)
+				if err != nil {
+					log.Warn().Err(err).Str("path", event.Name).Msg("Failed to subscribe to created directory")
+				}
+			}
+		}
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	if _, ok := s.filterOf(event.Name); ok {
+		for _, path := range createdPaths {
+			s.state.watched[path] = true
+		}
+	} else {
+		// The root stopped being watched while the directory was walked
+		for _, path := range createdPaths {
+			_ = s.state.notify.Remove(path)
+		}
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		if s.state.watched[event.Name] {
+			s.removeRecursive(event.Name)
+		}
+	}
+
+	s.markDirty(filepath.Dir(event.Name))
+	if _, isRoot := s.state.roots[event.Name]; isRoot {
+		s.markDirty(event.Name)
+	}
+}
+
+// filterOf returns the filter of the watched root containing the path. Must be called with the lock held
+func (s *Service) filterOf(absolutePath string) (filter rootFilter, ok bool) {
+	for rootPath, mode := range s.state.roots {
+		if mode == watchModeNotify && isWithin(absolutePath, rootPath) {
+			return s.state.filters[rootPath], true
+		}
+	}
+	return rootFilter{}, false
+}
+
+// subscribe adds watches to the directory and all directories below it that a scan would enter.
+// On failure the added watches are removed again. Must be called without the lock held
+func (s *Service) subscribe(filter rootFilter, absolutePath string) (watchedPaths []string, err error) {
+	filter.walk(absolutePath, func(dirPath string, entries []os.DirEntry) {
+		if err != nil {
+			return
+		}
+		if err = s.state.notify.Add(dirPath); err != nil {
+			return
+		}
+		watchedPaths = append(watchedPaths, dirPath)
+	})
+	if err != nil {
+		for _, path := range watchedPaths {
+			_ = s.state.notify.Remove(path)
+		}
+		return nil, err
+	}
+	return watchedPaths, nil
+}
+
+// removeRecursive unsubscribes from the directory and all directories below it. Must be called with the lock held
+func (s *Service) removeRecursive(absolutePath string) {
+	for path := range s.state.watched {
+		if !isWithin(path, absolutePath) {
+			continue
+		}
+		// The kernel drops watches of removed directories by itself, so the error is expected
+		_ = s.state.notify.Remove(path)
+		delete(s.state.watched, path)
+	}
+}
@@ -0,0 +1,82 @@
+package watcher_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+func (s *Service) poll() {
+	ticker := time.NewTicker(s.Config.PollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.state.mu.Lock()
+		polledRoots := make([]rootFilter, 0)
+		for rootPath, mode := range s.state.roots {
+			if mode == watchModePoll {
+				polledRoots = append(polledRoots, s.state.filters[rootPath])
+			}
+		}
+		s.state.mu.Unlock()
+
+		for _, filter := range polledRoots {
+			snapshot := takeSnapshot(filter)
+			s.compareSnapshot(filter.rootPath, snapshot)
+		}
+	}
+}
+
+// compareSnapshot marks directories whose content changed since the previous poll as dirty
+func (s *Service) compareSnapshot(rootPath string, snapshot map[string]uint64) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	previous, ok := s.state.snapshots[rootPath]
+	if !ok {
+		return
+	}
+
+	for dirPath, signature := range snapshot {
+		if previousSignature, ok := previous[dirPath]; !ok || previousSignature != signature {
+			s.markDirty(dirPath)
+		}
+	}
+	for dirPath := range previous {
+		if _, ok := snapshot[dirPath]; !ok {
+			s.markDirty(filepath.Dir(dirPath))
+		}
+	}
+	if len(snapshot) == 0 && len(previous) != 0 {
+		s.markDirty(rootPath)
+	}
+
+	s.state.snapshots[rootPath] = snapshot
+	log.Trace().Str("rootPath", rootPath).Int("countOfDirs", len(snapshot)).Msg("Root polled")
+}
+
+// takeSnapshot computes a signature of names, sizes and modification times of entries of every directory under
+// the root that a scan would enter. Ignored entries are not part of signatures
+func takeSnapshot(filter rootFilter) (snapshot map[string]uint64) {
+	snapshot = make(map[string]uint64)
+
+	filter.walk(filter.rootPath, func(dirPath string, entries []os.DirEntry) {
+		hash := fnv.New64a()
+		for _, dirEntry := range entries {
+			hash.Write([]byte(dirEntry.Name()))
+			hash.Write([]byte{0})
+			if info, err := dirEntry.Info(); err == nil {
+				hash.Write([]byte(strconv.FormatInt(info.Size(), 10)))
+				hash.Write([]byte(strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+				hash.Write([]byte(info.Mode().String()))
+			}
+			hash.Write([]byte{0})
+		}
+		snapshot[dirPath] = hash.Sum64()
+	})
+
+	return snapshot
+}
@@ -0,0 +1,75 @@
+package watcher_service
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"music-files/internal/config"
+	"music-files/internal/service"
+	"music-files/internal/service/dir_service"
+	"music-files/internal/service/ignore_service"
+	"music-files/internal/service/scan_job_service"
+	"sync"
+	"time"
+)
+
+type Service struct {
+	DirService         dir_service.Service
+	IgnoreService      ignore_service.Service
+	ScanJobService     scan_job_service.Service
+	TransactionManager service.TransactionManager
+	Config             config.Watcher
+
+	state *state
+}
+
+func NewService(dirService dir_service.Service,
+	ignoreService ignore_service.Service,
+	scanJobService scan_job_service.Service,
+	transactionManager service.TransactionManager,
+	watcherConfig config.Watcher) (s *Service) {
+
+	s = &Service{
+		DirService:         dirService,
+		IgnoreService:      ignoreService,
+		ScanJobService:     scanJobService,
+		TransactionManager: transactionManager,
+		Config:             watcherConfig,
+		state: &state{
+			roots:     make(map[string]watchMode),
+			filters:   make(map[string]rootFilter),
+			watched:   make(map[string]bool),
+			snapshots: make(map[string]map[string]uint64),
+			dirty:     make(map[string]bool),
+		},
+	}
+
+	return s
+}
+
+type watchMode int
+
+const (
+	watchModeNotify watchMode = iota
+	watchModePoll
+)
+
+// state is shared by all copies of the Service
+type state struct {
+	// Serializes refreshes, which walk roots without holding mu
+	refreshMu sync.Mutex
+	mu        sync.Mutex
+
+	notify *fsnotify.Watcher
+	// Absolute paths of tracked roots and the way they are watched
+	roots map[string]watchMode
+	// Ignore rules and symlink policies of tracked roots
+	filters map[string]rootFilter
+	// Directories subscribed to filesystem events
+	watched map[string]bool
+	// Signatures of directories of polled roots, by root
+	snapshots map[string]map[string]uint64
+
+	// Directories with changes that are not applied yet
+	dirty      map[string]bool
+	firstDirty time.Time
+	flushTimer *time.Timer
+}
@@ -0,0 +1,203 @@
+package watcher_service
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"music-files/internal/utils"
+	"os"
+)
+
+// Start subscribes to changes of all tracked roots and starts applying them
+func (s *Service) Start() (err error) {
+	if !s.Config.Enabled {
+		log.Info().Msg("Filesystem watcher disabled")
+		return nil
+	}
+	log.Debug().Msg("Starting filesystem watcher")
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warn().Err(err).Msg("Filesystem events unavailable, all roots will be polled")
+	} else {
+		s.state.notify = notify
+		go s.listen()
+	}
+	if s.Config.PollInterval > 0 {
+		go s.poll()
+	} else {
+		log.Warn().Msg("Polling disabled, changes of polled roots will not be noticed")
+	}
+
+	// Subscribing walks whole trees, so it must not delay the start of the server
+	go func() {
+		if err := s.Refresh(); err != nil {
+			log.Error().Err(err).Msg("Failed to subscribe to tracked roots")
+		}
+	}()
+
+	log.Info().Msg("Filesystem watcher started")
+	return nil
+}
+
+// Refresh brings the set of watched roots in line with the watch list, and subscriptions of roots whose ignore
+// patterns or symlink policy changed in line with their new filters.
+// Roots are walked without holding the lock, so events of other roots keep being handled meanwhile
+func (s *Service) Refresh() (err error) {
+	if !s.Config.Enabled {
+		return nil
+	}
+	log.Debug().Msg("Refreshing watched roots")
+
+	s.state.refreshMu.Lock()
+	defer s.state.refreshMu.Unlock()
+
+	filters := make(map[string]rootFilter)
+	err = s.TransactionManager.WithTransaction(func(tx *sqlx.Tx) (err error) {
+		roots, err := s.DirService.GetRoots(tx)
+		if err != nil {
+			return err
+		}
+		for _, root := range roots {
+			patterns, err := s.IgnoreService.GetPatterns(tx, root.DirId)
+			if err != nil {
+				return err
+			}
+			filter, err := newRootFilter(root, patterns)
+			if err != nil {
+				log.Warn().Err(err).Str("rootPath", root.Name).Msg("Failed to parse ignore patterns of root, they are not applied")
+				filter, _ = newRootFilter(root, nil)
+			}
+			filters[root.Name] = filter
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get root directories")
+		return err
+	}
+
+	s.state.mu.Lock()
+	newFilters := make([]rootFilter, 0)
+	changedFilters := make([]rootFilter, 0)
+	for rootPath, filter := range filters {
+		if _, ok := s.state.roots[rootPath]; !ok {
+			newFilters = append(newFilters, filter)
+		} else if !filter.equals(s.state.filters[rootPath]) {
+			changedFilters = append(changedFilters, filter)
+		}
+		s.state.filters[rootPath] = filter
+	}
+	for rootPath := range s.state.roots {
+		if _, ok := filters[rootPath]; !ok {
+			s.unwatchRoot(rootPath)
+		}
+	}
+	s.state.mu.Unlock()
+
+	for _, filter := range newFilters {
+		s.watchRoot(filter)
+	}
+	for _, filter := range changedFilters {
+		s.rewatchRoot(filter)
+	}
+
+	s.state.mu.Lock()
+	countOfRoots := len(s.state.roots)
+	s.state.mu.Unlock()
+	log.Debug().Int("countOfRoots", countOfRoots).Msg("Watched roots refreshed")
+	return nil
+}
+
+// watchRoot chooses how the root is watched and subscribes to it. Must be called without the lock held
+func (s *Service) watchRoot(filter rootFilter) {
+	rootPath := filter.rootPath
+	mode := watchModeNotify
+	if s.Config.ForcePolling || s.state.notify == nil {
+		mode = watchModePoll
+	} else if isNetwork, err := utils.IsNetworkFilesystem(rootPath); err != nil {
+		log.Warn().Err(err).Str("rootPath", rootPath).Msg("Failed to detect filesystem type, root will be polled")
+		mode = watchModePoll
+	} else if isNetwork {
+		log.Info().Str("rootPath", rootPath).Msg("Root is on a network filesystem and will be polled")
+		mode = watchModePoll
+	}
+
+	var watchedPaths []string
+	if mode == watchModeNotify {
+		var err error
+		watchedPaths, err = s.subscribe(filter, rootPath)
+		if err != nil {
+			log.Warn().Err(err).Str("rootPath", rootPath).Msg("Failed to subscribe to filesystem events, root will be polled")
+			mode = watchModePoll
+		}
+	}
+	var snapshot map[string]uint64
+	if mode == watchModePoll {
+		snapshot = takeSnapshot(filter)
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	s.state.roots[rootPath] = mode
+	for _, path := range watchedPaths {
+		s.state.watched[path] = true
+	}
+	if mode == watchModePoll {
+		s.state.snapshots[rootPath] = snapshot
+	}
+	log.Info().Str("rootPath", rootPath).Bool("polling", mode == watchModePoll).Msg("Root is watched")
+}
+
+// rewatchRoot walks a watched root again after its filter changed: directories that are ignored now are
+// unsubscribed, newly allowed ones subscribed. Polled roots take the new filter on the next poll, which marks
+// the directories it lets in or out as dirty. Must be called without the lock held
+func (s *Service) rewatchRoot(filter rootFilter) {
+	rootPath := filter.rootPath
+	s.state.mu.Lock()
+	mode := s.state.roots[rootPath]
+	s.state.mu.Unlock()
+	if mode != watchModeNotify {
+		return
+	}
+
+	wantedPaths := make(map[string]bool)
+	filter.walk(rootPath, func(dirPath string, entries []os.DirEntry) {
+		wantedPaths[dirPath] = true
+	})
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.state.roots[rootPath] != watchModeNotify {
+		// The root stopped being watched while it was walked
+		return
+	}
+	for path := range s.state.watched {
+		if isWithin(path, rootPath) && !wantedPaths[path] {
+			_ = s.state.notify.Remove(path)
+			delete(s.state.watched, path)
+		}
+	}
+	for path := range wantedPaths {
+		if s.state.watched[path] {
+			continue
+		}
+		if err := s.state.notify.Add(path); err != nil {
+			log.Warn().Err(err).Str("path", path).Msg("Failed to subscribe to directory")
+			continue
+		}
+		s.state.watched[path] = true
+	}
+	log.Info().Str("rootPath", rootPath).Int("countOfWatchedDirs", len(wantedPaths)).Msg("Subscriptions of root updated to its filter")
+}
+
+// unwatchRoot stops watching the root. Must be called with the lock held
+func (s *Service) unwatchRoot(rootPath string) {
+	if s.state.roots[rootPath] == watchModeNotify {
+		s.removeRecursive(rootPath)
+	}
+	delete(s.state.roots, rootPath)
+	delete(s.state.filters, rootPath)
+	delete(s.state.snapshots, rootPath)
+	log.Info().Str("rootPath", rootPath).Msg("Root is no longer watched")
+}
@@ -0,0 +1,150 @@
+package watcher_service
+
+import (
+	"github.com/rs/zerolog/log"
+	"music-files/internal/model"
+	"music-files/internal/service/dir_service"
+	"music-files/internal/utils"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// rootFilter decides which directories under a root are watched, the same way scans traverse the root
+type rootFilter struct {
+	rootPath      string
+	resolvedRoot  string
+	symlinkPolicy string
+	// Patterns of the root as stored and parsed, .wakarimiignore files are read while walking
+	patterns []string
+	rules    utils.IgnoreRules
+}
+
+func newRootFilter(root model.Directory, patterns []string) (filter rootFilter, err error) {
+	rules, err := utils.ParseIgnoreRules("", patterns)
+	if err != nil {
+		return rootFilter{}, err
+	}
+	return rootFilter{
+		rootPath:      root.Name,
+		resolvedRoot:  dir_service.ResolveOrKeep(root.Name),
+		symlinkPolicy: root.SymlinkPolicyOrDefault(),
+		patterns:      patterns,
+		rules:         rules,
+	}, nil
+}
+
+// equals tells whether both filters let the same directories in
+func (f rootFilter) equals(other rootFilter) bool {
+	return f.rootPath == other.rootPath && f.resolvedRoot == other.resolvedRoot &&
+		f.symlinkPolicy == other.symlinkPolicy && slices.Equal(f.patterns, other.patterns)
+}
+
+// walk calls visit for the directory and every directory below it that a scan would enter.
+// Entries excluded by ignore rules and symlinks not followed under the symlink policy are left out of entries
+func (f rootFilter) walk(absolutePath string, visit func(dirPath string, entries []os.DirEntry)) {
+	if !isWithin(absolutePath, f.rootPath) {
+		return
+	}
+
+	var rules []utils.IgnoreRules
+	if len(f.rules.Patterns) > 0 {
+		rules = append(rules, f.rules)
+	}
+
+	// Rules of the ancestors decide whether the directory itself is entered
+	dirPath := f.rootPath
+	relativePath := ""
+	if relative, err := filepath.Rel(f.rootPath, absolutePath); err == nil && relative != "." {
+		for _, name := range strings.Split(filepath.ToSlash(relative), "/") {
+			rules = f.withIgnoreFile(rules, dirPath, relativePath)
+			dirPath = filepath.Join(dirPath, name)
+			relativePath = path.Join(relativePath, name)
+			if utils.IsIgnored(rules, relativePath, true) {
+				return
+			}
+		}
+		if fileInfo, err := os.Lstat(dirPath); err == nil && fileInfo.Mode()&os.ModeSymlink != 0 &&
+			!dir_service.IsSymlinkFollowed(f.symlinkPolicy, dirPath, f.resolvedRoot) {
+			return
+		}
+	}
+
+	f.walkDir(dirPath, relativePath, rules, nil, visit)
+}
+
+func (f rootFilter) walkDir(dirPath string, relativePath string, rules []utils.IgnoreRules, ancestors []os.FileInfo, visit func(dirPath string, entries []os.DirEntry)) {
+	fileInfo, err := os.Stat(dirPath)
+	if err != nil {
+		log.Debug().Err(err).Str("path", dirPath).Msg("Failed to stat directory while walking")
+		return
+	}
+	// A directory leading back to one of its ancestors is a loop
+	for _, ancestor := range ancestors {
+		if os.SameFile(fileInfo, ancestor) {
+			return
+		}
+	}
+	ancestors = append(ancestors, fileInfo)
+
+	allEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		log.Debug().Err(err).Str("path", dirPath).Msg("Failed to read directory while walking")
+		return
+	}
+	rules = f.withIgnoreFile(rules, dirPath, relativePath)
+
+	entries := make([]os.DirEntry, 0, len(allEntries))
+	subDirs := make([]string, 0)
+	for _, entry := range allEntries {
+		if entry.Name() != utils.IgnoreFilename && utils.IsIgnored(rules, path.Join(relativePath, entry.Name()), entry.IsDir()) {
+			continue
+		}
+		entryPath := filepath.Join(dirPath, entry.Name())
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			// As in scans, the policy only applies to symlinks to directories
+			linkInfo, err := os.Stat(entryPath)
+			isDir = err == nil && linkInfo.IsDir()
+			if isDir && !dir_service.IsSymlinkFollowed(f.symlinkPolicy, entryPath, f.resolvedRoot) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+		if isDir {
+			subDirs = append(subDirs, entry.Name())
+		}
+	}
+
+	visit(dirPath, entries)
+	for _, name := range subDirs {
+		f.walkDir(filepath.Join(dirPath, name), path.Join(relativePath, name), rules, ancestors, visit)
+	}
+}
+
+// withIgnoreFile adds the rules of the .wakarimiignore file of the directory, if there is one
+func (f rootFilter) withIgnoreFile(rules []utils.IgnoreRules, dirPath string, relativePath string) []utils.IgnoreRules {
+	file, err := os.Open(filepath.Join(dirPath, utils.IgnoreFilename))
+	if err != nil {
+		return rules
+	}
+	defer file.Close()
+
+	lines, err := utils.ReadIgnoreLines(file)
+	if err != nil {
+		log.Debug().Err(err).Str("path", dirPath).Msg("Failed to read ignore file while walking")
+		return rules
+	}
+	fileRules, err := utils.ParseIgnoreRules(relativePath, lines)
+	if err != nil {
+		log.Debug().Err(err).Str("path", dirPath).Msg("Failed to parse ignore file while walking")
+		return rules
+	}
+	if len(fileRules.Patterns) == 0 {
+		return rules
+	}
+	// Rules of siblings must not share the backing array
+	return append(rules[:len(rules):len(rules)], fileRules)
+}
@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// How many bytes after the ID3v2 tags are given to magic detectors
+const audioFormatHeadSize = 4096
+
+// AudioFormat is a kind of audio file the scanner recognizes
+type AudioFormat struct {
+	// Short name, e.g. flac, opus or m4b. Variants of a format detected in different ways share it
+	Name string
+	// Canonical MIME type, sent as the Content-Type of downloads
+	MimeType string
+	// Extensions in lower case with the dot
+	Extensions []string
+	// Magic tells by the first bytes of the stream, after any ID3v2 tags, whether the file is of the format.
+	// Nil for formats that are only known by extension
+	Magic func(head []byte) bool
+	// Set for signatures shared with containers of video or other data, e.g. Matroska or ASF,
+	// then the file must have one of the extensions too
+	RequiresExtension bool
+	Capabilities      AudioFormatCapabilities
+}
+
+// AudioFormatCapabilities tell what the format is and what the service can do with it without taglib
+type AudioFormatCapabilities struct {
+	// Audio of the format is always lossless
+	Lossless bool
+	// Properties and tags are read by the native metadata reader, other formats need taglib
+	NativeMetadata bool
+	// Pictures embedded in the tags are extracted as covers
+	EmbeddedPictures bool
+}
+
+var (
+	audioFormatsMutex sync.RWMutex
+	// In order of priority, more specific formats go before the ones they refine
+	audioFormats = []AudioFormat{
+		{
+			Name: "flac", MimeType: "audio/flac", Extensions: []string{".flac"},
+			Magic:        magicPrefix("fLaC"),
+			Capabilities: AudioFormatCapabilities{Lossless: true, NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			Name: "opus", MimeType: "audio/ogg; codecs=opus", Extensions: []string{".opus", ".ogg", ".oga"},
+			Magic:        magicOggCodec("OpusHead"),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true},
+		},
+		{
+			Name: "vorbis", MimeType: "audio/ogg; codecs=vorbis", Extensions: []string{".ogg", ".oga"},
+			Magic:        magicOggCodec("\x01vorbis"),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true},
+		},
+		{
+			Name: "ogg_flac", MimeType: "audio/ogg; codecs=flac", Extensions: []string{".oga", ".ogg"},
+			Magic:        magicOggCodec("\x7fFLAC"),
+			Capabilities: AudioFormatCapabilities{Lossless: true, NativeMetadata: true},
+		},
+		{
+			Name: "speex", MimeType: "audio/ogg; codecs=speex", Extensions: []string{".spx"},
+			Magic: magicOggCodec("Speex   "),
+		},
+		{
+			Name: "m4b", MimeType: "audio/mp4", Extensions: []string{".m4b"},
+			Magic:        magicMp4Brand("M4B "),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			Name: "m4a", MimeType: "audio/mp4", Extensions: []string{".m4a", ".m4p"},
+			Magic:        magicMp4Brand("M4A ", "M4P "),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			// Audiobooks are often written with the brand of music or a generic one, the extension tells them apart
+			Name: "m4b", MimeType: "audio/mp4", Extensions: []string{".m4b"},
+			Magic:             magicMp4Brand(),
+			RequiresExtension: true,
+			Capabilities:      AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			// Generic brands are used for video too, such files are taken by extension
+			Name: "m4a", MimeType: "audio/mp4", Extensions: []string{".m4a"},
+			Magic:             magicMp4Brand(),
+			RequiresExtension: true,
+			Capabilities:      AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			Name: "wav", MimeType: "audio/wav", Extensions: []string{".wav", ".wave"},
+			Magic: func(head []byte) bool {
+				return (bytes.HasPrefix(head, []byte("RIFF")) || bytes.HasPrefix(head, []byte("RF64"))) &&
+					len(head) >= 12 && string(head[8:12]) == "WAVE"
+			},
+			Capabilities: AudioFormatCapabilities{Lossless: true, NativeMetadata: true},
+		},
+		{
+			Name: "aiff", MimeType: "audio/aiff", Extensions: []string{".aiff", ".aif", ".aifc"},
+			Magic: func(head []byte) bool {
+				return bytes.HasPrefix(head, []byte("FORM")) && len(head) >= 12 &&
+					(string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC")
+			},
+			Capabilities: AudioFormatCapabilities{Lossless: true, NativeMetadata: true},
+		},
+		{
+			Name: "ape", MimeType: "audio/x-ape", Extensions: []string{".ape"},
+			Magic:        magicPrefix("MAC "),
+			Capabilities: AudioFormatCapabilities{Lossless: true},
+		},
+		{
+			Name: "wavpack", MimeType: "audio/x-wavpack", Extensions: []string{".wv"},
+			Magic:        magicPrefix("wvpk"),
+			Capabilities: AudioFormatCapabilities{Lossless: true},
+		},
+		{
+			Name: "tta", MimeType: "audio/x-tta", Extensions: []string{".tta"},
+			Magic:        magicPrefix("TTA1"),
+			Capabilities: AudioFormatCapabilities{Lossless: true},
+		},
+		{
+			Name: "dsf", MimeType: "audio/x-dsf", Extensions: []string{".dsf"},
+			Magic:        magicPrefix("DSD "),
+			Capabilities: AudioFormatCapabilities{Lossless: true},
+		},
+		{
+			Name: "dff", MimeType: "audio/x-dff", Extensions: []string{".dff"},
+			Magic: func(head []byte) bool {
+				return bytes.HasPrefix(head, []byte("FRM8")) && len(head) >= 16 && string(head[12:16]) == "DSD "
+			},
+			Capabilities: AudioFormatCapabilities{Lossless: true},
+		},
+		{
+			Name: "mka", MimeType: "audio/x-matroska", Extensions: []string{".mka"},
+			Magic:             magicEbmlDocType("matroska"),
+			RequiresExtension: true,
+		},
+		{
+			Name: "weba", MimeType: "audio/webm", Extensions: []string{".weba"},
+			Magic:             magicEbmlDocType("webm"),
+			RequiresExtension: true,
+		},
+		{
+			// ASF header object GUID, the same for video
+			Name: "wma", MimeType: "audio/x-ms-wma", Extensions: []string{".wma"},
+			Magic:             magicPrefix("\x30\x26\xb2\x75\x8e\x66\xcf\x11\xa6\xd9\x00\xaa\x00\x62\xce\x6c"),
+			RequiresExtension: true,
+		},
+		{
+			Name: "amr", MimeType: "audio/amr", Extensions: []string{".amr"},
+			Magic: magicPrefix("#!AMR"),
+		},
+		{
+			Name: "realaudio", MimeType: "audio/vnd.rn-realaudio", Extensions: []string{".ra"},
+			Magic: magicPrefix(".ra\xfd"),
+		},
+		{
+			// Two bytes of sync word are too short to be taken alone
+			Name: "ac3", MimeType: "audio/ac3", Extensions: []string{".ac3"},
+			Magic:             magicPrefix("\x0b\x77"),
+			RequiresExtension: true,
+		},
+		{
+			// ADTS frames: 12 bits of sync and layer 0
+			Name: "aac", MimeType: "audio/aac", Extensions: []string{".aac"},
+			Magic: func(head []byte) bool {
+				return len(head) >= 2 && head[0] == 0xff && head[1]&0xf6 == 0xf0
+			},
+		},
+		{
+			Name: "mp3", MimeType: "audio/mpeg", Extensions: []string{".mp3"},
+			Magic:        magicMpegLayer(3),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+		{
+			Name: "mp2", MimeType: "audio/mpeg", Extensions: []string{".mp2", ".mpa"},
+			Magic:        magicMpegLayer(2),
+			Capabilities: AudioFormatCapabilities{NativeMetadata: true, EmbeddedPictures: true},
+		},
+	}
+)
+
+// RegisterAudioFormat adds a format to the registry. It is tried before the ones registered earlier,
+// so it can refine a built-in format
+func RegisterAudioFormat(format AudioFormat) {
+	audioFormatsMutex.Lock()
+	defer audioFormatsMutex.Unlock()
+	audioFormats = append([]AudioFormat{format}, audioFormats...)
+}
+
+// DetectAudioFormat tells the format by the magic bytes of the stream after any ID3v2 tags, the extension
+// decides between formats with the same signature. Formats without a magic detector are found by extension.
+// ok is false for files that aren't audio, e.g. MIDI. The file is positioned back to its start
+func DetectAudioFormat(file io.ReadSeeker, filename string) (format AudioFormat, ok bool, err error) {
+	var offset int64
+	header := make([]byte, 10)
+	for {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return AudioFormat{}, false, err
+		}
+		_, err = io.ReadFull(file, header)
+		if err != nil || !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+		offset += 10 + int64(syncsafe(header[6:10]))
+		if header[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return AudioFormat{}, false, err
+	}
+	head := make([]byte, audioFormatHeadSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return AudioFormat{}, false, err
+	}
+	head = head[:n]
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return AudioFormat{}, false, err
+	}
+
+	format, ok = matchAudioFormat(head, strings.ToLower(filepath.Ext(filename)))
+	return format, ok, nil
+}
+
+func matchAudioFormat(head []byte, extension string) (format AudioFormat, ok bool) {
+	audioFormatsMutex.RLock()
+	defer audioFormatsMutex.RUnlock()
+
+	var magicMatches []AudioFormat
+	for _, format := range audioFormats {
+		if format.Magic != nil && format.Magic(head) {
+			magicMatches = append(magicMatches, format)
+		}
+	}
+	for _, format := range magicMatches {
+		if slices.Contains(format.Extensions, extension) {
+			return format, true
+		}
+	}
+	for _, format := range magicMatches {
+		if !format.RequiresExtension {
+			return format, true
+		}
+	}
+	for _, format := range audioFormats {
+		if format.Magic == nil && slices.Contains(format.Extensions, extension) {
+			return format, true
+		}
+	}
+	return AudioFormat{}, false
+}
+
+// DetectAudioFileFormat opens the file and detects its format, ok is false for directories and files that aren't audio
+func DetectAudioFileFormat(absolutePath string) (format AudioFormat, ok bool, err error) {
+	fileInfo, err := os.Stat(absolutePath)
+	if err != nil {
+		return AudioFormat{}, false, err
+	}
+	if fileInfo.IsDir() {
+		return AudioFormat{}, false, nil
+	}
+
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return AudioFormat{}, false, err
+	}
+	defer file.Close()
+
+	return DetectAudioFormat(file, absolutePath)
+}
+
+// AudioContentType returns the MIME type of the audio file, application/octet-stream if it is unknown
+func AudioContentType(absolutePath string) (contentType string) {
+	format, ok, err := DetectAudioFileFormat(absolutePath)
+	if err != nil || !ok {
+		return "application/octet-stream"
+	}
+	return format.MimeType
+}
+
+func magicPrefix(signature string) func(head []byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte(signature))
+	}
+}
+
+// magicOggCodec matches the identification header, the first packet of the first Ogg page
+func magicOggCodec(signature string) func(head []byte) bool {
+	return func(head []byte) bool {
+		if !bytes.HasPrefix(head, []byte("OggS")) || len(head) < 27 {
+			return false
+		}
+		packetStart := 27 + int(head[26])
+		return len(head) > packetStart && bytes.HasPrefix(head[packetStart:], []byte(signature))
+	}
+}
+
+// magicMp4Brand matches the file type box by its major or compatible brands, any brand if none are given.
+// Brands of pictures, such as AVIF and HEIF, never match
+func magicMp4Brand(brands ...string) func(head []byte) bool {
+	return func(head []byte) bool {
+		if len(head) < 16 || string(head[4:8]) != "ftyp" {
+			return false
+		}
+		boxSize := min(int(head[0])<<24|int(head[1])<<16|int(head[2])<<8|int(head[3]), len(head))
+		if boxSize < 16 {
+			return false
+		}
+		// Major brand, minor version, then compatible brands
+		fileBrands := []string{string(head[8:12])}
+		for position := 16; position+4 <= boxSize; position += 4 {
+			fileBrands = append(fileBrands, string(head[position:position+4]))
+		}
+		for _, brand := range fileBrands {
+			if brand == "avif" || brand == "avis" || brand == "heic" || brand == "mif1" {
+				return false
+			}
+		}
+		if len(brands) == 0 {
+			return true
+		}
+		for _, brand := range fileBrands {
+			if slices.Contains(brands, brand) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// magicEbmlDocType matches the EBML header by the document type, whose element follows within the header
+func magicEbmlDocType(docType string) func(head []byte) bool {
+	return func(head []byte) bool {
+		if !bytes.HasPrefix(head, []byte("\x1a\x45\xdf\xa3")) {
+			return false
+		}
+		// DocType element id, then a one byte size
+		headerEnd := min(len(head), 64)
+		index := bytes.Index(head[:headerEnd], []byte("\x42\x82"))
+		if index < 0 || index+3 > headerEnd {
+			return false
+		}
+		size := int(head[index+2] &^ 0x80)
+		start := index + 3
+		return start+size <= headerEnd && string(head[start:start+size]) == docType
+	}
+}
+
+// magicMpegLayer matches a frame of the MPEG audio layer, confirmed by the next one when it is within the head.
+// Zero bytes before the first frame are skipped
+func magicMpegLayer(layer int) func(head []byte) bool {
+	return func(head []byte) bool {
+		head = bytes.TrimLeft(head, "\x00")
+		frame, ok := parseMpegFrame(head)
+		if !ok || frame.layer != layer {
+			return false
+		}
+		if next := frame.size; next+4 <= len(head) {
+			nextFrame, ok := parseMpegFrame(head[next:])
+			return ok && nextFrame.layer == layer && nextFrame.sampleRateHz == frame.sampleRateHz
+		}
+		return true
+	}
+}
@@ -0,0 +1,1028 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrUnknownAudioFormat is returned for files whose audio stream isn't recognized
+var ErrUnknownAudioFormat = errors.New("audio format is not recognized")
+
+const (
+	// How far from the start of the audio data the first MPEG frame is looked for
+	mpegSyncSearchSize = 64 * 1024
+	// Ogg pages are shorter, so the last one starts within this many bytes from the end
+	oggTailSize = 64 * 1024
+)
+
+// How the bitrate of a stream behaves
+const (
+	BitrateModeConstant = "CBR"
+	// Includes average bitrate encoding, which varies the bitrate towards a target
+	BitrateModeVariable = "VBR"
+)
+
+// AudioProperties are technical details of the audio stream of a file
+type AudioProperties struct {
+	Duration     time.Duration
+	BitrateKbps  int
+	SampleRateHz int
+	ChannelsN    int
+	// Codec of the stream: mp1, mp2, mp3, aac, alac, flac, vorbis, opus, ac3, eac3, pcm, pcm_float, alaw, mulaw,
+	// adpcm_ms or adpcm_ima. Empty if it isn't known
+	Codec string
+	// Format of the file around the stream: mpeg, flac, ogg, mp4, wav or aiff
+	Container string
+	// Bits per sample of lossless streams, zero for lossy ones where the notion doesn't apply
+	BitsPerSample int
+	Lossless      bool
+	// BitrateModeConstant, BitrateModeVariable or empty if it isn't known
+	BitrateMode string
+	// Encoder named by the stream, e.g. LAME3.100 from the LAME tag or the vendor string of Vorbis comments
+	Encoder string
+	// Samples per channel without encoder delay and padding where the headers tell them. Zero if it isn't known
+	SampleCount int64
+}
+
+// ReadAudioProperties parses headers of MP3, FLAC, Ogg Vorbis, Opus and FLAC, MP4, WAV and AIFF files.
+// The file is positioned back to its start
+func ReadAudioProperties(file io.ReadSeeker) (properties AudioProperties, err error) {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return AudioProperties{}, err
+	}
+
+	var offset int64
+	header := make([]byte, 12)
+	for {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return AudioProperties{}, err
+		}
+		_, err = io.ReadFull(file, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// Nothing but tags, no format matches the cleared header
+			header = make([]byte, 12)
+			break
+		}
+		if err != nil {
+			return AudioProperties{}, err
+		}
+		if !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+		offset += 10 + int64(syncsafe(header[6:10]))
+		if header[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		properties = flacProperties(file, offset+4, size)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		properties = oggProperties(file, offset, size)
+	case bytes.Equal(header[4:8], []byte("ftyp")):
+		properties = mp4Properties(file, size)
+	case bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		properties = wavProperties(file, size)
+	case bytes.HasPrefix(header, []byte("FORM")) && (bytes.Equal(header[8:12], []byte("AIFF")) || bytes.Equal(header[8:12], []byte("AIFC"))):
+		properties = aiffProperties(file, size)
+	default:
+		properties = mpegProperties(file, offset, size)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return AudioProperties{}, err
+	}
+	if properties.SampleRateHz <= 0 {
+		return AudioProperties{}, ErrUnknownAudioFormat
+	}
+	if properties.BitrateKbps == 0 && properties.Duration > 0 {
+		properties.BitrateKbps = averageBitrateKbps(size-offset, properties.Duration)
+	}
+	return properties, nil
+}
+
+// samplesDuration converts a number of samples per channel into time
+func samplesDuration(samples uint64, sampleRateHz int) time.Duration {
+	if sampleRateHz <= 0 {
+		return 0
+	}
+	return time.Duration(float64(samples) * float64(time.Second) / float64(sampleRateHz))
+}
+
+func averageBitrateKbps(audioBytes int64, duration time.Duration) int {
+	if duration <= 0 || audioBytes <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(audioBytes) * 8 / duration.Seconds() / 1000))
+}
+
+func flacProperties(file io.ReadSeeker, offset int64, size int64) (properties AudioProperties) {
+	blockHeader := make([]byte, 4)
+	for {
+		_, err := file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return AudioProperties{}
+		}
+		_, err = io.ReadFull(file, blockHeader)
+		if err != nil {
+			return AudioProperties{}
+		}
+		blockSize := int64(blockHeader[1])<<16 | int64(blockHeader[2])<<8 | int64(blockHeader[3])
+		offset += 4 + blockSize
+
+		switch {
+		case blockHeader[0]&0x7f == 0 && blockSize >= 34:
+			streamInfo := make([]byte, 34)
+			_, err = io.ReadFull(file, streamInfo)
+			if err != nil {
+				return AudioProperties{}
+			}
+			encoder := properties.Encoder
+			properties = flacStreamInfo(streamInfo)
+			properties.Encoder = encoder
+		case blockHeader[0]&0x7f == 4:
+			// Only the vendor string at the start of the comments is needed
+			comments := make([]byte, min(blockSize, 4+maxVorbisVendorSize))
+			_, err = io.ReadFull(file, comments)
+			if err != nil {
+				return AudioProperties{}
+			}
+			properties.Encoder = vorbisVendor(comments)
+		}
+		if blockHeader[0]&0x80 != 0 {
+			break
+		}
+	}
+	properties.Container = "flac"
+	properties.BitrateKbps = averageBitrateKbps(size-offset, properties.Duration)
+	return properties
+}
+
+// flacStreamInfo reads the STREAMINFO block: block and frame sizes, then 20 bits of sample rate,
+// 3 of channels, 5 of bits per sample and 36 of total samples
+func flacStreamInfo(streamInfo []byte) (properties AudioProperties) {
+	if len(streamInfo) < 18 {
+		return AudioProperties{}
+	}
+	packed := binary.BigEndian.Uint64(streamInfo[10:18])
+	properties.Codec = "flac"
+	properties.Lossless = true
+	properties.BitrateMode = BitrateModeVariable
+	properties.SampleRateHz = int(packed >> 44)
+	properties.ChannelsN = int(packed>>41&0x7) + 1
+	properties.BitsPerSample = int(packed>>36&0x1f) + 1
+	properties.SampleCount = int64(packed & 0xfffffffff)
+	properties.Duration = samplesDuration(packed&0xfffffffff, properties.SampleRateHz)
+	return properties
+}
+
+// Longest vendor string of Vorbis comments that is taken for the name of the encoder
+const maxVorbisVendorSize = 1024
+
+// vorbisVendor reads the vendor string that starts Vorbis comments, it names the library that wrote the stream
+func vorbisVendor(comments []byte) (vendor string) {
+	if len(comments) < 4 {
+		return ""
+	}
+	size := binary.LittleEndian.Uint32(comments[:4])
+	if uint64(size) > uint64(len(comments)-4) {
+		return ""
+	}
+	return strings.TrimSpace(string(comments[4 : 4+size]))
+}
+
+// oggProperties reads the identification header of the first logical stream and the vendor string from
+// its comment header, the duration comes from the granule position of its last page
+func oggProperties(file io.ReadSeeker, offset int64, size int64) (properties AudioProperties) {
+	_, err := file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return AudioProperties{}
+	}
+	// The identification header is the only packet of the first page
+	serial, packet, ok := readOggPage(file)
+	if !ok {
+		return AudioProperties{}
+	}
+
+	// Samples of the last granule position that aren't audio
+	var preSkip uint64
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 28:
+		properties.Codec = "vorbis"
+		properties.ChannelsN = int(packet[11])
+		properties.SampleRateHz = int(binary.LittleEndian.Uint32(packet[12:16]))
+		maximumBitrate := int32(binary.LittleEndian.Uint32(packet[16:20]))
+		nominalBitrate := int32(binary.LittleEndian.Uint32(packet[20:24]))
+		minimumBitrate := int32(binary.LittleEndian.Uint32(packet[24:28]))
+		if nominalBitrate > 0 {
+			properties.BitrateKbps = int(nominalBitrate / 1000)
+		}
+		properties.BitrateMode = BitrateModeVariable
+		if nominalBitrate > 0 && maximumBitrate == nominalBitrate && minimumBitrate == nominalBitrate {
+			properties.BitrateMode = BitrateModeConstant
+		}
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 19:
+		// Opus is always decoded at 48 kHz whatever the rate of the source was
+		properties.Codec = "opus"
+		properties.ChannelsN = int(packet[9])
+		properties.SampleRateHz = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:12]))
+	case bytes.HasPrefix(packet, []byte("\x7fFLAC")) && len(packet) >= 13+8+34:
+		// Mapping header, then the native signature and the STREAMINFO block with its header
+		properties = flacStreamInfo(packet[13+8:])
+	default:
+		return AudioProperties{}
+	}
+	properties.Container = "ogg"
+
+	// The comment header starts the second page
+	commentsSerial, comments, ok := readOggPage(file)
+	if ok && bytes.Equal(commentsSerial, serial) {
+		switch {
+		case bytes.HasPrefix(comments, []byte("\x03vorbis")):
+			properties.Encoder = vorbisVendor(comments[7:])
+		case bytes.HasPrefix(comments, []byte("OpusTags")):
+			properties.Encoder = vorbisVendor(comments[8:])
+		case properties.Codec == "flac" && len(comments) >= 4:
+			// Metadata block with its header
+			properties.Encoder = vorbisVendor(comments[4:])
+		}
+	}
+
+	granule, ok := lastOggGranule(file, size, serial)
+	if ok && granule > preSkip {
+		properties.SampleCount = int64(granule - preSkip)
+		properties.Duration = samplesDuration(granule-preSkip, properties.SampleRateHz)
+	}
+	return properties
+}
+
+// readOggPage reads the page at the current position of the file, serial tells the logical stream it belongs to
+func readOggPage(file io.Reader) (serial []byte, data []byte, ok bool) {
+	header := make([]byte, 27)
+	_, err := io.ReadFull(file, header)
+	if err != nil || !bytes.HasPrefix(header, []byte("OggS")) {
+		return nil, nil, false
+	}
+	segmentTable := make([]byte, header[26])
+	_, err = io.ReadFull(file, segmentTable)
+	if err != nil || len(segmentTable) == 0 {
+		return nil, nil, false
+	}
+	pageSize := 0
+	for _, segmentSize := range segmentTable {
+		pageSize += int(segmentSize)
+	}
+	data = make([]byte, pageSize)
+	_, err = io.ReadFull(file, data)
+	if err != nil {
+		return nil, nil, false
+	}
+	return header[14:18], data, true
+}
+
+// lastOggGranule finds the granule position of the last page of the stream near the end of the file
+func lastOggGranule(file io.ReadSeeker, size int64, serial []byte) (granule uint64, ok bool) {
+	tailSize := int64(oggTailSize)
+	if tailSize > size {
+		tailSize = size
+	}
+	_, err := file.Seek(size-tailSize, io.SeekStart)
+	if err != nil {
+		return 0, false
+	}
+	tail := make([]byte, tailSize)
+	_, err = io.ReadFull(file, tail)
+	if err != nil {
+		return 0, false
+	}
+
+	for end := len(tail); ; {
+		start := bytes.LastIndex(tail[:end], []byte("OggS"))
+		if start < 0 {
+			return 0, false
+		}
+		page := tail[start:]
+		if len(page) >= 27 && bytes.Equal(page[14:18], serial) {
+			granule = binary.LittleEndian.Uint64(page[6:14])
+			// Pages without a finished packet have no position
+			if granule != math.MaxUint64 {
+				return granule, true
+			}
+		}
+		end = start
+	}
+}
+
+// mp4Properties takes the duration from the media header of the first sound track and the format
+// from its sample description
+func mp4Properties(file io.ReadSeeker, size int64) (properties AudioProperties) {
+	start, moovSize, found := findMp4Atom(file, 0, size, "moov")
+	if !found || moovSize > maxEmbeddedPictureSize {
+		return AudioProperties{}
+	}
+	_, err := file.Seek(start, io.SeekStart)
+	if err != nil {
+		return AudioProperties{}
+	}
+	moov := make([]byte, moovSize)
+	_, err = io.ReadFull(file, moov)
+	if err != nil {
+		return AudioProperties{}
+	}
+
+	for _, trak := range mp4Children(moov) {
+		if trak.name != "trak" {
+			continue
+		}
+		mdia, ok := mp4Child(trak.content, "mdia")
+		if !ok {
+			continue
+		}
+		// Handler: version and flags, pre-defined, then the handler type
+		hdlr, ok := mp4Child(mdia, "hdlr")
+		if !ok || len(hdlr) < 12 || string(hdlr[8:12]) != "soun" {
+			continue
+		}
+
+		var timescale uint32
+		var duration uint64
+		mdhd, ok := mp4Child(mdia, "mdhd")
+		if ok && len(mdhd) >= 24 {
+			if mdhd[0] == 1 {
+				// Version 1 has 64-bit times
+				if len(mdhd) < 32 {
+					continue
+				}
+				timescale = binary.BigEndian.Uint32(mdhd[20:24])
+				duration = binary.BigEndian.Uint64(mdhd[24:32])
+			} else {
+				timescale = binary.BigEndian.Uint32(mdhd[12:16])
+				duration = uint64(binary.BigEndian.Uint32(mdhd[16:20]))
+			}
+			properties.Duration = samplesDuration(duration, int(timescale))
+			properties.SampleRateHz = int(timescale)
+		}
+
+		entry, ok := mp4SampleEntry(mdia)
+		if ok && len(entry.content) >= 28 {
+			// Reserved, data reference, version, revision, vendor, then channels, sample size,
+			// compression id, packet size and the rate as 16.16 fixed point
+			properties.ChannelsN = int(binary.BigEndian.Uint16(entry.content[16:18]))
+			if sampleRateHz := int(binary.BigEndian.Uint32(entry.content[24:28]) >> 16); sampleRateHz > 0 {
+				properties.SampleRateHz = sampleRateHz
+			}
+			mp4AudioFormat(&properties, entry)
+		}
+		if timescale > 0 {
+			properties.SampleCount = int64(math.Round(float64(duration) * float64(properties.SampleRateHz) / float64(timescale)))
+		}
+		properties.Container = "mp4"
+		properties.BitrateKbps = averageBitrateKbps(size, properties.Duration)
+		return properties
+	}
+	return AudioProperties{}
+}
+
+// mp4AudioFormat tells the codec by the name of the sample entry, details come from the atoms inside it
+func mp4AudioFormat(properties *AudioProperties, entry mp4Atom) {
+	// QuickTime sound descriptions of versions 1 and 2 are longer than the 28 bytes of version 0
+	childrenStart := 28
+	switch binary.BigEndian.Uint16(entry.content[8:10]) {
+	case 1:
+		childrenStart += 16
+	case 2:
+		childrenStart += 36
+	}
+	var children []byte
+	if len(entry.content) > childrenStart {
+		children = entry.content[childrenStart:]
+	}
+	sampleSize := int(binary.BigEndian.Uint16(entry.content[18:20]))
+
+	switch entry.name {
+	case "mp4a":
+		properties.Codec = "aac"
+		esds, ok := mp4Child(children, "esds")
+		if !ok {
+			return
+		}
+		objectType, maximumBitrate, averageBitrate, ok := mp4DecoderConfig(esds)
+		if !ok {
+			return
+		}
+		if objectType == 0x69 || objectType == 0x6b {
+			properties.Codec = "mp3"
+		}
+		switch {
+		case averageBitrate > 0 && maximumBitrate == averageBitrate:
+			properties.BitrateMode = BitrateModeConstant
+		case averageBitrate > 0 && maximumBitrate > averageBitrate:
+			properties.BitrateMode = BitrateModeVariable
+		}
+	case "alac":
+		properties.Codec = "alac"
+		properties.Lossless = true
+		properties.BitrateMode = BitrateModeVariable
+		// Version and flags, frame length, compatible version, then the bit depth
+		cookie, ok := mp4Child(children, "alac")
+		if ok && len(cookie) >= 10 {
+			properties.BitsPerSample = int(cookie[9])
+		}
+	case "fLaC":
+		// Version and flags, then the STREAMINFO block with its header
+		dfLa, ok := mp4Child(children, "dfLa")
+		if ok && len(dfLa) >= 8+34 {
+			streamInfo := flacStreamInfo(dfLa[8:])
+			properties.BitsPerSample = streamInfo.BitsPerSample
+		}
+		properties.Codec = "flac"
+		properties.Lossless = true
+		properties.BitrateMode = BitrateModeVariable
+	case "Opus":
+		properties.Codec = "opus"
+	case "ac-3":
+		properties.Codec = "ac3"
+	case "ec-3":
+		properties.Codec = "eac3"
+	case "lpcm", "twos", "sowt", "raw ":
+		properties.Codec = "pcm"
+		properties.BitsPerSample = sampleSize
+	case "in24":
+		properties.Codec = "pcm"
+		properties.BitsPerSample = 24
+	case "in32":
+		properties.Codec = "pcm"
+		properties.BitsPerSample = 32
+	case "fl32":
+		properties.Codec = "pcm_float"
+		properties.BitsPerSample = 32
+	case "fl64":
+		properties.Codec = "pcm_float"
+		properties.BitsPerSample = 64
+	}
+	if properties.Codec == "pcm" || properties.Codec == "pcm_float" {
+		properties.Lossless = true
+		properties.BitrateMode = BitrateModeConstant
+	}
+}
+
+// mp4DecoderConfig finds the decoder config descriptor in the elementary stream descriptor: object type,
+// stream type, buffer size, then the maximum and the average bitrate
+func mp4DecoderConfig(esds []byte) (objectType byte, maximumBitrate uint32, averageBitrate uint32, ok bool) {
+	// Version and flags go first
+	if len(esds) < 4 {
+		return 0, 0, 0, false
+	}
+	data := esds[4:]
+	readDescriptor := func() (tag byte, content []byte, ok bool) {
+		if len(data) < 2 {
+			return 0, nil, false
+		}
+		tag = data[0]
+		// Size is stored 7 bits per byte, the high bit marks that another byte follows
+		size, position := 0, 1
+		for {
+			if position >= len(data) || position > 4 {
+				return 0, nil, false
+			}
+			sizeByte := data[position]
+			position++
+			size = size<<7 | int(sizeByte&0x7f)
+			if sizeByte&0x80 == 0 {
+				break
+			}
+		}
+		if position+size > len(data) {
+			return 0, nil, false
+		}
+		content = data[position : position+size]
+		data = data[position+size:]
+		return tag, content, true
+	}
+
+	tag, content, ok := readDescriptor()
+	if !ok || tag != 0x03 || len(content) < 3 {
+		return 0, 0, 0, false
+	}
+	// Elementary stream id, then flags telling which optional fields follow
+	flags := content[2]
+	data = content[3:]
+	if flags&0x80 != 0 && len(data) >= 2 {
+		data = data[2:]
+	}
+	if flags&0x40 != 0 && len(data) >= 1 && len(data) >= 1+int(data[0]) {
+		data = data[1+int(data[0]):]
+	}
+	if flags&0x20 != 0 && len(data) >= 2 {
+		data = data[2:]
+	}
+
+	tag, content, ok = readDescriptor()
+	if !ok || tag != 0x04 || len(content) < 13 {
+		return 0, 0, 0, false
+	}
+	return content[0], binary.BigEndian.Uint32(content[5:9]), binary.BigEndian.Uint32(content[9:13]), true
+}
+
+// mp4SampleEntry returns the first sample description of a track media, its name is the codec
+func mp4SampleEntry(mdia []byte) (entry mp4Atom, ok bool) {
+	stsd := mdia
+	for _, name := range []string{"minf", "stbl", "stsd"} {
+		stsd, ok = mp4Child(stsd, name)
+		if !ok {
+			return mp4Atom{}, false
+		}
+	}
+	// Version, flags and the count of entries go first
+	if len(stsd) < 8 {
+		return mp4Atom{}, false
+	}
+	entries := mp4Children(stsd[8:])
+	if len(entries) == 0 {
+		return mp4Atom{}, false
+	}
+	return entries[0], true
+}
+
+// mp4Child returns the content of the first child atom with the name
+func mp4Child(data []byte, name string) (content []byte, found bool) {
+	for _, atom := range mp4Children(data) {
+		if atom.name == name {
+			return atom.content, true
+		}
+	}
+	return nil, false
+}
+
+// riffChunk is a chunk of a RIFF or IFF file. Its content isn't read, only located
+type riffChunk struct {
+	id     string
+	offset int64
+	size   int64
+}
+
+// riffChunks lists chunks that follow the 12 byte header, sizes are little-endian in RIFF and big-endian in IFF
+func riffChunks(file io.ReadSeeker, size int64, byteOrder binary.ByteOrder) (chunks []riffChunk) {
+	header := make([]byte, 8)
+	for offset := int64(12); offset+8 <= size; {
+		_, err := file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return chunks
+		}
+		_, err = io.ReadFull(file, header)
+		if err != nil {
+			return chunks
+		}
+		chunk := riffChunk{id: string(header[:4]), offset: offset + 8, size: int64(byteOrder.Uint32(header[4:8]))}
+		if chunk.offset+chunk.size > size {
+			// Streams written without knowing their length leave it too large
+			chunk.size = size - chunk.offset
+		}
+		chunks = append(chunks, chunk)
+		// Chunks are aligned to two bytes
+		offset = chunk.offset + chunk.size + chunk.size%2
+	}
+	return chunks
+}
+
+func readRiffChunk(file io.ReadSeeker, chunk riffChunk, limit int64) (content []byte, ok bool) {
+	if chunk.size > limit {
+		return nil, false
+	}
+	_, err := file.Seek(chunk.offset, io.SeekStart)
+	if err != nil {
+		return nil, false
+	}
+	content = make([]byte, chunk.size)
+	_, err = io.ReadFull(file, content)
+	return content, err == nil
+}
+
+// Codecs of WAVE format tags
+var wavFormatCodecs = map[uint16]string{
+	0x0001: "pcm",
+	0x0002: "adpcm_ms",
+	0x0003: "pcm_float",
+	0x0006: "alaw",
+	0x0007: "mulaw",
+	0x0011: "adpcm_ima",
+	0x0050: "mp2",
+	0x0055: "mp3",
+	0x2000: "ac3",
+}
+
+// Format tag telling that the real one is the start of the sub-format GUID of the extensible format chunk
+const wavFormatExtensible = 0xfffe
+
+// wavProperties reads the format chunk, the length comes from the size of the data chunk or,
+// for compressed streams, from the fact chunk
+func wavProperties(file io.ReadSeeker, size int64) (properties AudioProperties) {
+	var byteRate uint32
+	var blockAlign uint16
+	var dataSize int64
+	var factSamples uint32
+	for _, chunk := range riffChunks(file, size, binary.LittleEndian) {
+		switch chunk.id {
+		case "fmt ":
+			// Format tag, channels, sample rate, byte rate, block align, bits per sample, then the size of the
+			// extension, valid bits per sample, channel mask and the sub-format GUID
+			format, ok := readRiffChunk(file, chunk, 1024)
+			if !ok || len(format) < 16 {
+				return AudioProperties{}
+			}
+			formatTag := binary.LittleEndian.Uint16(format[0:2])
+			properties.ChannelsN = int(binary.LittleEndian.Uint16(format[2:4]))
+			properties.SampleRateHz = int(binary.LittleEndian.Uint32(format[4:8]))
+			byteRate = binary.LittleEndian.Uint32(format[8:12])
+			blockAlign = binary.LittleEndian.Uint16(format[12:14])
+			properties.BitsPerSample = int(binary.LittleEndian.Uint16(format[14:16]))
+			if formatTag == wavFormatExtensible && len(format) >= 26 {
+				formatTag = binary.LittleEndian.Uint16(format[24:26])
+				if validBits := int(binary.LittleEndian.Uint16(format[18:20])); validBits > 0 {
+					properties.BitsPerSample = validBits
+				}
+			}
+			properties.Codec = wavFormatCodecs[formatTag]
+		case "fact":
+			fact, ok := readRiffChunk(file, chunk, 1024)
+			if ok && len(fact) >= 4 {
+				factSamples = binary.LittleEndian.Uint32(fact[0:4])
+			}
+		case "data":
+			dataSize = chunk.size
+		}
+	}
+	properties.Container = "wav"
+	switch properties.Codec {
+	case "pcm", "pcm_float":
+		properties.Lossless = true
+		properties.BitrateMode = BitrateModeConstant
+		if blockAlign > 0 {
+			properties.SampleCount = dataSize / int64(blockAlign)
+		}
+	default:
+		// Bits per sample of compressed streams are of the decoded audio at most
+		properties.BitsPerSample = 0
+		properties.SampleCount = int64(factSamples)
+	}
+	if byteRate > 0 {
+		properties.BitrateKbps = int(math.Round(float64(byteRate) * 8 / 1000))
+		properties.Duration = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	}
+	return properties
+}
+
+// Codecs of AIFF-C compression types
+var aifcCompressionCodecs = map[string]string{
+	"NONE": "pcm",
+	"twos": "pcm",
+	"sowt": "pcm",
+	"raw ": "pcm",
+	"in24": "pcm",
+	"in32": "pcm",
+	"fl32": "pcm_float",
+	"FL32": "pcm_float",
+	"fl64": "pcm_float",
+	"FL64": "pcm_float",
+	"alaw": "alaw",
+	"ALAW": "alaw",
+	"ulaw": "mulaw",
+	"ULAW": "mulaw",
+}
+
+// aiffProperties reads the common chunk: channels, sample frames, sample size and the rate
+// as an 80-bit extended float, followed by the compression type in AIFF-C
+func aiffProperties(file io.ReadSeeker, size int64) (properties AudioProperties) {
+	for _, chunk := range riffChunks(file, size, binary.BigEndian) {
+		if chunk.id != "COMM" {
+			continue
+		}
+		common, ok := readRiffChunk(file, chunk, 1024)
+		if !ok || len(common) < 18 {
+			return AudioProperties{}
+		}
+		properties.ChannelsN = int(binary.BigEndian.Uint16(common[0:2]))
+		frames := uint64(binary.BigEndian.Uint32(common[2:6]))
+		properties.SampleRateHz = int(math.Round(extendedFloat(common[8:18])))
+		properties.Duration = samplesDuration(frames, properties.SampleRateHz)
+		properties.SampleCount = int64(frames)
+		properties.Container = "aiff"
+
+		properties.Codec = "pcm"
+		if len(common) >= 22 {
+			properties.Codec = aifcCompressionCodecs[string(common[18:22])]
+		}
+		if properties.Codec == "pcm" || properties.Codec == "pcm_float" {
+			properties.Lossless = true
+			properties.BitrateMode = BitrateModeConstant
+			properties.BitsPerSample = int(binary.BigEndian.Uint16(common[6:8]))
+		}
+		return properties
+	}
+	return AudioProperties{}
+}
+
+// extendedFloat decodes an IEEE 754 80-bit extended precision number: sign and 15-bit exponent,
+// then a 64-bit mantissa with an explicit integer bit
+func extendedFloat(data []byte) (value float64) {
+	exponent := int(binary.BigEndian.Uint16(data[0:2]) & 0x7fff)
+	mantissa := binary.BigEndian.Uint64(data[2:10])
+	value = math.Ldexp(float64(mantissa), exponent-16383-63)
+	if data[0]&0x80 != 0 {
+		value = -value
+	}
+	return value
+}
+
+// Bitrates in kbps of MPEG audio by version (MPEG-1 or MPEG-2 and 2.5), layer and the index from the header
+var mpegBitrates = [2][3][15]int{
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// Sample rates of MPEG-1, MPEG-2 and MPEG-2.5 by the index from the header
+var mpegSampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// mpegFrame is a decoded MPEG audio frame header
+type mpegFrame struct {
+	// 0 for MPEG-1, 1 for MPEG-2, 2 for MPEG-2.5
+	version int
+	// 1, 2 or 3
+	layer           int
+	bitrateKbps     int
+	sampleRateHz    int
+	channelsN       int
+	samplesPerFrame int
+	size            int
+}
+
+// parseMpegFrame decodes the 4 byte header of a frame, ok is false if it isn't a valid one
+func parseMpegFrame(header []byte) (frame mpegFrame, ok bool) {
+	if len(header) < 4 || header[0] != 0xff || header[1]&0xe0 != 0xe0 {
+		return mpegFrame{}, false
+	}
+	switch header[1] >> 3 & 0x3 {
+	case 3:
+		frame.version = 0
+	case 2:
+		frame.version = 1
+	case 0:
+		frame.version = 2
+	default:
+		return mpegFrame{}, false
+	}
+	frame.layer = 4 - int(header[1]>>1&0x3)
+	bitrateIndex := int(header[2] >> 4)
+	sampleRateIndex := int(header[2] >> 2 & 0x3)
+	if frame.layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		// Free format streams are not supported
+		return mpegFrame{}, false
+	}
+
+	table := 0
+	if frame.version > 0 {
+		table = 1
+	}
+	frame.bitrateKbps = mpegBitrates[table][frame.layer-1][bitrateIndex]
+	frame.sampleRateHz = mpegSampleRates[frame.version][sampleRateIndex]
+	frame.channelsN = 2
+	if header[3]>>6 == 3 {
+		frame.channelsN = 1
+	}
+	padding := int(header[2] >> 1 & 0x1)
+	switch {
+	case frame.layer == 1:
+		frame.samplesPerFrame = 384
+		frame.size = (12*frame.bitrateKbps*1000/frame.sampleRateHz + padding) * 4
+	case frame.layer == 3 && frame.version > 0:
+		frame.samplesPerFrame = 576
+		frame.size = 72*frame.bitrateKbps*1000/frame.sampleRateHz + padding
+	default:
+		frame.samplesPerFrame = 1152
+		frame.size = 144*frame.bitrateKbps*1000/frame.sampleRateHz + padding
+	}
+	return frame, true
+}
+
+// mpegProperties finds the first frame, confirmed by the one after it, and takes the length from the
+// Xing, Info or VBRI header of variable bitrate files or from the file size for constant bitrate ones
+func mpegProperties(file io.ReadSeeker, offset int64, size int64) (properties AudioProperties) {
+	_, err := file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return AudioProperties{}
+	}
+	buffer := make([]byte, mpegSyncSearchSize)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return AudioProperties{}
+	}
+	buffer = buffer[:n]
+
+	for position := 0; position+4 <= len(buffer); position++ {
+		frame, ok := parseMpegFrame(buffer[position:])
+		if !ok {
+			continue
+		}
+		next := position + frame.size
+		if next+4 <= len(buffer) {
+			if nextFrame, ok := parseMpegFrame(buffer[next:]); !ok || nextFrame.sampleRateHz != frame.sampleRateHz {
+				continue
+			}
+		}
+
+		properties.Codec = fmt.Sprintf("mp%d", frame.layer)
+		properties.Container = "mpeg"
+		properties.SampleRateHz = frame.sampleRateHz
+		properties.ChannelsN = frame.channelsN
+		properties.BitrateKbps = frame.bitrateKbps
+
+		audioBytes := size - offset - int64(position)
+		if hasId3v1(file, size) {
+			audioBytes -= id3v1Size
+		}
+		vbrHeader := mpegVbrHeader(buffer[position:], frame)
+		if vbrHeader.frames > 0 {
+			samples := int64(vbrHeader.frames) * int64(frame.samplesPerFrame)
+			if trimmed := samples - int64(vbrHeader.encoderDelay) - int64(vbrHeader.encoderPadding); trimmed > 0 {
+				samples = trimmed
+			}
+			properties.SampleCount = samples
+			properties.Duration = samplesDuration(uint64(samples), frame.sampleRateHz)
+			if vbrHeader.frameBytes > 0 {
+				audioBytes = int64(vbrHeader.frameBytes)
+			}
+			properties.BitrateKbps = averageBitrateKbps(audioBytes, properties.Duration)
+		} else {
+			properties.Duration = time.Duration(float64(audioBytes) * 8 / float64(frame.bitrateKbps*1000) * float64(time.Second))
+			properties.SampleCount = int64(math.Round(properties.Duration.Seconds() * float64(frame.sampleRateHz)))
+		}
+		properties.Encoder = vbrHeader.encoder
+
+		switch {
+		case vbrHeader.found && vbrHeader.variable:
+			properties.BitrateMode = BitrateModeVariable
+		case vbrHeader.found:
+			properties.BitrateMode = BitrateModeConstant
+		case mpegBitrateVaries(buffer[position:]):
+			properties.BitrateMode = BitrateModeVariable
+		default:
+			properties.BitrateMode = BitrateModeConstant
+		}
+		return properties
+	}
+	return AudioProperties{}
+}
+
+// mpegVbrInfo is what the header in the first frame of a stream tells about it
+type mpegVbrInfo struct {
+	found bool
+	// Set for Xing and VBRI headers and for LAME tags of VBR and ABR modes, Info headers mark CBR files
+	variable   bool
+	frames     uint32
+	frameBytes uint32
+	// Short version string of the LAME tag that follows the Xing or Info header, e.g. LAME3.100
+	encoder string
+	// Samples added by the encoder before and after the audio, from the LAME tag
+	encoderDelay   int
+	encoderPadding int
+}
+
+// Encoders writing the LAME tag, or a tag in its layout, with their name at its start
+var mpegLameTagEncoders = []string{"LAME", "Lavc", "Lavf", "GOGO"}
+
+// mpegVbrHeader reads the count of frames and bytes from the Xing or Info header after the side information
+// of the first frame, with the LAME tag after it, or from the VBRI header at a fixed place
+func mpegVbrHeader(frameData []byte, frame mpegFrame) (info mpegVbrInfo) {
+	sideInfoSize := 32
+	switch {
+	case frame.version == 0 && frame.channelsN == 1:
+		sideInfoSize = 17
+	case frame.version > 0 && frame.channelsN == 2:
+		sideInfoSize = 17
+	case frame.version > 0:
+		sideInfoSize = 9
+	}
+
+	xing := 4 + sideInfoSize
+	if len(frameData) >= xing+8 && (string(frameData[xing:xing+4]) == "Xing" || string(frameData[xing:xing+4]) == "Info") {
+		info.found = true
+		info.variable = string(frameData[xing:xing+4]) == "Xing"
+		// Frames, bytes, table of contents and quality follow when their flags are set
+		flags := binary.BigEndian.Uint32(frameData[xing+4 : xing+8])
+		fields := frameData[xing+8:]
+		if flags&0x1 != 0 && len(fields) >= 4 {
+			info.frames = binary.BigEndian.Uint32(fields[:4])
+			fields = fields[4:]
+		}
+		if flags&0x2 != 0 && len(fields) >= 4 {
+			info.frameBytes = binary.BigEndian.Uint32(fields[:4])
+			fields = fields[4:]
+		}
+		if flags&0x4 != 0 && len(fields) >= 100 {
+			fields = fields[100:]
+		}
+		if flags&0x8 != 0 && len(fields) >= 4 {
+			fields = fields[4:]
+		}
+		mpegLameTag(&info, fields)
+		return info
+	}
+
+	vbri := 4 + 32
+	if len(frameData) >= vbri+18 && string(frameData[vbri:vbri+4]) == "VBRI" {
+		// Version, delay and quality, then bytes and frames
+		info.found = true
+		info.variable = true
+		info.frameBytes = binary.BigEndian.Uint32(frameData[vbri+10 : vbri+14])
+		info.frames = binary.BigEndian.Uint32(frameData[vbri+14 : vbri+18])
+	}
+	return info
+}
+
+// mpegLameTag reads the LAME tag: 9 bytes of the encoder version, revision and VBR method, lowpass,
+// replay gain, encoding flags, bitrate, then 12 bits of encoder delay and 12 of padding
+func mpegLameTag(info *mpegVbrInfo, tag []byte) {
+	if len(tag) < 24 {
+		return
+	}
+	encoder := string(bytes.TrimRight(tag[:9], "\x00 "))
+	known := false
+	for _, prefix := range mpegLameTagEncoders {
+		known = known || strings.HasPrefix(encoder, prefix)
+	}
+	if !known {
+		return
+	}
+	for _, character := range encoder {
+		if character < ' ' || character > '~' {
+			return
+		}
+	}
+	info.encoder = encoder
+
+	// CBR methods, other ones vary the bitrate towards a quality or an average
+	switch tag[9] & 0x0f {
+	case 1, 8:
+		info.variable = false
+	case 2, 3, 4, 5, 6, 9:
+		info.variable = true
+	}
+	info.encoderDelay = int(tag[21])<<4 | int(tag[22])>>4
+	info.encoderPadding = int(tag[22]&0x0f)<<8 | int(tag[23])
+}
+
+// mpegBitrateVaries tells if frames in the beginning of a stream without a VBR header differ in bitrate
+func mpegBitrateVaries(data []byte) bool {
+	previous, ok := parseMpegFrame(data)
+	if !ok {
+		return false
+	}
+	for len(data) >= previous.size+4 {
+		data = data[previous.size:]
+		frame, ok := parseMpegFrame(data)
+		if !ok {
+			return false
+		}
+		if frame.bitrateKbps != previous.bitrateKbps {
+			return true
+		}
+		previous = frame
+	}
+	return false
+}
+
+func hasId3v1(file io.ReadSeeker, size int64) bool {
+	if size < id3v1Size {
+		return false
+	}
+	_, err := file.Seek(size-id3v1Size, io.SeekStart)
+	if err != nil {
+		return false
+	}
+	marker := make([]byte, 3)
+	_, err = io.ReadFull(file, marker)
+	return err == nil && string(marker) == "TAG"
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"music-files/internal/model"
+	"path/filepath"
+	"strings"
+)
+
+// CoverRule assigns a kind to images whose lowercase name without extension matches the glob pattern
+type CoverRule struct {
+	Kind    string
+	Pattern string
+}
+
+// CoverRules decide which images of a directory are covers and what they show
+type CoverRules struct {
+	// Checked in order, the first matching rule wins and its position is the rank of the image, lower is better
+	Rules []CoverRule
+	// Lowercase names of artwork subfolders, like "artwork" or "scans", mapped to the kind of their images
+	// that match no rule
+	Subfolders map[string]string
+	// Square images that match no rule are taken as front covers, ranked after everything else
+	AnySquareImage bool
+}
+
+// ParseCoverRules reads comma-separated "kind:pattern" rules and "name:kind" subfolders
+func ParseCoverRules(rules string, subfolders string, anySquareImage bool) (coverRules CoverRules, err error) {
+	coverRules.Subfolders = make(map[string]string)
+	coverRules.AnySquareImage = anySquareImage
+
+	for _, item := range splitList(rules) {
+		kind, pattern, found := strings.Cut(item, ":")
+		if !found || pattern == "" {
+			return CoverRules{}, fmt.Errorf("cover rule %q is not in the kind:pattern form", item)
+		}
+		if !isCoverKind(kind) {
+			return CoverRules{}, fmt.Errorf("unknown kind %q in cover rule %q", kind, item)
+		}
+		pattern = strings.ToLower(pattern)
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			return CoverRules{}, fmt.Errorf("invalid pattern in cover rule %q: %w", item, err)
+		}
+		coverRules.Rules = append(coverRules.Rules, CoverRule{Kind: kind, Pattern: pattern})
+	}
+
+	for _, item := range splitList(subfolders) {
+		name, kind, found := strings.Cut(item, ":")
+		if !found || name == "" {
+			return CoverRules{}, fmt.Errorf("cover subfolder %q is not in the name:kind form", item)
+		}
+		if !isCoverKind(kind) {
+			return CoverRules{}, fmt.Errorf("unknown kind %q in cover subfolder %q", kind, item)
+		}
+		coverRules.Subfolders[strings.ToLower(name)] = kind
+	}
+
+	return coverRules, nil
+}
+
+// MayBeCover tells whether an image in the directory is worth examining as a cover
+func (r CoverRules) MayBeCover(dirName string, filename string) (mayBe bool) {
+	_, _, ok := r.classifyByName(dirName, filename)
+	return ok || r.AnySquareImage
+}
+
+// Classify returns the kind and rank of an image. ok is false if the image is not a cover
+func (r CoverRules) Classify(dirName string, filename string, widthPx int, heightPx int) (kind string, rank int, ok bool) {
+	kind, rank, ok = r.classifyByName(dirName, filename)
+	if ok {
+		return kind, rank, true
+	}
+	if r.AnySquareImage && widthPx > 0 && widthPx == heightPx {
+		return model.CoverKindFront, len(r.Rules) + 1, true
+	}
+	return "", 0, false
+}
+
+// IsSubfolder tells whether the directory holds artwork of its parent
+func (r CoverRules) IsSubfolder(dirName string) (isSubfolder bool) {
+	_, isSubfolder = r.Subfolders[strings.ToLower(dirName)]
+	return isSubfolder
+}
+
+func (r CoverRules) classifyByName(dirName string, filename string) (kind string, rank int, ok bool) {
+	name := strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	for i, rule := range r.Rules {
+		if matched, _ := filepath.Match(rule.Pattern, name); matched {
+			return rule.Kind, i, true
+		}
+	}
+	if kind, ok = r.Subfolders[strings.ToLower(dirName)]; ok {
+		return kind, len(r.Rules), true
+	}
+	return "", 0, false
+}
+
+func isCoverKind(kind string) bool {
+	switch kind {
+	case model.CoverKindFront, model.CoverKindBack, model.CoverKindDisc, model.CoverKindBooklet:
+		return true
+	default:
+		return false
+	}
+}
+
+func splitList(list string) (items []string) {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,457 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Picture types shared by ID3v2 APIC frames and FLAC PICTURE blocks
+const (
+	PictureTypeOther      = 0
+	PictureTypeFrontCover = 3
+	PictureTypeBackCover  = 4
+	PictureTypeLeaflet    = 5
+	PictureTypeMedia      = 6
+)
+
+// Larger tags and atoms are taken as broken rather than read into memory
+const maxEmbeddedPictureSize = 64 << 20
+
+// EmbeddedPicture is an image stored inside the tags of an audio file
+type EmbeddedPicture struct {
+	// MIME type declared by the tag, may be empty
+	MimeType    string
+	PictureType int
+	Data        []byte
+}
+
+// ReadEmbeddedPictures returns pictures of ID3v2 APIC and PIC frames, FLAC PICTURE blocks and MP4 covr atoms.
+// Formats without such tags have none. The file is positioned back to its start
+func ReadEmbeddedPictures(file io.ReadSeeker) (pictures []EmbeddedPicture, err error) {
+	var offset int64
+	header := make([]byte, 10)
+	for {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.ReadFull(file, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			_, err = file.Seek(0, io.SeekStart)
+			return pictures, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+
+		tagSize := int64(syncsafe(header[6:10]))
+		if tagSize > maxEmbeddedPictureSize {
+			break
+		}
+		tag := make([]byte, tagSize)
+		_, err = io.ReadFull(file, tag)
+		if err != nil {
+			break
+		}
+		pictures = append(pictures, id3v2Pictures(header[3], header[5], tag)...)
+		offset += 10 + tagSize
+		if header[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		pictures = append(pictures, flacPictures(file, offset+4)...)
+	case bytes.Equal(header[4:8], []byte("ftyp")):
+		pictures = append(pictures, mp4Pictures(file)...)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	return pictures, nil
+}
+
+// BestEmbeddedPicture picks the front cover, then a picture of an unknown type, then the first one
+func BestEmbeddedPicture(pictures []EmbeddedPicture) (picture EmbeddedPicture, ok bool) {
+	for _, pictureType := range []int{PictureTypeFrontCover, PictureTypeOther} {
+		for _, picture = range pictures {
+			if picture.PictureType == pictureType {
+				return picture, true
+			}
+		}
+	}
+	if len(pictures) > 0 {
+		return pictures[0], true
+	}
+	return EmbeddedPicture{}, false
+}
+
+func syncsafe(b []byte) (value int) {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// removeUnsynchronisation undoes the ID3v2 scheme that inserts a zero byte after every 0xFF
+func removeUnsynchronisation(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+func id3v2Pictures(version byte, flags byte, tag []byte) (pictures []EmbeddedPicture) {
+	for _, frame := range id3v2Frames(version, flags, tag) {
+		if frame.id != "APIC" && frame.id != "PIC" {
+			continue
+		}
+		picture, ok := id3v2Picture(version, frame.body)
+		if ok {
+			pictures = append(pictures, picture)
+		}
+	}
+	return pictures
+}
+
+// id3v2Frame is a frame of an ID3v2 tag whose format flags are already undone
+type id3v2Frame struct {
+	id   string
+	body []byte
+}
+
+// id3v2Frames splits the content of an ID3v2 tag into frames, compressed and encrypted ones are skipped
+func id3v2Frames(version byte, flags byte, tag []byte) (frames []id3v2Frame) {
+	if version < 4 && flags&0x80 != 0 {
+		tag = removeUnsynchronisation(tag)
+	}
+	if flags&0x40 != 0 && len(tag) >= 4 {
+		// Extended header. Its size excludes the size field itself before v2.4
+		size := int(binary.BigEndian.Uint32(tag[:4])) + 4
+		if version == 4 {
+			size = syncsafe(tag[:4])
+		}
+		if size > len(tag) {
+			return nil
+		}
+		tag = tag[size:]
+	}
+
+	idSize, headerSize := 4, 10
+	if version == 2 {
+		idSize, headerSize = 3, 6
+	}
+	for len(tag) >= headerSize && tag[0] != 0 {
+		id := string(tag[:idSize])
+		var size int
+		switch version {
+		case 2:
+			size = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(tag[4:8]))
+		default:
+			size = syncsafe(tag[4:8])
+		}
+		if size < 0 || headerSize+size > len(tag) {
+			break
+		}
+		body := tag[headerSize : headerSize+size]
+		var frameFlags byte
+		if version >= 3 {
+			frameFlags = tag[9]
+		}
+		tag = tag[headerSize+size:]
+
+		body, ok := id3v2FrameBody(version, flags, frameFlags, body)
+		if ok {
+			frames = append(frames, id3v2Frame{id: id, body: body})
+		}
+	}
+	return frames
+}
+
+// id3v2FrameBody strips what the frame's format flags added to its content. ok is false for compressed
+// and encrypted frames, they are not supported
+func id3v2FrameBody(version byte, tagFlags byte, frameFlags byte, body []byte) (content []byte, ok bool) {
+	switch version {
+	case 3:
+		if frameFlags&0xc0 != 0 {
+			return nil, false
+		}
+		if frameFlags&0x20 != 0 {
+			if len(body) < 1 {
+				return nil, false
+			}
+			body = body[1:]
+		}
+	case 4:
+		if frameFlags&0x0c != 0 {
+			return nil, false
+		}
+		if frameFlags&0x40 != 0 {
+			if len(body) < 1 {
+				return nil, false
+			}
+			body = body[1:]
+		}
+		if frameFlags&0x01 != 0 {
+			if len(body) < 4 {
+				return nil, false
+			}
+			body = body[4:]
+		}
+		if frameFlags&0x02 != 0 || tagFlags&0x80 != 0 {
+			body = removeUnsynchronisation(body)
+		}
+	}
+	return body, true
+}
+
+func id3v2Picture(version byte, body []byte) (picture EmbeddedPicture, ok bool) {
+	if len(body) < 2 {
+		return EmbeddedPicture{}, false
+	}
+	encoding := body[0]
+	body = body[1:]
+
+	if version == 2 {
+		// PIC: three letter image format instead of the MIME type
+		if len(body) < 4 {
+			return EmbeddedPicture{}, false
+		}
+		switch string(bytes.ToUpper(body[:3])) {
+		case "JPG":
+			picture.MimeType = "image/jpeg"
+		case "PNG":
+			picture.MimeType = "image/png"
+		}
+		body = body[3:]
+	} else {
+		end := bytes.IndexByte(body, 0)
+		if end < 0 {
+			return EmbeddedPicture{}, false
+		}
+		picture.MimeType = string(body[:end])
+		body = body[end+1:]
+	}
+
+	if len(body) < 1 {
+		return EmbeddedPicture{}, false
+	}
+	picture.PictureType = int(body[0])
+	body = body[1:]
+
+	// Description, terminated by one zero byte or, for UTF-16 encodings, by two aligned ones
+	if encoding == 1 || encoding == 2 {
+		end := -1
+		for i := 0; i+1 < len(body); i += 2 {
+			if body[i] == 0 && body[i+1] == 0 {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return EmbeddedPicture{}, false
+		}
+		body = body[end+2:]
+	} else {
+		end := bytes.IndexByte(body, 0)
+		if end < 0 {
+			return EmbeddedPicture{}, false
+		}
+		body = body[end+1:]
+	}
+
+	if len(body) == 0 {
+		return EmbeddedPicture{}, false
+	}
+	picture.Data = body
+	return picture, true
+}
+
+func flacPictures(file io.ReadSeeker, offset int64) (pictures []EmbeddedPicture) {
+	blockHeader := make([]byte, 4)
+	for {
+		_, err := file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return pictures
+		}
+		_, err = io.ReadFull(file, blockHeader)
+		if err != nil {
+			return pictures
+		}
+		size := int64(blockHeader[1])<<16 | int64(blockHeader[2])<<8 | int64(blockHeader[3])
+		offset += 4 + size
+
+		if blockHeader[0]&0x7f == 6 {
+			block := make([]byte, size)
+			_, err = io.ReadFull(file, block)
+			if err != nil {
+				return pictures
+			}
+			picture, ok := flacPicture(block)
+			if ok {
+				pictures = append(pictures, picture)
+			}
+		}
+		if blockHeader[0]&0x80 != 0 {
+			return pictures
+		}
+	}
+}
+
+func flacPicture(block []byte) (picture EmbeddedPicture, ok bool) {
+	// Picture type, MIME type, description, width, height, depth, colors, data, all lengths are 32-bit big-endian
+	readUint32 := func() (value int, ok bool) {
+		if len(block) < 4 {
+			return 0, false
+		}
+		value = int(binary.BigEndian.Uint32(block[:4]))
+		block = block[4:]
+		return value, true
+	}
+	readBytes := func() (value []byte, ok bool) {
+		size, ok := readUint32()
+		if !ok || size < 0 || size > len(block) {
+			return nil, false
+		}
+		value = block[:size]
+		block = block[size:]
+		return value, true
+	}
+
+	pictureType, ok := readUint32()
+	if !ok {
+		return EmbeddedPicture{}, false
+	}
+	mimeType, ok := readBytes()
+	if !ok {
+		return EmbeddedPicture{}, false
+	}
+	if _, ok = readBytes(); !ok {
+		return EmbeddedPicture{}, false
+	}
+	for i := 0; i < 4; i++ {
+		if _, ok = readUint32(); !ok {
+			return EmbeddedPicture{}, false
+		}
+	}
+	data, ok := readBytes()
+	if !ok || len(data) == 0 {
+		return EmbeddedPicture{}, false
+	}
+	return EmbeddedPicture{MimeType: string(mimeType), PictureType: pictureType, Data: data}, true
+}
+
+// mp4Pictures returns the images of data atoms of moov/udta/meta/ilst/covr
+func mp4Pictures(file io.ReadSeeker) (pictures []EmbeddedPicture) {
+	start, size, found := findMp4Ilst(file)
+	if found {
+		start, size, found = findMp4Atom(file, start, start+size, "covr")
+	}
+	if !found {
+		return nil
+	}
+
+	limit := start + size
+	for start < limit {
+		dataStart, dataSize, found := findMp4Atom(file, start, limit, "data")
+		if !found || dataSize < 8 || dataSize > maxEmbeddedPictureSize {
+			break
+		}
+		data := make([]byte, dataSize)
+		_, err := file.Seek(dataStart, io.SeekStart)
+		if err != nil {
+			break
+		}
+		_, err = io.ReadFull(file, data)
+		if err != nil {
+			break
+		}
+
+		// Type indicator, then locale, then the image
+		picture := EmbeddedPicture{PictureType: PictureTypeFrontCover, Data: data[8:]}
+		switch binary.BigEndian.Uint32(data[:4]) & 0xffffff {
+		case 13:
+			picture.MimeType = "image/jpeg"
+		case 14:
+			picture.MimeType = "image/png"
+		case 27:
+			picture.MimeType = "image/bmp"
+		}
+		if len(picture.Data) > 0 {
+			pictures = append(pictures, picture)
+		}
+		start = dataStart + dataSize
+	}
+	return pictures
+}
+
+// findMp4Ilst walks moov/udta/meta/ilst, the atom of iTunes metadata items. Returns where its content starts and its size
+func findMp4Ilst(file io.ReadSeeker) (contentStart int64, contentSize int64, found bool) {
+	end, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	start, size, found := findMp4Atom(file, 0, end, "moov")
+	for _, name := range []string{"udta", "meta", "ilst"} {
+		if !found {
+			return 0, 0, false
+		}
+		if name == "ilst" && !isMp4AtomAt(file, start, "hdlr") {
+			// meta is usually a full atom, then its children follow the version and flags
+			start += 4
+			size -= 4
+		}
+		start, size, found = findMp4Atom(file, start, start+size, name)
+	}
+	return start, size, found
+}
+
+// findMp4Atom looks for a child atom between start and end. Returns where its content starts and its size
+func findMp4Atom(file io.ReadSeeker, start int64, end int64, name string) (contentStart int64, contentSize int64, found bool) {
+	header := make([]byte, 16)
+	for start+8 <= end {
+		_, err := file.Seek(start, io.SeekStart)
+		if err != nil {
+			return 0, 0, false
+		}
+		_, err = io.ReadFull(file, header[:8])
+		if err != nil {
+			return 0, 0, false
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			size = end - start
+		case 1:
+			_, err = io.ReadFull(file, header[8:16])
+			if err != nil {
+				return 0, 0, false
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize || start+size > end {
+			return 0, 0, false
+		}
+		if string(header[4:8]) == name {
+			return start + headerSize, size - headerSize, true
+		}
+		start += size
+	}
+	return 0, 0, false
+}
+
+func isMp4AtomAt(file io.ReadSeeker, start int64, name string) bool {
+	header := make([]byte, 8)
+	_, err := file.Seek(start, io.SeekStart)
+	if err != nil {
+		return false
+	}
+	_, err = io.ReadFull(file, header)
+	return err == nil && string(header[4:]) == name
+}
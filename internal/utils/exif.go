@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+const exifOrientationTag = 0x0112
+
+// ExifOrientation returns the orientation from EXIF data of a JPEG file, 1 (as stored) when there is none
+func ExifOrientation(data []byte) (orientation int) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 1
+		}
+		marker := data[i+1]
+		// Markers without a payload
+		if marker == 0xd8 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			i += 2
+			continue
+		}
+		// EXIF goes before the image data
+		if marker == 0xda || marker == 0xd9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation looks for the orientation tag in the first IFD of EXIF data
+func tiffOrientation(tiff []byte) (orientation int) {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	countOfEntries := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < countOfEntries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag {
+			continue
+		}
+		value := int(order.Uint16(tiff[entry+8 : entry+10]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+	return 1
+}
+
+// ApplyOrientation turns the image upright according to an EXIF orientation
+func ApplyOrientation(img image.Image, orientation int) (oriented image.Image) {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Orientations from 5 to 8 swap the sides
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	if orientation >= 5 {
+		result = image.NewRGBA(image.Rect(0, 0, height, width))
+	}
+	resultBounds := result.Bounds()
+	for y := 0; y < resultBounds.Dy(); y++ {
+		for x := 0; x < resultBounds.Dx(); x++ {
+			var sourceX, sourceY int
+			switch orientation {
+			case 2:
+				sourceX, sourceY = width-1-x, y
+			case 3:
+				sourceX, sourceY = width-1-x, height-1-y
+			case 4:
+				sourceX, sourceY = x, height-1-y
+			case 5:
+				sourceX, sourceY = y, x
+			case 6:
+				sourceX, sourceY = y, height-1-x
+			case 7:
+				sourceX, sourceY = width-1-y, height-1-x
+			case 8:
+				sourceX, sourceY = width-1-y, x
+			}
+			result.Set(x, y, img.At(bounds.Min.X+sourceX, bounds.Min.Y+sourceY))
+		}
+	}
+	return result
+}
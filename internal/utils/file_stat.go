@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"time"
+)
+
+// FileStat is the part of file metadata used to notice content changes without reading the file
+type FileStat struct {
+	SizeByte   int64
+	ModifiedAt time.Time
+	// Nil where the platform has no inodes
+	Inode *int64
+}
+
+func StatFile(absolutePath string) (fileStat FileStat, err error) {
+	fileInfo, err := os.Stat(absolutePath)
+	if err != nil {
+		return FileStat{}, err
+	}
+
+	fileStat = FileStat{
+		SizeByte: fileInfo.Size(),
+		// The database keeps timestamps without time zone and with microsecond precision
+		ModifiedAt: fileInfo.ModTime().UTC().Truncate(time.Microsecond),
+		Inode:      fileInode(fileInfo),
+	}
+	return fileStat, nil
+}
+
+// Matches reports whether the file looks the same as when the stored values were taken
+func (s FileStat) Matches(sizeByte int64, modifiedAt *time.Time, inode *int64) bool {
+	if modifiedAt == nil {
+		return false
+	}
+	if s.SizeByte != sizeByte || !s.ModifiedAt.Equal(*modifiedAt) {
+		return false
+	}
+	if s.Inode != nil && inode != nil && *s.Inode != *inode {
+		return false
+	}
+	return true
+}
@@ -5,56 +5,36 @@ import (
 	"encoding/hex"
 	"github.com/h2non/filetype"
 	"github.com/rs/zerolog/log"
+	"io"
 	"os"
+	"path/filepath"
 )
 
 func CalculateSha256(filePath string) (hash string, err error) {
-	data, err := os.ReadFile(filePath)
+	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
 	}
-	hashBytes := sha256.Sum256(data)
-	hash = hex.EncodeToString(hashBytes[:])
-	return hash, nil
-}
-
-func IsMusicFile(absolutePath string) (isMusicFile bool, err error) {
-	fileInfo, err := os.Stat(absolutePath)
-	if err != nil {
-		return false, err
-	}
-	if fileInfo.IsDir() {
-		return false, err
-	}
-
-	file, err := os.Open(absolutePath)
-	if err != nil {
-		return false, err
-	}
 	defer file.Close()
 
-	head := make([]byte, 261)
-	file.Read(head)
+	return CalculateSha256FromReader(file)
+}
 
-	kind, _ := filetype.Match(head)
-	if kind == filetype.Unknown {
-		return false, nil
+// CalculateSha256FromReader hashes the reader in chunks, so the whole file is never held in memory
+func CalculateSha256FromReader(reader io.Reader) (hash string, err error) {
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, reader)
+	if err != nil {
+		return "", err
 	}
+	hash = hex.EncodeToString(hasher.Sum(nil))
+	return hash, nil
+}
 
-	isMusicFile = kind.MIME.Value == "audio/mpeg" ||
-		kind.MIME.Value == "audio/wav" ||
-		kind.MIME.Value == "audio/flac" ||
-		kind.MIME.Value == "audio/x-flac" ||
-		kind.MIME.Value == "audio/aac" ||
-		kind.MIME.Value == "audio/ogg" ||
-		kind.MIME.Value == "audio/x-ms-wma" ||
-		kind.MIME.Value == "audio/vnd.rn-realaudio" ||
-		kind.MIME.Value == "audio/amr" ||
-		kind.MIME.Value == "audio/mp4" ||
-		kind.MIME.Value == "audio/alac" ||
-		kind.MIME.Value == "audio/midi"
-
-	return isMusicFile, nil
+// IsMusicFile tells whether the file is of one of the registered audio formats
+func IsMusicFile(absolutePath string) (isMusicFile bool, err error) {
+	_, isMusicFile, err = DetectAudioFileFormat(absolutePath)
+	return isMusicFile, err
 }
 
 func IsImageFile(absolutePath string) (isImageFile bool, err error) {
@@ -80,6 +60,9 @@ func IsImageFile(absolutePath string) (isImageFile bool, err error) {
 		return false, err
 	}
 
+	if IsAVIF(head) {
+		return true, nil
+	}
 	kind, _ := filetype.Match(head)
 	if kind == filetype.Unknown {
 		return false, nil
@@ -87,7 +70,46 @@ func IsImageFile(absolutePath string) (isImageFile bool, err error) {
 
 	isImageFile = kind.MIME.Value == "image/jpeg" ||
 		kind.MIME.Value == "image/png" ||
-		kind.MIME.Value == "image/gif"
+		kind.MIME.Value == "image/gif" ||
+		kind.MIME.Value == "image/webp" ||
+		kind.MIME.Value == "image/bmp" ||
+		kind.MIME.Value == "image/tiff"
 
 	return isImageFile, nil
 }
+
+// WriteFileAtomically writes the file under a hidden temporary name first and renames it,
+// so a reader never sees a partial file
+func WriteFileAtomically(absolutePath string, data []byte) (err error) {
+	tempPath, err := WriteTempFile(absolutePath, data)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tempPath, absolutePath)
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// WriteTempFile writes the data under a hidden temporary name next to absolutePath, the caller moves it in place
+func WriteTempFile(absolutePath string, data []byte) (tempPath string, err error) {
+	err = os.MkdirAll(filepath.Dir(absolutePath), 0755)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(filepath.Dir(absolutePath), "."+filepath.Base(absolutePath)+".*")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// Magic numbers of filesystems that do not deliver inotify events for changes made by other hosts
+var networkFilesystemMagics = map[int64]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x65735546: true, // FUSE (sshfs, rclone and so on)
+	0x564C:     true, // NCP
+	0x5346414F: true, // AFS
+	0x01021997: true, // 9P
+}
+
+// IsMountPoint reports whether a filesystem is mounted at path, that is path is on another device than its parent
+func IsMountPoint(path string) (isMountPoint bool, err error) {
+	var stat, parentStat syscall.Stat_t
+	if err = syscall.Stat(path, &stat); err != nil {
+		return false, err
+	}
+	if err = syscall.Stat(filepath.Join(path, ".."), &parentStat); err != nil {
+		return false, err
+	}
+	return stat.Dev != parentStat.Dev || stat.Ino == parentStat.Ino, nil
+}
+
+// IsNetworkFilesystem reports whether path is located on a network or userspace filesystem
+func IsNetworkFilesystem(path string) (isNetwork bool, err error) {
+	var stat syscall.Statfs_t
+	if err = syscall.Statfs(path, &stat); err != nil {
+		return false, err
+	}
+	return networkFilesystemMagics[int64(stat.Type)], nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package utils
+
+// IsMountPoint reports whether a filesystem is mounted at path. Detection is only implemented for Linux
+func IsMountPoint(path string) (isMountPoint bool, err error) {
+	return false, nil
+}
+
+// IsNetworkFilesystem reports whether path is located on a network or userspace filesystem.
+// Detection is only implemented for Linux
+func IsNetworkFilesystem(path string) (isNetwork bool, err error) {
+	return false, nil
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFilename is the name of files with ignore patterns for their directory and everything below it
+const IgnoreFilename = ".wakarimiignore"
+
+// IgnorePattern is a single gitignore-style pattern
+type IgnorePattern struct {
+	// Pattern started with "!", a match brings back what earlier patterns ignored
+	negate bool
+	// Pattern ended with "/", it matches directories only
+	dirOnly bool
+	regexp  *regexp.Regexp
+}
+
+// IgnoreRules are patterns declared in one place. They apply to paths below Base
+type IgnoreRules struct {
+	// Slash-separated path of the directory the patterns are relative to, empty for the root
+	Base     string
+	Patterns []IgnorePattern
+}
+
+// ParseIgnorePattern compiles a line of an ignore file. ok is false for blank lines and comments
+func ParseIgnorePattern(line string) (pattern IgnorePattern, ok bool, err error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return IgnorePattern{}, false, nil
+	}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return IgnorePattern{}, false, fmt.Errorf("empty ignore pattern")
+	}
+
+	// A pattern with a slash is relative to its base, otherwise it matches a name at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var expression strings.Builder
+	if anchored {
+		expression.WriteString("^")
+	} else {
+		expression.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			expression.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "/**") && i+3 == len(line):
+			expression.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case line[i] == '*':
+			expression.WriteString("[^/]*")
+		case line[i] == '?':
+			expression.WriteString("[^/]")
+		case line[i] == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				return IgnorePattern{}, false, fmt.Errorf("unclosed bracket in ignore pattern %q", line)
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expression.WriteString("[" + class + "]")
+			i += end + 1
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			expression.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			expression.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+
+	pattern.regexp, err = regexp.Compile(expression.String())
+	if err != nil {
+		return IgnorePattern{}, false, fmt.Errorf("invalid ignore pattern %q: %w", line, err)
+	}
+	return pattern, true, nil
+}
+
+// ParseIgnoreRules compiles lines of an ignore file or patterns configured for a root
+func ParseIgnoreRules(base string, lines []string) (rules IgnoreRules, err error) {
+	rules.Base = base
+	for _, line := range lines {
+		pattern, ok, err := ParseIgnorePattern(line)
+		if err != nil {
+			return IgnoreRules{}, err
+		}
+		if ok {
+			rules.Patterns = append(rules.Patterns, pattern)
+		}
+	}
+	return rules, nil
+}
+
+// ReadIgnoreLines splits an ignore file into lines
+func ReadIgnoreLines(reader io.Reader) (lines []string, err error) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// IsIgnored tells whether the slash-separated path relative to the root is excluded.
+// Rules go from the lowest priority to the highest, and within rules the last matching pattern wins
+func IsIgnored(rules []IgnoreRules, relativePath string, isDir bool) (ignored bool) {
+	for _, rule := range rules {
+		path := relativePath
+		if rule.Base != "" {
+			if !strings.HasPrefix(relativePath, rule.Base+"/") {
+				continue
+			}
+			path = strings.TrimPrefix(relativePath, rule.Base+"/")
+		}
+		for _, pattern := range rule.Patterns {
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if pattern.regexp.MatchString(path) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ErrPictureNotDecodable is returned for formats whose size is known but whose pixels can't be decoded,
+// such pictures are served as they are and are never resized, converted or analyzed
+var ErrPictureNotDecodable = errors.New("pictures of this format can't be decoded, only their size is read")
+
+// Content types of picture formats by extension in lower case
+var imageContentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".avif": "image/avif",
+}
+
+func init() {
+	// There is no pure Go AVIF decoder, only the size is read from the container
+	image.RegisterFormat("avif", "????ftypavif", decodeAVIF, decodeAVIFConfig)
+	image.RegisterFormat("avif", "????ftypavis", decodeAVIF, decodeAVIFConfig)
+}
+
+// ImageContentType returns the MIME type of a picture by its file name, application/octet-stream if it is unknown
+func ImageContentType(filename string) (contentType string) {
+	contentType, ok := imageContentTypes[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+// IsDecodableImage tells whether pixels of the picture can be decoded, so it can be resized, converted and analyzed
+func IsDecodableImage(filename string) (decodable bool) {
+	return strings.ToLower(filepath.Ext(filename)) != ".avif"
+}
+
+// ImageExtension returns the file extension for a format reported by image.DecodeConfig
+func ImageExtension(format string) (extension string) {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// IsAVIF tells whether the head of a file has the signature of AVIF, which filetype doesn't know
+func IsAVIF(head []byte) (isAVIF bool) {
+	return len(head) >= 12 && string(head[4:8]) == "ftyp" && (string(head[8:12]) == "avif" || string(head[8:12]) == "avis")
+}
+
+func decodeAVIF(r io.Reader) (image.Image, error) {
+	return nil, ErrPictureNotDecodable
+}
+
+// decodeAVIFConfig reads the size from the image spatial extents property of the HEIF container:
+// meta, then iprp, then ipco, then the first ispe box
+func decodeAVIFConfig(r io.Reader) (config image.Config, err error) {
+	// Properties are in the meta box, which goes before the image data, so the head of the file is enough
+	head := make([]byte, 64*1024)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return image.Config{}, err
+	}
+	head = head[:n]
+
+	meta, ok := isobmffBox(head, "meta")
+	// meta is a full box, its children follow the version and flags
+	if !ok || len(meta) < 4 {
+		return image.Config{}, errors.New("avif: meta box not found")
+	}
+	properties, ok := isobmffBox(meta[4:], "iprp")
+	if ok {
+		properties, ok = isobmffBox(properties, "ipco")
+	}
+	if ok {
+		properties, ok = isobmffBox(properties, "ispe")
+	}
+	if !ok || len(properties) < 12 {
+		return image.Config{}, errors.New("avif: image spatial extents not found")
+	}
+	return image.Config{
+		ColorModel: color.RGBAModel,
+		Width:      int(binary.BigEndian.Uint32(properties[4:8])),
+		Height:     int(binary.BigEndian.Uint32(properties[8:12])),
+	}, nil
+}
+
+// isobmffBox returns the payload of the first box of the type among boxes in data
+func isobmffBox(data []byte, boxType string) (payload []byte, found bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, false
+			}
+			size, headerSize = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return nil, false
+		}
+		if string(data[4:8]) == boxType {
+			return data[headerSize:size], true
+		}
+		data = data[size:]
+	}
+	return nil, false
+}
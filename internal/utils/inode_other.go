@@ -0,0 +1,9 @@
+//go:build !unix
+
+package utils
+
+import "os"
+
+func fileInode(fileInfo os.FileInfo) (inode *int64) {
+	return nil
+}
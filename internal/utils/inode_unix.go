@@ -0,0 +1,17 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+func fileInode(fileInfo os.FileInfo) (inode *int64) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	value := int64(stat.Ino)
+	return &value
+}
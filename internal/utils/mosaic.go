@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+	"math"
+)
+
+// RenderMosaic arranges pictures into a square grid, 4 pictures make 2x2 and 9 make 3x3, and encodes it
+// in the spec's format. Every picture is turned upright and cropped to fill its cell, the spec gives the whole size
+func RenderMosaic(pictures [][]byte, spec ThumbnailSpec) (mosaic []byte, err error) {
+	side := int(math.Sqrt(float64(len(pictures))))
+	if side < 2 || side*side != len(pictures) {
+		return nil, fmt.Errorf("%d pictures don't make a square grid", len(pictures))
+	}
+	if spec.Width < side || spec.Height < side {
+		return nil, fmt.Errorf("mosaic size %dx%d is too small for a %dx%d grid", spec.Width, spec.Height, side, side)
+	}
+
+	result := newThumbnailCanvas(spec.Width, spec.Height, spec.Format)
+	for i, picture := range pictures {
+		source, err := DecodeUpright(picture)
+		if err != nil {
+			return nil, err
+		}
+		// Cells on the right and at the bottom take what is left after division
+		column, row := i%side, i/side
+		cell := image.Rect(column*spec.Width/side, row*spec.Height/side, (column+1)*spec.Width/side, (row+1)*spec.Height/side)
+		sourceRect, _, _ := thumbnailGeometry(source.Bounds(), ThumbnailSpec{Width: cell.Dx(), Height: cell.Dy(), Mode: ThumbnailModeFill})
+		draw.CatmullRom.Scale(result, cell, source, sourceRect, draw.Over, nil)
+	}
+	return encodeThumbnail(result, spec.Format)
+}
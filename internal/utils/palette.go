@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+	"math"
+)
+
+// Palette is a few colors of a picture for tinting the interface. Colors are written as "#rrggbb"
+type Palette struct {
+	// The most common color
+	Dominant string
+	// The most common saturated color of medium lightness, empty if the picture has none
+	Vibrant string
+	// The most common unsaturated color of medium lightness, empty if the picture has none
+	Muted string
+	// Mean relative luminance from 0 (black) to 1 (white)
+	AverageLuminance float64
+}
+
+const (
+	// Side of the grid the picture is reduced to before counting colors
+	paletteSampleSize = 64
+	// Colors are counted in buckets of 16 shades per channel
+	paletteBucketShift = 4
+	// Saturation that separates vibrant colors from muted ones
+	paletteSaturationThreshold = 0.35
+	// Lightness range of vibrant and muted colors, darker and lighter ones make poor tints
+	paletteMinLightness = 0.25
+	paletteMaxLightness = 0.75
+)
+
+type paletteBucket struct {
+	count                     int
+	sumRed, sumGreen, sumBlue int
+}
+
+func (b paletteBucket) color() (red, green, blue int) {
+	return b.sumRed / b.count, b.sumGreen / b.count, b.sumBlue / b.count
+}
+
+// ExtractPalette returns the palette of an upright picture. Transparent pixels are skipped,
+// a picture without opaque ones is taken as if it were drawn over white
+func ExtractPalette(source image.Image) (palette Palette) {
+	sample := image.NewNRGBA(image.Rect(0, 0, paletteSampleSize, paletteSampleSize))
+	draw.CatmullRom.Scale(sample, sample.Bounds(), source, source.Bounds(), draw.Src, nil)
+
+	buckets, luminance := paletteBuckets(sample, false)
+	if len(buckets) == 0 {
+		buckets, luminance = paletteBuckets(sample, true)
+	}
+
+	var dominant, vibrant, muted *paletteBucket
+	var vibrantScore float64
+	countOfPixels := 0
+	for _, bucket := range buckets {
+		countOfPixels += bucket.count
+		if dominant == nil || bucket.count > dominant.count {
+			dominant = bucket
+		}
+		saturation, lightness := saturationLightness(bucket.color())
+		if lightness < paletteMinLightness || lightness > paletteMaxLightness {
+			continue
+		}
+		if saturation >= paletteSaturationThreshold {
+			if score := float64(bucket.count) * saturation; vibrant == nil || score > vibrantScore {
+				vibrant, vibrantScore = bucket, score
+			}
+		} else if muted == nil || bucket.count > muted.count {
+			muted = bucket
+		}
+	}
+
+	palette.Dominant = hexColor(dominant)
+	palette.Vibrant = hexColor(vibrant)
+	palette.Muted = hexColor(muted)
+	palette.AverageLuminance = luminance / float64(countOfPixels)
+	return palette
+}
+
+// paletteBuckets counts pixels of the sample by color and sums their luminance.
+// With flatten transparent pixels are drawn over white, otherwise the ones less than half opaque are skipped
+func paletteBuckets(sample *image.NRGBA, flatten bool) (buckets map[int]*paletteBucket, luminance float64) {
+	buckets = make(map[int]*paletteBucket)
+	for i := 0; i+3 < len(sample.Pix); i += 4 {
+		red, green, blue, alpha := int(sample.Pix[i]), int(sample.Pix[i+1]), int(sample.Pix[i+2]), int(sample.Pix[i+3])
+		if flatten {
+			red = (red*alpha + 255*(255-alpha)) / 255
+			green = (green*alpha + 255*(255-alpha)) / 255
+			blue = (blue*alpha + 255*(255-alpha)) / 255
+		} else if alpha < 128 {
+			continue
+		}
+
+		key := red>>paletteBucketShift<<16 | green>>paletteBucketShift<<8 | blue>>paletteBucketShift
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &paletteBucket{}
+			buckets[key] = bucket
+		}
+		bucket.count++
+		bucket.sumRed += red
+		bucket.sumGreen += green
+		bucket.sumBlue += blue
+		luminance += relativeLuminance(red, green, blue)
+	}
+	return buckets, luminance
+}
+
+// relativeLuminance follows the WCAG definition for sRGB colors
+func relativeLuminance(red, green, blue int) float64 {
+	linear := func(channel int) float64 {
+		value := float64(channel) / 255
+		if value <= 0.03928 {
+			return value / 12.92
+		}
+		return math.Pow((value+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(red) + 0.7152*linear(green) + 0.0722*linear(blue)
+}
+
+// saturationLightness returns saturation and lightness of the HSL model, both from 0 to 1
+func saturationLightness(red, green, blue int) (saturation float64, lightness float64) {
+	r, g, b := float64(red)/255, float64(green)/255, float64(blue)/255
+	maxChannel, minChannel := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	lightness = (maxChannel + minChannel) / 2
+	if maxChannel == minChannel {
+		return 0, lightness
+	}
+	return (maxChannel - minChannel) / (1 - math.Abs(2*lightness-1)), lightness
+}
+
+func hexColor(bucket *paletteBucket) (hex string) {
+	if bucket == nil {
+		return ""
+	}
+	red, green, blue := bucket.color()
+	return fmt.Sprintf("#%02x%02x%02x", red, green, blue)
+}
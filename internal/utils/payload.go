@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+const id3v1Size = 128
+
+// AudioPayload tells which part of an audio file holds the audio data, so that retagging does not change its hash
+type AudioPayload struct {
+	// Bytes taken by tags at the start of the file
+	Offset int64
+	// Drop the trailing ID3v1 tag if the file ends with one
+	TrimId3v1 bool
+}
+
+// FindAudioPayload skips leading ID3v2 tags and FLAC metadata blocks. ok is false for formats
+// whose tags are not recognized and for truncated or corrupt tags. The file is positioned back to its start
+func FindAudioPayload(file io.ReadSeeker) (payload AudioPayload, ok bool, err error) {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return AudioPayload{}, false, err
+	}
+	header := make([]byte, 10)
+	for {
+		_, err = file.Seek(payload.Offset, io.SeekStart)
+		if err != nil {
+			return AudioPayload{}, false, err
+		}
+		_, err = io.ReadFull(file, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			_, err = file.Seek(0, io.SeekStart)
+			return AudioPayload{}, false, err
+		}
+		if err != nil {
+			return AudioPayload{}, false, err
+		}
+		if !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+
+		// ID3v2 header: "ID3", version, flags, then the tag size as a 28-bit syncsafe integer
+		tagSize := int64(header[6]&0x7f)<<21 | int64(header[7]&0x7f)<<14 | int64(header[8]&0x7f)<<7 | int64(header[9]&0x7f)
+		payload.Offset += 10 + tagSize
+		if header[5]&0x10 != 0 {
+			payload.Offset += 10
+		}
+		payload.TrimId3v1 = true
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		payload.Offset += 4
+		blockHeader := make([]byte, 4)
+		// Every block takes at least its header, so without the last-block flag the loop stops at the end of the file
+		ok = true
+		for ok {
+			_, err = file.Seek(payload.Offset, io.SeekStart)
+			if err != nil {
+				return AudioPayload{}, false, err
+			}
+			_, err = io.ReadFull(file, blockHeader)
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				ok = false
+				break
+			}
+			if err != nil {
+				return AudioPayload{}, false, err
+			}
+			payload.Offset += 4 + (int64(blockHeader[1])<<16 | int64(blockHeader[2])<<8 | int64(blockHeader[3]))
+			if payload.Offset > size {
+				ok = false
+			} else if blockHeader[0]&0x80 != 0 {
+				break
+			}
+		}
+		if !ok {
+			// Truncated or corrupt metadata, the whole file is hashed instead
+			payload = AudioPayload{}
+		}
+	case header[0] == 0xff && header[1]&0xe0 == 0xe0:
+		// MPEG frame sync
+		payload.TrimId3v1 = true
+		ok = true
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return AudioPayload{}, false, err
+	}
+	return payload, ok, nil
+}
+
+// CalculateSha256WithPayload hashes the whole reader and, in the same pass, the audio payload described by payload
+func CalculateSha256WithPayload(reader io.Reader, payload AudioPayload) (hash string, payloadHash string, err error) {
+	hasher := sha256.New()
+	payloadHasher := &payloadWriter{
+		hasher: sha256.New(),
+		skip:   payload.Offset,
+	}
+	if payload.TrimId3v1 {
+		payloadHasher.holdBack = id3v1Size
+	}
+
+	_, err = io.Copy(io.MultiWriter(hasher, payloadHasher), reader)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), payloadHasher.sum(), nil
+}
+
+// payloadWriter hashes what is written to it except the first skip bytes. The last holdBack bytes
+// are kept until the end, where they are dropped if they turn out to be an ID3v1 tag
+type payloadWriter struct {
+	hasher   hash.Hash
+	skip     int64
+	holdBack int
+	tail     []byte
+}
+
+func (w *payloadWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if w.skip > 0 {
+		if int64(len(p)) <= w.skip {
+			w.skip -= int64(len(p))
+			return n, nil
+		}
+		p = p[w.skip:]
+		w.skip = 0
+	}
+	if w.holdBack == 0 {
+		w.hasher.Write(p)
+		return n, nil
+	}
+
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > w.holdBack {
+		flushed := len(w.tail) - w.holdBack
+		w.hasher.Write(w.tail[:flushed])
+		w.tail = append(w.tail[:0], w.tail[flushed:]...)
+	}
+	return n, nil
+}
+
+func (w *payloadWriter) sum() (hash string) {
+	isId3v1 := len(w.tail) == id3v1Size && bytes.HasPrefix(w.tail, []byte("TAG"))
+	if !isId3v1 {
+		w.hasher.Write(w.tail)
+	}
+	return hex.EncodeToString(w.hasher.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func flacBlock(last bool, length int) (block []byte) {
+	flags := byte(0)
+	if last {
+		flags = 0x80
+	}
+	block = []byte{flags, byte(length >> 16), byte(length >> 8), byte(length)}
+	return append(block, make([]byte, length)...)
+}
+
+func TestFindAudioPayload(t *testing.T) {
+	frames := []byte{0xff, 0xf8, 1, 2, 3, 4, 5, 6, 7, 8}
+	flac := append([]byte("fLaC"), flacBlock(false, 34)...)
+	flac = append(flac, flacBlock(true, 10)...)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantOk      bool
+		wantPayload AudioPayload
+	}{
+		{
+			name:        "flac",
+			data:        append(append([]byte(nil), flac...), frames...),
+			wantOk:      true,
+			wantPayload: AudioPayload{Offset: int64(len(flac))},
+		},
+		{
+			name:        "mpeg",
+			data:        frames,
+			wantOk:      true,
+			wantPayload: AudioPayload{TrimId3v1: true},
+		},
+		{
+			name: "flac with truncated block header",
+			data: append(append([]byte("fLaC"), flacBlock(false, 34)...), 0x00, 0x00),
+		},
+		{
+			name: "flac with block longer than the file",
+			data: append([]byte("fLaC"), 0x00, 0xff, 0xff, 0xff, 1, 2, 3),
+		},
+		{
+			name: "flac without last block",
+			data: append([]byte("fLaC"), flacBlock(false, 34)...),
+		},
+		{
+			name: "unknown format",
+			data: []byte("RIFF....WAVEfmt "),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := bytes.NewReader(test.data)
+			payload, ok, err := FindAudioPayload(reader)
+			if err != nil {
+				t.Fatalf("FindAudioPayload() error = %v", err)
+			}
+			if ok != test.wantOk || payload != test.wantPayload {
+				t.Errorf("FindAudioPayload() = %+v, %v, want %+v, %v", payload, ok, test.wantPayload, test.wantOk)
+			}
+			if position, _ := reader.Seek(0, io.SeekCurrent); position != 0 {
+				t.Errorf("position after FindAudioPayload() = %d, want 0", position)
+			}
+		})
+	}
+}
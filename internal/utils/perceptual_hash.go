@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// Width of the grid the picture is reduced to, one more than the height so every row gives 8 comparisons
+const (
+	perceptualHashWidth  = 9
+	perceptualHashHeight = 8
+)
+
+// PerceptualHash returns a difference hash (dHash) of an upright picture: it is reduced to 9x8 gray cells
+// and every bit tells whether a cell is darker than its right neighbour. The same artwork saved at another size
+// or with another compression gets the same hash or one that differs in a few bits, see HammingDistance
+func PerceptualHash(source image.Image) (hash int64) {
+	// Transparent parts count as white, so a picture and its flattened copy match
+	cells := image.NewRGBA(image.Rect(0, 0, perceptualHashWidth, perceptualHashHeight))
+	draw.Draw(cells, cells.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(cells, cells.Bounds(), source, source.Bounds(), draw.Over, nil)
+
+	var hashBits uint64
+	for y := 0; y < perceptualHashHeight; y++ {
+		for x := 0; x < perceptualHashWidth-1; x++ {
+			left := color.GrayModel.Convert(cells.At(x, y)).(color.Gray).Y
+			right := color.GrayModel.Convert(cells.At(x+1, y)).(color.Gray).Y
+			hashBits <<= 1
+			if left < right {
+				hashBits |= 1
+			}
+		}
+	}
+	return int64(hashBits)
+}
+
+// HammingDistance is the number of bits two perceptual hashes differ in, from 0 (same picture) to 64
+func HammingDistance(a int64, b int64) (distance int) {
+	return bits.OnesCount64(uint64(a ^ b))
+}
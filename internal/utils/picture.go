@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+)
+
+// DecodeUpright decodes a picture and turns it upright according to its EXIF orientation
+func DecodeUpright(data []byte) (img image.Image, err error) {
+	img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ApplyOrientation(img, ExifOrientation(data)), nil
+}
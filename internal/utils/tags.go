@@ -0,0 +1,728 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Tags are textual tags of an audio file keyed by Vorbis comment field names in upper case,
+// such as ARTIST, DISCNUMBER or MUSICBRAINZ_ALBUMID. A field may have several values
+type Tags map[string][]string
+
+// First returns the first value of the field, empty if the file has none
+func (t Tags) First(key string) (value string) {
+	if len(t[key]) == 0 {
+		return ""
+	}
+	return t[key][0]
+}
+
+// Number returns the first value of a numeric field such as TRACKNUMBER or DATE, ok is false if it isn't a number.
+// Dates give their year
+func (t Tags) Number(key string) (number int, ok bool) {
+	value := t.First(key)
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	number, err := strconv.Atoi(value[:end])
+	return number, err == nil
+}
+
+func (t Tags) add(key string, values ...string) {
+	if key == "" {
+		return
+	}
+	for _, value := range values {
+		parts := []string{value}
+		if isMusicBrainzIdKey(key) {
+			// ID3v2.3 and MP4 have no multi-valued fields, several ids are joined by slashes
+			parts = strings.Split(value, "/")
+		}
+		for _, part := range parts {
+			part = NormalizeTagValue(key, part)
+			if part != "" && !slices.Contains(t[key], part) {
+				t[key] = append(t[key], part)
+			}
+		}
+	}
+}
+
+// NormalizeTagValue brings a value to the form tags are stored in, so that they can be looked up:
+// MusicBrainz ids in lower case, ISRC in upper case without dashes
+func NormalizeTagValue(key string, value string) (normalized string) {
+	normalized = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	switch {
+	case key == "ISRC":
+		normalized = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(normalized))
+	case isMusicBrainzIdKey(key):
+		normalized = strings.ToLower(normalized)
+	}
+	return normalized
+}
+
+func isMusicBrainzIdKey(key string) bool {
+	return strings.HasPrefix(key, "MUSICBRAINZ_") && strings.HasSuffix(key, "ID")
+}
+
+// ReadTags returns textual tags of ID3v2 frames, including ones in chunks of WAV and AIFF files, FLAC and Ogg
+// Vorbis comments, MP4 ilst atoms and WAV INFO lists, falling back to ID3v1 for files without them. The file is positioned back to its start
+func ReadTags(file io.ReadSeeker) (tags Tags, err error) {
+	tags = make(Tags)
+	var offset int64
+	header := make([]byte, 12)
+	for {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.ReadFull(file, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			_, err = file.Seek(0, io.SeekStart)
+			return tags, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+
+		tagSize := int64(syncsafe(header[6:10]))
+		if tagSize > maxEmbeddedPictureSize {
+			break
+		}
+		tag := make([]byte, tagSize)
+		_, err = io.ReadFull(file, tag)
+		if err != nil {
+			break
+		}
+		id3v2Tags(tags, header[3], header[5], tag)
+		offset += 10 + tagSize
+		if header[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		flacTags(tags, file, offset+4)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		oggTags(tags, file, offset)
+	case bytes.Equal(header[4:8], []byte("ftyp")):
+		mp4Tags(tags, file)
+	case bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		riffTags(tags, file, binary.LittleEndian)
+	case bytes.HasPrefix(header, []byte("FORM")) && (bytes.Equal(header[8:12], []byte("AIFF")) || bytes.Equal(header[8:12], []byte("AIFC"))):
+		riffTags(tags, file, binary.BigEndian)
+	}
+	if len(tags) == 0 {
+		id3v1Tags(tags, file)
+	}
+	splitTotals(tags, "TRACKNUMBER", "TRACKTOTAL")
+	splitTotals(tags, "DISCNUMBER", "DISCTOTAL")
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// splitTotals turns "3/12" into the number and the total, the way Vorbis comments keep them
+func splitTotals(tags Tags, numberKey string, totalKey string) {
+	number, total, found := strings.Cut(tags.First(numberKey), "/")
+	if !found {
+		return
+	}
+	tags[numberKey][0] = strings.TrimSpace(number)
+	if len(tags[totalKey]) == 0 {
+		tags.add(totalKey, total)
+	}
+}
+
+// Text frames of ID3v2.3 and 2.4 and their ID3v2.2 three letter ids
+var id3v2TextFrameKeys = map[string]string{
+	"TIT1": "GROUPING", "TT1": "GROUPING",
+	"TIT2": "TITLE", "TT2": "TITLE",
+	"TIT3": "SUBTITLE", "TT3": "SUBTITLE",
+	"TPE1": "ARTIST", "TP1": "ARTIST",
+	"TPE2": "ALBUMARTIST", "TP2": "ALBUMARTIST",
+	"TPE3": "CONDUCTOR", "TP3": "CONDUCTOR",
+	"TPE4": "REMIXER", "TP4": "REMIXER",
+	"TALB": "ALBUM", "TAL": "ALBUM",
+	"TCOM": "COMPOSER", "TCM": "COMPOSER",
+	"TEXT": "LYRICIST", "TXT": "LYRICIST",
+	"TCON": "GENRE", "TCO": "GENRE",
+	"TRCK": "TRACKNUMBER", "TRK": "TRACKNUMBER",
+	"TPOS": "DISCNUMBER", "TPA": "DISCNUMBER",
+	"TYER": "DATE", "TYE": "DATE", "TDRC": "DATE",
+	"TORY": "ORIGINALDATE", "TOR": "ORIGINALDATE", "TDOR": "ORIGINALDATE",
+	"TSRC": "ISRC", "TRC": "ISRC",
+	"TPUB": "LABEL", "TPB": "LABEL",
+	"TCOP": "COPYRIGHT", "TCR": "COPYRIGHT",
+	"TENC": "ENCODEDBY", "TEN": "ENCODEDBY",
+	"TSSE": "ENCODER", "TSS": "ENCODER",
+	"TMED": "MEDIA", "TMT": "MEDIA",
+	"TLAN": "LANGUAGE", "TLA": "LANGUAGE",
+	"TBPM": "BPM", "TBP": "BPM",
+	"TKEY": "KEY", "TKE": "KEY",
+	"TMOO": "MOOD",
+	"TSST": "DISCSUBTITLE",
+	"TSOA": "ALBUMSORT",
+	"TSOP": "ARTISTSORT",
+	"TSO2": "ALBUMARTISTSORT",
+	"TSOT": "TITLESORT",
+	"TSOC": "COMPOSERSORT",
+	"TCMP": "COMPILATION", "TCP": "COMPILATION",
+}
+
+// Descriptions of user defined ID3v2 frames and MP4 freeform atoms written by MusicBrainz Picard
+// and their Vorbis comment names, in upper case
+var userDefinedTagKeys = map[string]string{
+	"MUSICBRAINZ ALBUM ID":              "MUSICBRAINZ_ALBUMID",
+	"MUSICBRAINZ ARTIST ID":             "MUSICBRAINZ_ARTISTID",
+	"MUSICBRAINZ ALBUM ARTIST ID":       "MUSICBRAINZ_ALBUMARTISTID",
+	"MUSICBRAINZ RELEASE GROUP ID":      "MUSICBRAINZ_RELEASEGROUPID",
+	"MUSICBRAINZ RELEASE TRACK ID":      "MUSICBRAINZ_RELEASETRACKID",
+	"MUSICBRAINZ TRACK ID":              "MUSICBRAINZ_TRACKID",
+	"MUSICBRAINZ WORK ID":               "MUSICBRAINZ_WORKID",
+	"MUSICBRAINZ ALBUM TYPE":            "RELEASETYPE",
+	"MUSICBRAINZ ALBUM STATUS":          "RELEASESTATUS",
+	"MUSICBRAINZ ALBUM RELEASE COUNTRY": "RELEASECOUNTRY",
+	"ALBUM ARTIST":                      "ALBUMARTIST",
+}
+
+// userDefinedTagKey names a user defined field after its description, spaces become underscores
+func userDefinedTagKey(description string) (key string) {
+	description = strings.ToUpper(strings.TrimSpace(description))
+	if key, ok := userDefinedTagKeys[description]; ok {
+		return key
+	}
+	return strings.ReplaceAll(description, " ", "_")
+}
+
+func id3v2Tags(tags Tags, version byte, flags byte, tag []byte) {
+	for _, frame := range id3v2Frames(version, flags, tag) {
+		if len(frame.body) < 1 {
+			continue
+		}
+		encoding, body := frame.body[0], frame.body[1:]
+
+		switch frame.id {
+		case "TXXX", "TXX":
+			values := id3v2Strings(encoding, body)
+			if len(values) >= 2 {
+				tags.add(userDefinedTagKey(values[0]), values[1:]...)
+			}
+		case "COMM", "COM":
+			// Language, then the short description and the text. Described comments are written by
+			// players for their own needs, such as iTunNORM
+			if len(body) < 3 {
+				continue
+			}
+			values := id3v2Strings(encoding, body[3:])
+			if len(values) >= 2 && values[0] == "" {
+				tags.add("COMMENT", values[1:]...)
+			}
+		case "UFID", "UFI":
+			// Owner, then binary identifier
+			owner, identifier, found := bytes.Cut(frame.body, []byte{0})
+			if found && string(owner) == "http://musicbrainz.org" {
+				tags.add("MUSICBRAINZ_TRACKID", string(identifier))
+			}
+		default:
+			key, ok := id3v2TextFrameKeys[frame.id]
+			if !ok {
+				continue
+			}
+			values := id3v2Strings(encoding, body)
+			if key == "GENRE" {
+				for i, value := range values {
+					values[i] = id3v2Genre(value)
+				}
+			}
+			tags.add(key, values...)
+		}
+	}
+}
+
+// id3v2Strings splits a text of ID3v2 frame into zero terminated strings, empty ones between values are kept
+func id3v2Strings(encoding byte, data []byte) (values []string) {
+	var parts [][]byte
+	if encoding == 1 || encoding == 2 {
+		// UTF-16, the terminator is two zero bytes at an even position
+		for {
+			end := -1
+			for i := 0; i+1 < len(data); i += 2 {
+				if data[i] == 0 && data[i+1] == 0 {
+					end = i
+					break
+				}
+			}
+			if end < 0 {
+				parts = append(parts, data)
+				break
+			}
+			parts = append(parts, data[:end])
+			data = data[end+2:]
+		}
+	} else {
+		parts = bytes.Split(data, []byte{0})
+	}
+	for len(parts) > 1 && len(parts[len(parts)-1]) == 0 {
+		parts = parts[:len(parts)-1]
+	}
+
+	values = make([]string, len(parts))
+	for i, part := range parts {
+		values[i] = id3v2String(encoding, part)
+	}
+	return values
+}
+
+// id3v2String decodes a string of ID3v2 encoding: ISO-8859-1, UTF-16 with BOM, UTF-16BE or UTF-8
+func id3v2String(encoding byte, data []byte) (value string) {
+	switch encoding {
+	case 0:
+		return latin1String(data)
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(data) >= 2 && data[0] == 0xfe && data[1] == 0xff {
+			bigEndian, data = true, data[2:]
+		} else if len(data) >= 2 && data[0] == 0xff && data[1] == 0xfe {
+			bigEndian, data = false, data[2:]
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if bigEndian {
+				units[i] = binary.BigEndian.Uint16(data[2*i:])
+			} else {
+				units[i] = binary.LittleEndian.Uint16(data[2*i:])
+			}
+		}
+		return string(utf16.Decode(units))
+	default:
+		return string(data)
+	}
+}
+
+func latin1String(data []byte) (value string) {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// id3v2Genre replaces references to ID3v1 genres, such as "(17)" or "17", with their names
+func id3v2Genre(value string) (genre string) {
+	if strings.HasPrefix(value, "(") {
+		reference, rest, found := strings.Cut(value[1:], ")")
+		if !found {
+			return value
+		}
+		if rest != "" {
+			// "(17)Rock", the refinement goes after the reference
+			return rest
+		}
+		value = reference
+	}
+	switch value {
+	case "RX":
+		return "Remix"
+	case "CR":
+		return "Cover"
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil || index < 0 || index >= len(id3v1Genres) {
+		return value
+	}
+	return id3v1Genres[index]
+}
+
+// Genres of ID3v1 with the Winamp extensions, ID3v2 and MP4 refer to them by index
+var id3v1Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop", "Jazz", "Metal",
+	"New Age", "Oldies", "Other", "Pop", "R&B", "Rap", "Reggae", "Rock", "Techno", "Industrial",
+	"Alternative", "Ska", "Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk",
+	"Fusion", "Trance", "Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"Alternative Rock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic",
+	"Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream", "Southern Rock", "Comedy", "Cult", "Gangsta",
+	"Top 40", "Christian Rap", "Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave", "Psychedelic", "Rave", "Showtunes",
+	"Trailer", "Lo-Fi", "Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+	"Folk", "Folk-Rock", "National Folk", "Swing", "Fast Fusion", "Bebop", "Latin", "Revival", "Celtic", "Bluegrass",
+	"Avantgarde", "Gothic Rock", "Progressive Rock", "Psychedelic Rock", "Symphonic Rock", "Slow Rock", "Big Band", "Chorus", "Easy Listening", "Acoustic",
+	"Humour", "Speech", "Chanson", "Opera", "Chamber Music", "Sonata", "Symphony", "Booty Bass", "Primus", "Porn Groove",
+	"Satire", "Slow Jam", "Club", "Tango", "Samba", "Folklore", "Ballad", "Power Ballad", "Rhythmic Soul", "Freestyle",
+	"Duet", "Punk Rock", "Drum Solo", "A Cappella", "Euro-House", "Dance Hall",
+}
+
+// id3v1Tags reads the 128 byte tag at the end of the file
+func id3v1Tags(tags Tags, file io.ReadSeeker) {
+	_, err := file.Seek(-id3v1Size, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	tag := make([]byte, id3v1Size)
+	_, err = io.ReadFull(file, tag)
+	if err != nil || !bytes.HasPrefix(tag, []byte("TAG")) {
+		return
+	}
+
+	field := func(data []byte) string {
+		end := bytes.IndexByte(data, 0)
+		if end >= 0 {
+			data = data[:end]
+		}
+		return latin1String(data)
+	}
+	tags.add("TITLE", field(tag[3:33]))
+	tags.add("ARTIST", field(tag[33:63]))
+	tags.add("ALBUM", field(tag[63:93]))
+	tags.add("DATE", field(tag[93:97]))
+	comment := tag[97:127]
+	if comment[28] == 0 && comment[29] != 0 {
+		// ID3v1.1 keeps the track number in the last byte of the comment
+		tags.add("TRACKNUMBER", strconv.Itoa(int(comment[29])))
+		comment = comment[:28]
+	}
+	tags.add("COMMENT", field(comment))
+	if int(tag[127]) < len(id3v1Genres) {
+		tags.add("GENRE", id3v1Genres[tag[127]])
+	}
+}
+
+// Alternative names of Vorbis comment fields
+var vorbisCommentAliases = map[string]string{
+	"ALBUM ARTIST": "ALBUMARTIST",
+	"ALBUM_ARTIST": "ALBUMARTIST",
+	"TOTALTRACKS":  "TRACKTOTAL",
+	"TOTALDISCS":   "DISCTOTAL",
+	"DESCRIPTION":  "COMMENT",
+	"YEAR":         "DATE",
+}
+
+// vorbisComments reads a comment header without its packet type: vendor, then "FIELD=value" comments,
+// lengths are 32-bit little-endian. Pictures are left to ReadEmbeddedPictures
+func vorbisComments(tags Tags, data []byte) {
+	readString := func() (value []byte, ok bool) {
+		if len(data) < 4 {
+			return nil, false
+		}
+		size := binary.LittleEndian.Uint32(data[:4])
+		if uint64(size) > uint64(len(data)-4) {
+			return nil, false
+		}
+		value = data[4 : 4+size]
+		data = data[4+size:]
+		return value, true
+	}
+
+	if _, ok := readString(); !ok || len(data) < 4 {
+		return
+	}
+	count := binary.LittleEndian.Uint32(data[:4])
+	data = data[4:]
+	for i := uint32(0); i < count; i++ {
+		comment, ok := readString()
+		if !ok {
+			return
+		}
+		key, value, found := strings.Cut(string(comment), "=")
+		if !found {
+			continue
+		}
+		key = strings.ToUpper(key)
+		if key == "METADATA_BLOCK_PICTURE" || key == "COVERART" {
+			continue
+		}
+		if alias, ok := vorbisCommentAliases[key]; ok {
+			key = alias
+		}
+		tags.add(key, value)
+	}
+}
+
+func flacTags(tags Tags, file io.ReadSeeker, offset int64) {
+	blockHeader := make([]byte, 4)
+	for {
+		_, err := file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return
+		}
+		_, err = io.ReadFull(file, blockHeader)
+		if err != nil {
+			return
+		}
+		size := int64(blockHeader[1])<<16 | int64(blockHeader[2])<<8 | int64(blockHeader[3])
+		offset += 4 + size
+
+		if blockHeader[0]&0x7f == 4 {
+			block := make([]byte, size)
+			_, err = io.ReadFull(file, block)
+			if err != nil {
+				return
+			}
+			vorbisComments(tags, block)
+			return
+		}
+		if blockHeader[0]&0x80 != 0 {
+			return
+		}
+	}
+}
+
+// oggTags reads the comment header, the second packet of the first logical stream, of Vorbis, Opus and FLAC
+func oggTags(tags Tags, file io.ReadSeeker, offset int64) {
+	_, err := file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return
+	}
+
+	var packets [][]byte
+	var packet []byte
+	var serial []byte
+	header := make([]byte, 27)
+	for len(packets) < 2 {
+		_, err = io.ReadFull(file, header)
+		if err != nil || !bytes.HasPrefix(header, []byte("OggS")) {
+			return
+		}
+		segmentTable := make([]byte, header[26])
+		_, err = io.ReadFull(file, segmentTable)
+		if err != nil {
+			return
+		}
+		pageSize := 0
+		for _, segmentSize := range segmentTable {
+			pageSize += int(segmentSize)
+		}
+		page := make([]byte, pageSize)
+		_, err = io.ReadFull(file, page)
+		if err != nil {
+			return
+		}
+
+		if serial == nil {
+			serial = bytes.Clone(header[14:18])
+		}
+		if !bytes.Equal(header[14:18], serial) {
+			continue
+		}
+		// A packet goes on while its segments are 255 bytes long, possibly on the next pages
+		for _, segmentSize := range segmentTable {
+			packet = append(packet, page[:segmentSize]...)
+			page = page[segmentSize:]
+			if segmentSize < 255 {
+				packets = append(packets, packet)
+				packet = nil
+			}
+		}
+		if len(packet) > maxEmbeddedPictureSize {
+			return
+		}
+	}
+
+	comments := packets[1]
+	switch {
+	case bytes.HasPrefix(comments, []byte("\x03vorbis")):
+		vorbisComments(tags, comments[7:])
+	case bytes.HasPrefix(comments, []byte("OpusTags")):
+		vorbisComments(tags, comments[8:])
+	case bytes.HasPrefix(packets[0], []byte("\x7fFLAC")) && len(comments) >= 4:
+		// Metadata block with its header
+		vorbisComments(tags, comments[4:])
+	}
+}
+
+// iTunes metadata items and their Vorbis comment names
+var mp4ItemKeys = map[string]string{
+	"\xa9nam": "TITLE",
+	"\xa9ART": "ARTIST",
+	"aART":    "ALBUMARTIST",
+	"\xa9alb": "ALBUM",
+	"\xa9wrt": "COMPOSER",
+	"\xa9gen": "GENRE",
+	"gnre":    "GENRE",
+	"\xa9day": "DATE",
+	"\xa9cmt": "COMMENT",
+	"\xa9grp": "GROUPING",
+	"\xa9too": "ENCODER",
+	"cprt":    "COPYRIGHT",
+	"cpil":    "COMPILATION",
+	"tmpo":    "BPM",
+	"trkn":    "TRACKNUMBER",
+	"disk":    "DISCNUMBER",
+	"soal":    "ALBUMSORT",
+	"soar":    "ARTISTSORT",
+	"soaa":    "ALBUMARTISTSORT",
+	"sonm":    "TITLESORT",
+	"soco":    "COMPOSERSORT",
+}
+
+// Data atom types of MP4 metadata values
+const (
+	mp4DataImplicit    = 0
+	mp4DataUtf8        = 1
+	mp4DataSignedInt   = 21
+	mp4DataUnsignedInt = 22
+)
+
+// mp4Tags reads items of moov/udta/meta/ilst, pictures are left to ReadEmbeddedPictures
+func mp4Tags(tags Tags, file io.ReadSeeker) {
+	start, size, found := findMp4Ilst(file)
+	if !found || size > maxEmbeddedPictureSize {
+		return
+	}
+	_, err := file.Seek(start, io.SeekStart)
+	if err != nil {
+		return
+	}
+	ilst := make([]byte, size)
+	_, err = io.ReadFull(file, ilst)
+	if err != nil {
+		return
+	}
+
+	for _, item := range mp4Children(ilst) {
+		key, ok := mp4ItemKeys[item.name]
+		var atoms []mp4Atom
+		switch {
+		case ok:
+			atoms = mp4Children(item.content)
+		case item.name == "----":
+			// Freeform item: reverse DNS mean, then name, then data
+			atoms = mp4Children(item.content)
+			for _, atom := range atoms {
+				if atom.name == "name" && len(atom.content) > 4 {
+					key = userDefinedTagKey(string(atom.content[4:]))
+				}
+			}
+		}
+		if key == "" {
+			continue
+		}
+
+		for _, atom := range atoms {
+			if atom.name != "data" || len(atom.content) < 8 {
+				continue
+			}
+			// Type indicator, then locale, then the value
+			dataType := binary.BigEndian.Uint32(atom.content[:4]) & 0xffffff
+			value := atom.content[8:]
+			switch {
+			case item.name == "trkn" || item.name == "disk":
+				// Reserved, number, total
+				if len(value) < 6 {
+					continue
+				}
+				tags.add(key, strconv.Itoa(int(binary.BigEndian.Uint16(value[2:4]))))
+				if total := binary.BigEndian.Uint16(value[4:6]); total > 0 {
+					tags.add(strings.Replace(key, "NUMBER", "TOTAL", 1), strconv.Itoa(int(total)))
+				}
+			case item.name == "gnre":
+				// ID3v1 genre index plus one
+				if len(value) >= 2 {
+					tags.add(key, id3v2Genre(strconv.Itoa(int(binary.BigEndian.Uint16(value))-1)))
+				}
+			case dataType == mp4DataUtf8:
+				tags.add(key, string(value))
+			case dataType == mp4DataSignedInt || dataType == mp4DataUnsignedInt || dataType == mp4DataImplicit:
+				if len(value) > 0 && len(value) <= 8 {
+					var number uint64
+					for _, b := range value {
+						number = number<<8 | uint64(b)
+					}
+					tags.add(key, strconv.FormatUint(number, 10))
+				}
+			}
+		}
+	}
+}
+
+// mp4Atom is an atom read into memory
+type mp4Atom struct {
+	name    string
+	content []byte
+}
+
+// mp4Children splits the content of an atom into its children
+func mp4Children(data []byte) (atoms []mp4Atom) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return atoms
+			}
+			size, headerSize = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return atoms
+		}
+		atoms = append(atoms, mp4Atom{name: string(data[4:8]), content: data[headerSize:size]})
+		data = data[size:]
+	}
+	return atoms
+}
+
+// Fields of the INFO list of WAV files
+var riffInfoKeys = map[string]string{
+	"INAM": "TITLE",
+	"IART": "ARTIST",
+	"IPRD": "ALBUM",
+	"ICMT": "COMMENT",
+	"IGNR": "GENRE",
+	"ICRD": "DATE",
+	"ITRK": "TRACKNUMBER",
+	"IPRT": "TRACKNUMBER",
+	"ICOP": "COPYRIGHT",
+	"ISFT": "ENCODER",
+	"IENG": "ENCODEDBY",
+}
+
+// riffTags reads the ID3v2 tag that WAV and AIFF files keep in an "id3 " chunk and the INFO list of WAV files
+func riffTags(tags Tags, file io.ReadSeeker, byteOrder binary.ByteOrder) {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	for _, chunk := range riffChunks(file, size, byteOrder) {
+		switch chunk.id {
+		case "id3 ", "ID3 ":
+			content, ok := readRiffChunk(file, chunk, maxEmbeddedPictureSize)
+			if !ok || len(content) < 10 || !bytes.HasPrefix(content, []byte("ID3")) {
+				continue
+			}
+			tagSize := syncsafe(content[6:10])
+			if 10+tagSize <= len(content) {
+				id3v2Tags(tags, content[3], content[5], content[10:10+tagSize])
+			}
+		case "LIST":
+			content, ok := readRiffChunk(file, chunk, 1<<20)
+			if !ok || !bytes.HasPrefix(content, []byte("INFO")) {
+				continue
+			}
+			// Subchunks of zero terminated strings with little-endian sizes, aligned to two bytes
+			content = content[4:]
+			for len(content) >= 8 {
+				id := string(content[:4])
+				fieldSize := int(binary.LittleEndian.Uint32(content[4:8]))
+				if fieldSize > len(content)-8 {
+					break
+				}
+				tags.add(riffInfoKeys[id], string(content[8:8+fieldSize]))
+				content = content[min(8+fieldSize+fieldSize%2, len(content)):]
+			}
+		}
+	}
+}
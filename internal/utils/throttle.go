@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ReadThrottle limits the total read speed of all readers sharing it
+type ReadThrottle struct {
+	bytesPerSecond int64
+
+	mutex sync.Mutex
+	// Moment when the bytes granted so far are paid off
+	next time.Time
+}
+
+// NewReadThrottle creates a throttle. Zero or negative bytesPerSecond disables throttling
+func NewReadThrottle(bytesPerSecond int64) (throttle *ReadThrottle) {
+	return &ReadThrottle{
+		bytesPerSecond: bytesPerSecond,
+	}
+}
+
+// Wait blocks until n more bytes may be read or the context is done
+func (t *ReadThrottle) Wait(ctx context.Context, n int) (err error) {
+	if t == nil || t.bytesPerSecond <= 0 || n <= 0 {
+		return ctx.Err()
+	}
+
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(time.Duration(int64(n) * int64(time.Second) / t.bytesPerSecond))
+	t.mutex.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ThrottledReader is an io.Reader that waits for its throttle before every read
+type ThrottledReader struct {
+	Ctx      context.Context
+	Reader   io.Reader
+	Throttle *ReadThrottle
+}
+
+func (r ThrottledReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if waitErr := r.Throttle.Wait(r.Ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The whole image fits into the box, the aspect ratio is kept
+	ThumbnailModeFit = "fit"
+	// The image covers the whole box, what sticks out is cropped evenly from both sides
+	ThumbnailModeFill = "fill"
+)
+
+const (
+	ThumbnailFormatJpeg = "jpeg"
+	ThumbnailFormatPng  = "png"
+	ThumbnailFormatWebp = "webp"
+)
+
+const thumbnailJpegQuality = 85
+
+// ThumbnailSpec describes a variant of a cover. Images are never scaled up
+type ThumbnailSpec struct {
+	// Bounding box in pixels, zero leaves the side unconstrained
+	Width  int
+	Height int
+	Mode   string
+	Format string
+}
+
+// Key identifies the variant among variants of the same picture
+func (s ThumbnailSpec) Key() (key string) {
+	return fmt.Sprintf("%dx%d_%s.%s", s.Width, s.Height, s.Mode, s.Format)
+}
+
+// ContentType is the MIME type of the variant
+func (s ThumbnailSpec) ContentType() (contentType string) {
+	return "image/" + s.Format
+}
+
+// Validate checks the spec and fills in the default mode and format
+func (s ThumbnailSpec) Validate(maxSize int) (spec ThumbnailSpec, err error) {
+	if s.Width < 0 || s.Height < 0 {
+		return ThumbnailSpec{}, fmt.Errorf("thumbnail size %dx%d is negative", s.Width, s.Height)
+	}
+	if maxSize > 0 && (s.Width > maxSize || s.Height > maxSize) {
+		return ThumbnailSpec{}, fmt.Errorf("thumbnail size %dx%d is over the limit of %d px", s.Width, s.Height, maxSize)
+	}
+	switch s.Mode {
+	case "":
+		s.Mode = ThumbnailModeFit
+	case ThumbnailModeFit, ThumbnailModeFill:
+	default:
+		return ThumbnailSpec{}, fmt.Errorf("unknown thumbnail mode %q", s.Mode)
+	}
+	switch s.Format {
+	case "", "jpg":
+		s.Format = ThumbnailFormatJpeg
+	case ThumbnailFormatJpeg, ThumbnailFormatPng, ThumbnailFormatWebp:
+	default:
+		return ThumbnailSpec{}, fmt.Errorf("unknown thumbnail format %q", s.Format)
+	}
+	return s, nil
+}
+
+// ParseThumbnailSpecs parses a comma-separated list of variants, each written as "300", "300x200",
+// optionally followed by ":fit" or ":fill" and by ":jpeg", ":png" or ":webp"
+func ParseThumbnailSpecs(specs string, maxSize int) (parsed []ThumbnailSpec, err error) {
+	for _, item := range strings.Split(specs, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		var spec ThumbnailSpec
+		width, height, isBox := strings.Cut(parts[0], "x")
+		spec.Width, err = strconv.Atoi(width)
+		if err != nil {
+			return nil, fmt.Errorf("invalid thumbnail size %q: %w", parts[0], err)
+		}
+		spec.Height = spec.Width
+		if isBox {
+			spec.Height, err = strconv.Atoi(height)
+			if err != nil {
+				return nil, fmt.Errorf("invalid thumbnail size %q: %w", parts[0], err)
+			}
+		}
+		for _, part := range parts[1:] {
+			if part == ThumbnailModeFit || part == ThumbnailModeFill {
+				spec.Mode = part
+			} else {
+				spec.Format = part
+			}
+		}
+		spec, err = spec.Validate(maxSize)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, spec)
+	}
+	return parsed, nil
+}
+
+// ParseThumbnailQuery reads variant parameters of a request: size is both sides and can't be combined with
+// width and height. isVariant is false if there are none, the original picture is wanted then
+func ParseThumbnailQuery(size string, width string, height string, mode string, format string) (spec ThumbnailSpec, isVariant bool, err error) {
+	spec.Mode, spec.Format = mode, format
+	isVariant = size != "" || width != "" || height != "" || mode != "" || format != ""
+
+	if size != "" && (width != "" || height != "") {
+		return ThumbnailSpec{}, false, fmt.Errorf("size can't be combined with w and h")
+	}
+	if size != "" {
+		spec.Width, err = strconv.Atoi(size)
+		spec.Height = spec.Width
+	}
+	if err == nil && width != "" {
+		spec.Width, err = strconv.Atoi(width)
+	}
+	if err == nil && height != "" {
+		spec.Height, err = strconv.Atoi(height)
+	}
+	if err != nil {
+		return ThumbnailSpec{}, false, err
+	}
+	return spec, isVariant, nil
+}
+
+// RenderThumbnail decodes a picture, turns it upright according to its EXIF orientation,
+// scales it down to the spec and encodes it in the spec's format
+func RenderThumbnail(data []byte, spec ThumbnailSpec) (thumbnail []byte, err error) {
+	source, err := DecodeUpright(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceRect, width, height := thumbnailGeometry(source.Bounds(), spec)
+	result := newThumbnailCanvas(width, height, spec.Format)
+	// Transparent parts of a JPEG become white rather than black
+	draw.CatmullRom.Scale(result, result.Bounds(), source, sourceRect, draw.Over, nil)
+
+	return encodeThumbnail(result, spec.Format)
+}
+
+// newThumbnailCanvas returns an empty image for a variant, white for JPEG as it has no transparency
+func newThumbnailCanvas(width int, height int, format string) (canvas *image.RGBA) {
+	canvas = image.NewRGBA(image.Rect(0, 0, width, height))
+	if format == ThumbnailFormatJpeg {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	return canvas
+}
+
+func encodeThumbnail(img image.Image, format string) (encoded []byte, err error) {
+	var buffer bytes.Buffer
+	switch format {
+	case ThumbnailFormatPng:
+		err = png.Encode(&buffer, img)
+	case ThumbnailFormatWebp:
+		err = EncodeWebP(&buffer, img)
+	default:
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: thumbnailJpegQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// thumbnailGeometry returns the part of the source that is shown and the size of the result
+func thumbnailGeometry(bounds image.Rectangle, spec ThumbnailSpec) (sourceRect image.Rectangle, width int, height int) {
+	sourceWidth, sourceHeight := bounds.Dx(), bounds.Dy()
+
+	if spec.Mode == ThumbnailModeFill && spec.Width > 0 && spec.Height > 0 {
+		// Crop to the aspect ratio of the box, then scale the crop into it
+		cropWidth, cropHeight := sourceWidth, sourceWidth*spec.Height/spec.Width
+		if cropHeight > sourceHeight {
+			cropWidth, cropHeight = sourceHeight*spec.Width/spec.Height, sourceHeight
+		}
+		cropWidth, cropHeight = max(cropWidth, 1), max(cropHeight, 1)
+		left, top := bounds.Min.X+(sourceWidth-cropWidth)/2, bounds.Min.Y+(sourceHeight-cropHeight)/2
+		sourceRect = image.Rect(left, top, left+cropWidth, top+cropHeight)
+		width, height = spec.Width, spec.Height
+		if cropWidth < width {
+			width, height = cropWidth, max(cropWidth*spec.Height/spec.Width, 1)
+		}
+		return sourceRect, width, height
+	}
+
+	scale := 1.0
+	if spec.Width > 0 {
+		scale = min(scale, float64(spec.Width)/float64(sourceWidth))
+	}
+	if spec.Height > 0 {
+		scale = min(scale, float64(spec.Height)/float64(sourceHeight))
+	}
+	width = max(int(float64(sourceWidth)*scale+0.5), 1)
+	height = max(int(float64(sourceHeight)*scale+0.5), 1)
+	return bounds, width, height
+}
@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Order in which lengths of the code length code are stored, see the WebP lossless bitstream specification
+var webpCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+const (
+	// Literal green values followed by 24 backward reference length prefixes, which are never used
+	webpGreenAlphabetSize = 256 + 24
+	webpMaxCodeLength     = 15
+	webpMaxCodeLengthCode = 7
+	webpMaxDimension      = 1 << 14
+)
+
+// EncodeWebP writes the image as a lossless WebP. There are no transforms and no backward references,
+// every pixel is a literal coded with prefix codes built for the image, so the output is larger than what
+// libwebp makes but is decoded by any WebP reader
+func EncodeWebP(w io.Writer, img image.Image) (err error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > webpMaxDimension || height > webpMaxDimension {
+		return fmt.Errorf("image size %dx%d is not supported by WebP", width, height)
+	}
+
+	pixels := make([]color.NRGBA, 0, width*height)
+	greenHistogram := make([]int, webpGreenAlphabetSize)
+	redHistogram, blueHistogram, alphaHistogram := make([]int, 256), make([]int, 256), make([]int, 256)
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, pixel)
+			greenHistogram[pixel.G]++
+			redHistogram[pixel.R]++
+			blueHistogram[pixel.B]++
+			alphaHistogram[pixel.A]++
+			hasAlpha = hasAlpha || pixel.A != 0xff
+		}
+	}
+
+	bits := &webpBitWriter{}
+	bits.write(0x2f, 8)
+	bits.write(uint32(width-1), 14)
+	bits.write(uint32(height-1), 14)
+	if hasAlpha {
+		bits.write(1, 1)
+	} else {
+		bits.write(0, 1)
+	}
+	bits.write(0, 3)
+	// No transforms, no color cache and a single group of prefix codes for the whole image
+	bits.write(0, 1)
+	bits.write(0, 1)
+	bits.write(0, 1)
+
+	greenCodes := bits.writePrefixCode(greenHistogram)
+	redCodes := bits.writePrefixCode(redHistogram)
+	blueCodes := bits.writePrefixCode(blueHistogram)
+	alphaCodes := bits.writePrefixCode(alphaHistogram)
+	// Distance code is never used, a simple code with the single symbol 0
+	bits.write(1, 1)
+	bits.write(0, 1)
+	bits.write(0, 1)
+	bits.write(0, 1)
+
+	for _, pixel := range pixels {
+		bits.writeCode(greenCodes[pixel.G])
+		bits.writeCode(redCodes[pixel.R])
+		bits.writeCode(blueCodes[pixel.B])
+		bits.writeCode(alphaCodes[pixel.A])
+	}
+	data := bits.bytes()
+
+	padding := len(data) % 2
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(data)+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(data)))
+	if _, err = w.Write(header); err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if padding > 0 {
+		_, err = w.Write([]byte{0})
+	}
+	return err
+}
+
+// webpCode is a prefix code with its bits reversed, so it is written least significant bit first
+type webpCode struct {
+	bits   uint32
+	length uint
+}
+
+type webpBitWriter struct {
+	buffer  []byte
+	pending uint64
+	count   uint
+}
+
+func (b *webpBitWriter) write(value uint32, length uint) {
+	b.pending |= uint64(value) << b.count
+	b.count += length
+	for b.count >= 8 {
+		b.buffer = append(b.buffer, byte(b.pending))
+		b.pending >>= 8
+		b.count -= 8
+	}
+}
+
+func (b *webpBitWriter) writeCode(code webpCode) {
+	b.write(code.bits, code.length)
+}
+
+func (b *webpBitWriter) bytes() []byte {
+	if b.count > 0 {
+		b.buffer = append(b.buffer, byte(b.pending))
+		b.pending, b.count = 0, 0
+	}
+	return b.buffer
+}
+
+// writePrefixCode stores a prefix code built for the histogram and returns codes of its symbols
+func (b *webpBitWriter) writePrefixCode(histogram []int) (codes []webpCode) {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		b.write(1, 1)
+		b.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			b.write(0, 1)
+			b.write(uint32(used[0]), 1)
+		} else {
+			b.write(1, 1)
+			b.write(uint32(used[0]), 8)
+		}
+		lengths := make([]int, len(histogram))
+		if len(used) == 2 {
+			b.write(uint32(used[1]), 8)
+			lengths[used[0]], lengths[used[1]] = 1, 1
+		}
+		return canonicalCodes(lengths)
+	}
+
+	lengths := huffmanLengths(histogram, webpMaxCodeLength)
+	lengthHistogram := make([]int, len(webpCodeLengthOrder))
+	for _, length := range lengths {
+		lengthHistogram[length]++
+	}
+	// A code of a single symbol has no bits, keep at least two so every length is written explicitly
+	if usedLengths := countUsed(lengthHistogram); usedLengths < 2 {
+		if lengthHistogram[0] == 0 {
+			lengthHistogram[0] = 1
+		} else {
+			lengthHistogram[1] = 1
+		}
+	}
+	lengthLengths := huffmanLengths(lengthHistogram, webpMaxCodeLengthCode)
+	lengthCodes := canonicalCodes(lengthLengths)
+
+	b.write(0, 1)
+	countOfLengths := len(webpCodeLengthOrder)
+	for countOfLengths > 4 && lengthLengths[webpCodeLengthOrder[countOfLengths-1]] == 0 {
+		countOfLengths--
+	}
+	b.write(uint32(countOfLengths-4), 4)
+	for _, symbol := range webpCodeLengthOrder[:countOfLengths] {
+		b.write(uint32(lengthLengths[symbol]), 3)
+	}
+	// Lengths of the whole alphabet follow
+	b.write(0, 1)
+	for _, length := range lengths {
+		b.writeCode(lengthCodes[length])
+	}
+	return canonicalCodes(lengths)
+}
+
+func countUsed(histogram []int) (used int) {
+	for _, count := range histogram {
+		if count > 0 {
+			used++
+		}
+	}
+	return used
+}
+
+// huffmanLengths returns code lengths of an optimal prefix code for the histogram limited to maxLength bits.
+// When the limit is exceeded the counts are flattened and the code is built again
+func huffmanLengths(histogram []int, maxLength int) (lengths []int) {
+	counts := append([]int(nil), histogram...)
+	for {
+		lengths = unlimitedHuffmanLengths(counts)
+		longest := 0
+		for _, length := range lengths {
+			longest = max(longest, length)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+		for symbol, count := range counts {
+			if count > 0 {
+				counts[symbol] = count/2 + 1
+			}
+		}
+	}
+}
+
+func unlimitedHuffmanLengths(counts []int) (lengths []int) {
+	type node struct {
+		weight  int
+		symbols []int
+	}
+	var nodes []node
+	for symbol, count := range counts {
+		if count > 0 {
+			nodes = append(nodes, node{weight: count, symbols: []int{symbol}})
+		}
+	}
+	lengths = make([]int, len(counts))
+	if len(nodes) == 1 {
+		lengths[nodes[0].symbols[0]] = 1
+		return lengths
+	}
+
+	for len(nodes) > 1 {
+		first, second := 0, 1
+		if nodes[second].weight < nodes[first].weight {
+			first, second = second, first
+		}
+		for i := 2; i < len(nodes); i++ {
+			if nodes[i].weight < nodes[first].weight {
+				first, second = i, first
+			} else if nodes[i].weight < nodes[second].weight {
+				second = i
+			}
+		}
+		// Every symbol below the merged node gets one bit longer
+		merged := node{weight: nodes[first].weight + nodes[second].weight}
+		merged.symbols = append(append(merged.symbols, nodes[first].symbols...), nodes[second].symbols...)
+		for _, symbol := range merged.symbols {
+			lengths[symbol]++
+		}
+		if first > second {
+			first, second = second, first
+		}
+		nodes[first] = merged
+		nodes = append(nodes[:second], nodes[second+1:]...)
+	}
+	return lengths
+}
+
+// canonicalCodes assigns codes to symbols by their lengths: shorter codes first, then by symbol
+func canonicalCodes(lengths []int) (codes []webpCode) {
+	var countOfLength [webpMaxCodeLength + 1]uint32
+	for _, length := range lengths {
+		countOfLength[length]++
+	}
+	countOfLength[0] = 0
+	var nextCode [webpMaxCodeLength + 2]uint32
+	for length := 1; length <= webpMaxCodeLength; length++ {
+		nextCode[length+1] = (nextCode[length] + countOfLength[length]) << 1
+	}
+
+	codes = make([]webpCode, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		code := nextCode[length]
+		nextCode[length]++
+		var reversed uint32
+		for i := 0; i < length; i++ {
+			reversed = reversed<<1 | (code>>i)&1
+		}
+		codes[symbol] = webpCode{bits: reversed, length: uint(length)}
+	}
+	return codes
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"bytes"
+	"golang.org/x/image/webp"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{
+			name: "single pixel",
+			img:  filledImage(1, 1, func(x, y int) color.NRGBA { return color.NRGBA{R: 10, G: 20, B: 30, A: 0xff} }),
+		},
+		{
+			name: "single color",
+			img:  filledImage(31, 17, func(x, y int) color.NRGBA { return color.NRGBA{R: 200, G: 100, B: 50, A: 0xff} }),
+		},
+		{
+			name: "two colors",
+			img: filledImage(16, 16, func(x, y int) color.NRGBA {
+				if (x+y)%2 == 0 {
+					return color.NRGBA{A: 0xff}
+				}
+				return color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}),
+		},
+		{
+			name: "gradient",
+			img: filledImage(256, 64, func(x, y int) color.NRGBA {
+				return color.NRGBA{R: uint8(x), G: uint8(x + y), B: uint8(y * 4), A: 0xff}
+			}),
+		},
+		{
+			name: "translucent",
+			img: filledImage(40, 30, func(x, y int) color.NRGBA {
+				return color.NRGBA{R: uint8(x * 6), G: uint8(y * 8), B: 0x80, A: uint8(x * y)}
+			}),
+		},
+		{
+			name: "noise",
+			img: filledImage(97, 101, func(x, y int) color.NRGBA {
+				return color.NRGBA{R: uint8(random.Intn(256)), G: uint8(random.Intn(256)), B: uint8(random.Intn(256)), A: uint8(random.Intn(256))}
+			}),
+		},
+		{
+			// Counts of green values grow like Fibonacci numbers, so the optimal code is longer than WebP allows
+			name: "skewed histogram",
+			img:  skewedImage(),
+		},
+		{
+			name: "offset bounds",
+			img: &image.RGBA{
+				Pix:    bytes.Repeat([]byte{1, 2, 3, 0xff, 4, 5, 6, 0xff, 7, 8, 9, 0xff}, 4),
+				Stride: 12,
+				Rect:   image.Rect(5, 7, 8, 11),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := EncodeWebP(&buffer, test.img)
+			if err != nil {
+				t.Fatalf("EncodeWebP() error = %v", err)
+			}
+			decoded, err := webp.Decode(&buffer)
+			if err != nil {
+				t.Fatalf("webp.Decode() error = %v", err)
+			}
+
+			bounds := test.img.Bounds()
+			if decoded.Bounds().Dx() != bounds.Dx() || decoded.Bounds().Dy() != bounds.Dy() {
+				t.Fatalf("decoded size = %v, want %v", decoded.Bounds().Size(), bounds.Size())
+			}
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					want := color.NRGBAModel.Convert(test.img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+					got := color.NRGBAModel.Convert(decoded.At(decoded.Bounds().Min.X+x, decoded.Bounds().Min.Y+y)).(color.NRGBA)
+					if got != want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPRejectsUnsupportedSize(t *testing.T) {
+	for _, size := range []image.Point{{0, 10}, {10, 0}, {webpMaxDimension + 1, 1}} {
+		err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rectangle{Max: size}))
+		if err == nil {
+			t.Errorf("EncodeWebP() of %v image error = nil, want error", size)
+		}
+	}
+}
+
+func filledImage(width int, height int, pixel func(x, y int) color.NRGBA) (img *image.NRGBA) {
+	img = image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+func skewedImage() (img *image.NRGBA) {
+	var greens []uint8
+	previous, current := 1, 1
+	for green := 0; green < 20; green++ {
+		for i := 0; i < current; i++ {
+			greens = append(greens, uint8(green))
+		}
+		previous, current = current, previous+current
+	}
+	const width = 128
+	height := (len(greens) + width - 1) / width
+	return filledImage(width, height, func(x, y int) color.NRGBA {
+		green := uint8(0)
+		if i := y*width + x; i < len(greens) {
+			green = greens[i]
+		}
+		return color.NRGBA{G: green, A: 0xff}
+	})
+}